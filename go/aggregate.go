@@ -0,0 +1,205 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"math"
+)
+
+// Aggregation transforms, over the elements of a source list (or the
+// values of a source map). Each is a list, which is replaced by the
+// result:
+//
+//	['`$SUM`', source-path, field?]
+//	['`$MIN`', source-path, field?]
+//	['`$MAX`', source-path, field?]
+//	['`$AVG`', source-path, field?]
+//	['`$COUNT`', source-path, field?]
+//	['`$REDUCE`', source-path, reducer, field?]
+//
+// The source path is resolved as for `$EACH`. If a field path is
+// given, the field of each element is aggregated, rather than the
+// element: ['`$SUM`', 'items', 'price'] is the total price of the
+// items. Missing (nil) values are skipped, and other values that are
+// not numbers are skipped with a warning. `$COUNT` counts the values
+// that are not missing. The reducer of `$REDUCE` is the name of an
+// aggregation: "sum", "min", "max", "avg" or "count".
+//
+// The sum and count of no values are zero, and the others have no
+// result. A missing source gives no result. Results are float64,
+// except for counts, which are int. Use `$ROUND` to round a sum of
+// decimal amounts.
+var Transform_SUM Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _aggregateTransform(state, current, store, "$SUM", "sum")
+}
+
+var Transform_MIN Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _aggregateTransform(state, current, store, "$MIN", "min")
+}
+
+var Transform_MAX Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _aggregateTransform(state, current, store, "$MAX", "max")
+}
+
+var Transform_AVG Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _aggregateTransform(state, current, store, "$AVG", "avg")
+}
+
+var Transform_COUNT Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _aggregateTransform(state, current, store, "$COUNT", "count")
+}
+
+var Transform_REDUCE Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _aggregateTransform(state, current, store, "$REDUCE", S_MT)
+}
+
+// Aggregations of the numeric values of a source. Count is the number
+// of values that are not missing, including values that are not
+// numbers.
+var _reducers = map[string]func(nums []float64, count int) any{
+	"sum": func(nums []float64, count int) any {
+		sum := 0.0
+		for _, n := range nums {
+			sum += n
+		}
+		return sum
+	},
+	"min": func(nums []float64, count int) any {
+		if 0 == len(nums) {
+			return nil
+		}
+		min := math.Inf(1)
+		for _, n := range nums {
+			min = math.Min(min, n)
+		}
+		return min
+	},
+	"max": func(nums []float64, count int) any {
+		if 0 == len(nums) {
+			return nil
+		}
+		max := math.Inf(-1)
+		for _, n := range nums {
+			max = math.Max(max, n)
+		}
+		return max
+	},
+	"avg": func(nums []float64, count int) any {
+		if 0 == len(nums) {
+			return nil
+		}
+		sum := 0.0
+		for _, n := range nums {
+			sum += n
+		}
+		return sum / float64(len(nums))
+	},
+	"count": func(nums []float64, count int) any {
+		return count
+	},
+}
+
+func _aggregateTransform(
+	state *Injection,
+	current any,
+	store any,
+	name string,
+	reducer string,
+) any {
+	nargs := 1
+	if S_MT == reducer {
+		nargs = 2
+	}
+	args, ok := _listTransformArgs(state, name, nargs)
+	if !ok {
+		return nil
+	}
+
+	if S_MT == reducer {
+		reducer, _ = args[1].(string)
+		args = append([]any{args[0]}, args[2:]...)
+	}
+	reduce, ok := _reducers[reducer]
+	if !ok {
+		state.Warn("reduce-args", "Unknown reducer for $REDUCE: "+Stringify(reducer))
+		return _replaceTransform(state, nil)
+	}
+
+	var field any
+	if 1 < len(args) {
+		fpath, ok := args[1].(string)
+		if _, pok := _pathParts(fpath); ok && pok {
+			field = fpath
+		} else {
+			state.Warn("aggregate-args", "Invalid field for "+name+": "+Stringify(args[1]))
+			return _replaceTransform(state, nil)
+		}
+	}
+
+	src := _itemsSource(args[0], store, current, state)
+	if nil == src {
+		return _replaceTransform(state, nil)
+	}
+	if !IsNode(src) {
+		state.Warn("aggregate-source", "Source for "+name+" is not a list or map: "+Typify(src))
+		return _replaceTransform(state, nil)
+	}
+
+	nums := []float64{}
+	count := 0
+	for _, item := range Items(src) {
+		v := item[1]
+		if nil != field {
+			v = GetPath(field, v)
+		}
+		if nil == v {
+			continue
+		}
+		count++
+
+		if n, ok := ToNum(v); ok {
+			nums = append(nums, n)
+		} else if "count" != reducer {
+			state.Warn("aggregate-value", "Value for "+name+" is not a number: "+
+				Typify(v)+" at "+StrKey(item[0]))
+		}
+	}
+
+	return _replaceTransform(state, reduce(nums, count))
+}
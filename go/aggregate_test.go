@@ -0,0 +1,84 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestAggregate(t *testing.T) {
+
+	data := map[string]any{
+		"lines": []any{
+			map[string]any{"sku": "a", "price": 10.1, "qty": 2},
+			map[string]any{"sku": "b", "price": 0.2, "qty": 1},
+			map[string]any{"sku": "c", "price": 5},
+			map[string]any{"sku": "d"},
+		},
+		"nums":  []any{3, 1, 2},
+		"stock": map[string]any{"x": 4, "y": 6},
+		"empty": []any{},
+	}
+
+	t.Run("aggregate-basic", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"total": []any{"`$ROUND`", []any{"`$SUM`", "lines", "price"}, 2},
+			"min":   []any{"`$MIN`", "nums"},
+			"max":   []any{"`$MAX`", "nums"},
+			"avg":   []any{"`$AVG`", "stock"},
+			"lines": []any{"`$COUNT`", "lines"},
+			"qtys":  []any{"`$COUNT`", "lines", "qty"},
+			"units": []any{"`$REDUCE`", "lines", "sum", "qty"},
+		})
+		expected := map[string]any{
+			"total": 15.3,
+			"min":   1.0,
+			"max":   3.0,
+			"avg":   5.0,
+			"lines": 4,
+			"qtys":  2,
+			"units": 3.0,
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("aggregate-empty", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"sum":   []any{"`$SUM`", "empty"},
+			"count": []any{"`$COUNT`", "empty"},
+			"min":   []any{"`$MIN`", "empty"},
+			"avg":   []any{"`$AVG`", "empty"},
+			"none":  []any{"`$SUM`", "missing"},
+		})
+		expected := map[string]any{"sum": 0.0, "count": 0}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("aggregate-warnings", func(t *testing.T) {
+		result := voxgigstruct.TransformCollect(data, map[string]any{
+			"a": []any{"`$SUM`", "lines", "sku"},
+			"b": []any{"`$SUM`", "nums.0"},
+			"c": []any{"`$REDUCE`", "nums", "median"},
+			"d": []any{"`$COUNT`", "lines", "sku"},
+		}, nil)
+
+		expected := map[string]any{"a": 0.0, "d": 4}
+		if !reflect.DeepEqual(expected, result.Out) {
+			t.Errorf("Expected: %v, Got: %v", expected, result.Out)
+		}
+
+		codes := map[string]int{}
+		for _, w := range result.Warnings {
+			codes[w.Code]++
+		}
+		expectedCodes := map[string]int{"aggregate-value": 4, "aggregate-source": 1, "reduce-args": 1}
+		if !reflect.DeepEqual(expectedCodes, codes) {
+			t.Errorf("Expected: %v, Got: %v", expectedCodes, codes)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strconv"
+)
+
+// Stringify a value, as for Stringify, with output of at most
+// maxBytes bytes. If the value does not fit, subtrees are replaced by
+// a count of what was removed, rather than cutting the end of the
+// output, so that the structure stays balanced and the rest of the
+// value is still shown:
+//
+//	{id:a1,items:[1200 items elided]}
+//
+// Lists are shown as `[N items elided]`, maps as `{N keys elided}`,
+// and long strings as `[N chars elided]`. Each step elides the
+// deepest subtree that makes the output fit, or, if there is none,
+// the largest subtree. Only if the elided root still does not fit is
+// the output truncated. The value is not modified.
+func StringifyBudget(val any, maxBytes int) string {
+	out := Stringify(val)
+	if len(out) <= maxBytes || maxBytes <= 0 {
+		return out
+	}
+
+	// Elide subtrees of a copy, wrapped so that the root can be elided.
+	root := map[string]any{S_DTOP: CloneFlags(val, map[string]bool{"cycle": true})}
+	size := len(out)
+
+	for maxBytes < size {
+		e := _budgetElide(root, size-maxBytes)
+		if nil == e {
+			break
+		}
+		SetProp(e.parent, e.key, e.marker)
+		size -= e.saving
+	}
+
+	out = Stringify(root[S_DTOP])
+	if maxBytes < len(out) {
+		out = Stringify(out, maxBytes)
+	}
+	return out
+}
+
+type budgetEntry struct {
+	parent any
+	key    any
+	depth  int
+	saving int
+	marker string
+}
+
+// Choose the subtree to elide, given the number of bytes to remove.
+func _budgetElide(root map[string]any, excess int) *budgetEntry {
+	var fit, largest *budgetEntry
+
+	var visit func(parent any, key any, val any, depth int)
+	visit = func(parent any, key any, val any, depth int) {
+		var marker string
+		switch {
+		case IsMap(val):
+			marker = "{" + strconv.Itoa(NumKeys(val)) + " keys elided}"
+		case IsList(val):
+			marker = "[" + strconv.Itoa(NumKeys(val)) + " items elided]"
+		default:
+			if s, ok := val.(string); ok {
+				marker = "[" + strconv.Itoa(len(s)) + " chars elided]"
+			} else {
+				return
+			}
+		}
+
+		e := &budgetEntry{
+			parent: parent,
+			key:    key,
+			depth:  depth,
+			saving: len(Stringify(val)) - len(marker),
+			marker: marker,
+		}
+
+		if 0 < e.saving {
+			if excess <= e.saving &&
+				(nil == fit || fit.depth < e.depth || (fit.depth == e.depth && e.saving < fit.saving)) {
+				fit = e
+			}
+			if nil == largest || largest.saving < e.saving ||
+				(largest.saving == e.saving && largest.depth < e.depth) {
+				largest = e
+			}
+		}
+
+		if IsNode(val) {
+			for _, kv := range Items(val) {
+				visit(val, kv[0], kv[1], depth+1)
+			}
+		}
+	}
+	visit(root, S_DTOP, root[S_DTOP], 0)
+
+	if nil != fit {
+		return fit
+	}
+	return largest
+}
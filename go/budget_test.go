@@ -0,0 +1,71 @@
+package voxgigstruct_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestStringifyBudget(t *testing.T) {
+
+	items := make([]any, 1200)
+	for i := range items {
+		items[i] = map[string]any{"n": i}
+	}
+
+	t.Run("budget-fit", func(t *testing.T) {
+		val := map[string]any{"a": 1, "b": []any{"x"}}
+		if out := voxgigstruct.StringifyBudget(val, 100); voxgigstruct.Stringify(val) != out {
+			t.Errorf("Expected: %v, Got: %v", voxgigstruct.Stringify(val), out)
+		}
+		if out := voxgigstruct.StringifyBudget(val, 0); voxgigstruct.Stringify(val) != out {
+			t.Errorf("Expected: %v, Got: %v", voxgigstruct.Stringify(val), out)
+		}
+	})
+
+	t.Run("budget-elide", func(t *testing.T) {
+		val := map[string]any{"id": "a1", "items": items}
+		out := voxgigstruct.StringifyBudget(val, 60)
+		expected := "{id:a1,items:[1200 items elided]}"
+		if expected != out {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		// The value is not modified.
+		if 1200 != len(val["items"].([]any)) || nil == voxgigstruct.GetPath("items.0.n", val) {
+			t.Errorf("Value was modified")
+		}
+	})
+
+	t.Run("budget-deepest", func(t *testing.T) {
+		val := map[string]any{
+			"a": map[string]any{"b": map[string]any{"c": []any{1, 2, 3, 4, 5, 6, 7, 8, 9}}},
+			"d": "keep",
+		}
+		full := voxgigstruct.Stringify(val)
+		out := voxgigstruct.StringifyBudget(val, len(full)-1)
+		expected := "{a:{b:{c:[9 items elided]}},d:keep}"
+		if expected != out {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("budget-strings", func(t *testing.T) {
+		val := map[string]any{"msg": strings.Repeat("x", 500), "code": 7}
+		out := voxgigstruct.StringifyBudget(val, 40)
+		expected := "{code:7,msg:[500 chars elided]}"
+		if expected != out {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("budget-limit", func(t *testing.T) {
+		for _, max := range []int{1, 5, 20, 33, 100, 1000} {
+			out := voxgigstruct.StringifyBudget(map[string]any{"items": items, "s": strings.Repeat("y", 300)}, max)
+			if max < len(out) {
+				t.Errorf("Expected at most %d bytes, Got: %d %v", max, len(out), out)
+			}
+		}
+	})
+}
@@ -0,0 +1,103 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// A spec bundle packages a transform spec with the shape of its
+// output and examples, so that a mapping can be distributed and
+// checked as a single node:
+//
+//	{
+//	  name: 'orders', version: '1.2.0',   // Optional metadata.
+//	  spec: { ... },                       // Transform specification.
+//	  shape: { ... },                      // Output shape (optional).
+//	  examples: [
+//	    { name: 'basic', in: { ... }, out: { ... } },  // out is optional.
+//	  ]
+//	}
+//
+// Each example input is transformed with the spec, and the output is
+// validated against the shape, and compared to the expected output,
+// if provided. Errors collected by the transform (such as `$ASSERT`
+// failures) are also reported.
+
+// Results of running a spec bundle.
+type BundleReport struct {
+	Name    string
+	Version string
+	Passed  int
+	Failed  int
+	Results []BundleResult
+}
+
+// Result of running a single bundle example.
+type BundleResult struct {
+	Index int      // Index in the examples list.
+	Name  string   // Example name, if any.
+	Out   any      // Transform output.
+	Errs  []string // Failures, if any.
+}
+
+// The example passed.
+func (r *BundleResult) Pass() bool {
+	return 0 == len(r.Errs)
+}
+
+// Run the examples of a spec bundle. Returns an ErrSpec error if the
+// bundle is not valid.
+func RunBundle(bundle any) (*BundleReport, error) {
+	if !IsMap(bundle) {
+		return nil, NewPathError(ErrSpec, nil, nil, "Invalid bundle: not a map")
+	}
+
+	spec, hasSpec := _getProp(bundle, "spec")
+	if !hasSpec {
+		return nil, NewPathError(ErrSpec, []string{"spec"}, nil, "Invalid bundle: missing spec")
+	}
+
+	shape, hasShape := _getProp(bundle, "shape")
+
+	examples := GetProp(bundle, "examples", []any{})
+	if !IsList(examples) {
+		return nil, NewPathError(ErrSpec, []string{"examples"}, nil,
+			"Invalid bundle: examples must be a list")
+	}
+
+	report := &BundleReport{Results: []BundleResult{}}
+	report.Name, _ = GetProp(bundle, "name").(string)
+	report.Version, _ = GetProp(bundle, "version").(string)
+
+	for eI, example := range _listify(examples) {
+		result := BundleResult{Index: eI, Errs: []string{}}
+		result.Name, _ = GetProp(example, "name").(string)
+
+		errs := ListRefCreate[any]()
+		result.Out = TransformWith(GetProp(example, "in"), spec, &TransformOptions{
+			Extra: map[string]any{S_DERRS: errs},
+		})
+		for _, err := range errs.List {
+			result.Errs = append(result.Errs, Stringify(err))
+		}
+
+		if hasShape {
+			verrs := ListRefCreate[any]()
+			ValidateCollect(Clone(result.Out), shape, nil, verrs)
+			for _, err := range verrs.List {
+				result.Errs = append(result.Errs, Stringify(err))
+			}
+		}
+
+		if expected, hasOut := _getProp(example, "out"); hasOut && !_jsonEqual(expected, result.Out) {
+			result.Errs = append(result.Errs, "Output does not match: expected "+
+				Stringify(expected)+", but found "+Stringify(result.Out)+".")
+		}
+
+		if result.Pass() {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
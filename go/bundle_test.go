@@ -0,0 +1,73 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestBundle(t *testing.T) {
+
+	t.Run("bundle-run", func(t *testing.T) {
+		bundle := map[string]any{
+			"name":    "orders",
+			"version": "1.0.0",
+			"spec": map[string]any{
+				"id":        "`order.id`",
+				"total":     "`order.amount`",
+				"`$ASSERT`": map[string]any{"order.currency": "EUR"},
+			},
+			"shape": map[string]any{"id": "`$STRING`", "total": "`$NUMBER`"},
+			"examples": []any{
+				map[string]any{
+					"name": "basic",
+					"in":   map[string]any{"order": map[string]any{"id": "a1", "amount": 10, "currency": "EUR"}},
+					"out":  map[string]any{"id": "a1", "total": 10},
+				},
+				map[string]any{
+					"name": "bad",
+					"in":   map[string]any{"order": map[string]any{"id": "a2", "amount": "x", "currency": "USD"}},
+					"out":  map[string]any{"id": "a2", "total": 11},
+				},
+			},
+		}
+
+		report, err := voxgigstruct.RunBundle(bundle)
+		if nil != err {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if "orders" != report.Name || "1.0.0" != report.Version ||
+			1 != report.Passed || 1 != report.Failed {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+
+		if !report.Results[0].Pass() ||
+			!reflect.DeepEqual(map[string]any{"id": "a1", "total": 10}, report.Results[0].Out) {
+			t.Errorf("Unexpected result: %+v", report.Results[0])
+		}
+
+		expectedErrs := []string{
+			"Assertion failed at order.currency: expected EUR, but found USD.",
+			"Expected field total to be number, but found string: x.",
+			"Output does not match: expected {id:a2,total:11}, but found {id:a2,total:x}.",
+		}
+		if "bad" != report.Results[1].Name || !reflect.DeepEqual(expectedErrs, report.Results[1].Errs) {
+			t.Errorf("Expected: %q, Got: %q", expectedErrs, report.Results[1].Errs)
+		}
+	})
+
+	t.Run("bundle-invalid", func(t *testing.T) {
+		for _, bundle := range []any{
+			1,
+			map[string]any{"examples": []any{}},
+			map[string]any{"spec": map[string]any{}, "examples": "x"},
+		} {
+			if _, err := voxgigstruct.RunBundle(bundle); !errors.Is(err, voxgigstruct.ErrSpec) {
+				t.Errorf("Expected ErrSpec for %v, Got: %v", bundle, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,143 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/json"
+)
+
+// Size limits for each document produced by Split. A zero value means
+// no limit.
+type SplitOptions struct {
+	MaxItems int // Maximum number of list elements per document.
+	MaxBytes int // Maximum (approximate) JSON size in bytes per document.
+}
+
+// Split a list into consecutive chunks of at most n elements. Each
+// chunk is a new list; the elements are not cloned. If n is less
+// than one, a single chunk with all the elements is returned.
+func Chunk(list any, n int) []any {
+	if !IsList(list) {
+		return []any{}
+	}
+
+	src := _listify(list)
+	if n < 1 {
+		n = len(src) + 1
+	}
+
+	out := []any{}
+	for start := 0; start < len(src); start += n {
+		end := start + n
+		if len(src) < end {
+			end = len(src)
+		}
+		chunk := make([]any, end-start)
+		copy(chunk, src[start:end])
+		out = append(out, chunk)
+	}
+
+	return out
+}
+
+// Split a document containing a large list (at path) into a sequence
+// of smaller documents, each containing a part of the list. All other
+// parts of the document (headers, metadata) are shared by reference
+// with each emitted document, and the nodes along the path are
+// shallow copies. Each document is passed to emit as soon as it is
+// complete; emit can return false to stop splitting. A single list
+// element that exceeds MaxBytes is emitted in a document by itself.
+// Returns the number of documents emitted.
+func Split(doc any, path any, opts SplitOptions, emit func(part any) bool) int {
+	parts, ok := _pathParts(path)
+	if !ok {
+		return 0
+	}
+
+	list := GetPath(parts, doc)
+	if !IsList(list) {
+		return 0
+	}
+	src := _listify(list)
+
+	// Size of the document without any list elements.
+	basesize := 0
+	if 0 < opts.MaxBytes {
+		basesize = _jsonSize(_replacePath(doc, parts, []any{}))
+	}
+
+	count := 0
+	send := func(chunk []any) bool {
+		count++
+		return emit(_replacePath(doc, parts, chunk))
+	}
+
+	chunk := []any{}
+	size := basesize
+	for _, item := range src {
+		itemsize := 0
+		if 0 < opts.MaxBytes {
+			// Allow for the separating comma.
+			itemsize = _jsonSize(item) + 1
+		}
+
+		full := (0 < opts.MaxItems && opts.MaxItems <= len(chunk)) ||
+			(0 < opts.MaxBytes && 0 < len(chunk) && opts.MaxBytes < size+itemsize)
+
+		if full {
+			if !send(chunk) {
+				return count
+			}
+			chunk = []any{}
+			size = basesize
+		}
+
+		chunk = append(chunk, item)
+		size += itemsize
+	}
+
+	// Always emit at least one document, even if the list is empty.
+	if 0 < len(chunk) || 0 == count {
+		send(chunk)
+	}
+
+	return count
+}
+
+// Replace the value at path with val, shallow copying the nodes along
+// the path so that the original document is not modified.
+func _replacePath(node any, parts []string, val any) any {
+	if 0 == len(parts) || (1 == len(parts) && S_MT == parts[0]) {
+		return val
+	}
+
+	var cnode any
+	if IsMap(node) {
+		m := node.(map[string]any)
+		cm := make(map[string]any, len(m))
+		for k, v := range m {
+			cm[k] = v
+		}
+		cnode = cm
+
+	} else if IsList(node) {
+		l := _listify(node)
+		cl := make([]any, len(l))
+		copy(cl, l)
+		cnode = cl
+
+	} else {
+		return node
+	}
+
+	child := _replacePath(GetProp(cnode, parts[0]), parts[1:], val)
+	return SetProp(cnode, parts[0], child)
+}
+
+func _jsonSize(val any) int {
+	b, err := json.Marshal(val)
+	if nil != err {
+		return 0
+	}
+	return len(b)
+}
@@ -0,0 +1,84 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestChunk(t *testing.T) {
+
+	t.Run("chunk-basic", func(t *testing.T) {
+		expected0 := []any{[]any{1, 2}, []any{3, 4}, []any{5}}
+		result0 := voxgigstruct.Chunk([]any{1, 2, 3, 4, 5}, 2)
+		if !reflect.DeepEqual(expected0, result0) {
+			t.Errorf("Expected: %v, Got: %v", expected0, result0)
+		}
+
+		expected1 := []any{[]any{"a", "b"}}
+		result1 := voxgigstruct.Chunk([]string{"a", "b"}, 0)
+		if !reflect.DeepEqual(expected1, result1) {
+			t.Errorf("Expected: %v, Got: %v", expected1, result1)
+		}
+
+		expected2 := []any{}
+		result2 := voxgigstruct.Chunk(map[string]any{"a": 1}, 2)
+		if !reflect.DeepEqual(expected2, result2) {
+			t.Errorf("Expected: %v, Got: %v", expected2, result2)
+		}
+	})
+
+	t.Run("chunk-split-items", func(t *testing.T) {
+		header := map[string]any{"id": "h0"}
+		doc := map[string]any{
+			"header": header,
+			"body":   map[string]any{"items": []any{1, 2, 3}, "n": 3},
+		}
+
+		var parts []any
+		count := voxgigstruct.Split(doc, "body.items",
+			voxgigstruct.SplitOptions{MaxItems: 2},
+			func(part any) bool {
+				parts = append(parts, part)
+				return true
+			})
+
+		expected := []any{
+			map[string]any{"header": header, "body": map[string]any{"items": []any{1, 2}, "n": 3}},
+			map[string]any{"header": header, "body": map[string]any{"items": []any{3}, "n": 3}},
+		}
+		if 2 != count || !reflect.DeepEqual(expected, parts) {
+			t.Errorf("Expected: %v, Got: %v", expected, parts)
+		}
+
+		// Original document is unchanged.
+		if 3 != len(doc["body"].(map[string]any)["items"].([]any)) {
+			t.Errorf("Original document was modified: %v", doc)
+		}
+	})
+
+	t.Run("chunk-split-bytes", func(t *testing.T) {
+		doc := map[string]any{"h": 1, "list": []any{"aaaa", "bbbb", "cccc", "dddddddddddddddd"}}
+
+		var sizes []int
+		voxgigstruct.Split(doc, "list",
+			voxgigstruct.SplitOptions{MaxBytes: 31},
+			func(part any) bool {
+				sizes = append(sizes, len(voxgigstruct.GetPath("list", part).([]any)))
+				return true
+			})
+
+		expected := []int{2, 1, 1}
+		if !reflect.DeepEqual(expected, sizes) {
+			t.Errorf("Expected: %v, Got: %v", expected, sizes)
+		}
+
+		stopped := voxgigstruct.Split(doc, "list",
+			voxgigstruct.SplitOptions{MaxItems: 1},
+			func(part any) bool { return false })
+		if 1 != stopped {
+			t.Errorf("Expected: %v, Got: %v", 1, stopped)
+		}
+	})
+}
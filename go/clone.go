@@ -0,0 +1,127 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"reflect"
+)
+
+// Deep copy Go values that are not JSON-like nodes: structs, pointers,
+// arrays, and typed maps and slices (see CloneFlags). Pointers to the
+// same value are cloned once, so shared and cyclic pointers keep their
+// shape. Unexported struct fields are copied, but not cloned. Providers
+// and loggers are services rather than data, and store internals (see
+// storeRef) are shared state, so none of these are cloned.
+func _cloneReflect(val any, flags map[string]bool) any {
+	if _isShared(val) {
+		return val
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Pointer, reflect.Array, reflect.Map, reflect.Slice:
+		return _cloneValue(rv, flags, map[reflectKey]reflect.Value{}).Interface()
+	}
+	return val
+}
+
+// Store internals that are shared by the copies of a store, such as
+// the `$ERRS` collector and the output usage of a transform.
+type storeRef interface {
+	storeRef()
+}
+
+func (*ListRef[T]) storeRef()    {}
+func (*outputUsage) storeRef()   {}
+func (*providerUsage) storeRef() {}
+func (*usedPaths) storeRef()     {}
+func (*Env) storeRef()           {}
+func (*Quota) storeRef()         {}
+
+func _isShared(val any) bool {
+	switch val.(type) {
+	case Provider, Logger, storeRef:
+		return true
+	}
+	return false
+}
+
+// A pointer, by address and type (a struct and its first field have
+// the same address).
+type reflectKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func _cloneValue(v reflect.Value, flags map[string]bool, seen map[reflectKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		if v.CanInterface() && _isShared(v.Interface()) {
+			return v
+		}
+		key := reflectKey{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := seen[key]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[key] = c
+		c.Elem().Set(_cloneValue(v.Elem(), flags, seen))
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(_cloneValue(v.Elem(), flags, seen))
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for fI := 0; fI < v.NumField(); fI++ {
+			if c.Field(fI).CanSet() {
+				c.Field(fI).Set(_cloneValue(v.Field(fI), flags, seen))
+			}
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(_cloneValue(v.Index(i), flags, seen))
+		}
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(_cloneValue(v.Index(i), flags, seen))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), _cloneValue(iter.Value(), flags, seen))
+		}
+		return c
+
+	case reflect.Func:
+		if !flags["func"] {
+			return reflect.Zero(v.Type())
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,148 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/voxgig/struct"
+)
+
+type CloneInner struct {
+	Tags []string
+}
+
+type cloneOuter struct {
+	CloneInner
+	Name   string
+	Inner  *CloneInner
+	Same   *CloneInner
+	Counts map[string]int
+	Any    any
+	When   time.Time
+	Next   *cloneOuter
+	hidden []int
+}
+
+func TestClone(t *testing.T) {
+
+	deep := map[string]bool{"reflect": true}
+
+	t.Run("clone-reflect", func(t *testing.T) {
+		inner := &CloneInner{Tags: []string{"a"}}
+		when := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		src := &cloneOuter{
+			CloneInner: CloneInner{Tags: []string{"e"}},
+			Name:       "x",
+			Inner:      inner,
+			Same:       inner,
+			Counts:     map[string]int{"a": 1},
+			Any:        map[string]any{"l": []int{1}},
+			When:       when,
+			hidden:     []int{1},
+		}
+		src.Next = src
+
+		out := voxgigstruct.CloneFlags(map[string]any{"v": src}, deep).(map[string]any)["v"].(*cloneOuter)
+
+		if out == src || out.Inner == inner || !reflect.DeepEqual(*inner, *out.Inner) {
+			t.Errorf("Expected deep copy: %v", out)
+		}
+
+		// Shared and cyclic pointers keep their shape.
+		if out.Inner != out.Same || out.Next != out {
+			t.Errorf("Expected shared pointers")
+		}
+
+		out.Tags[0] = "E"
+		out.Inner.Tags[0] = "A"
+		out.Counts["a"] = 2
+		out.Any.(map[string]any)["l"].([]int)[0] = 2
+		if "e" != src.Tags[0] || "a" != inner.Tags[0] || 1 != src.Counts["a"] ||
+			1 != src.Any.(map[string]any)["l"].([]int)[0] {
+			t.Errorf("Expected source unchanged: %v", src)
+		}
+
+		if !when.Equal(out.When) || "x" != out.Name {
+			t.Errorf("Unexpected: %v", out)
+		}
+
+		// Unexported fields are copied, not cloned.
+		if &src.hidden[0] != &out.hidden[0] {
+			t.Errorf("Expected shared unexported field")
+		}
+
+		typed := []map[string]int{{"a": 1}}
+		tout := voxgigstruct.CloneFlags(typed, deep).([]map[string]int)
+		tout[0]["a"] = 2
+		if 1 != typed[0]["a"] {
+			t.Errorf("Expected typed slice copy")
+		}
+
+		arr := [2][]int{{1}, {2}}
+		aout := voxgigstruct.CloneFlags(arr, deep).([2][]int)
+		aout[0][0] = 3
+		if 1 != arr[0][0] {
+			t.Errorf("Expected array copy")
+		}
+	})
+
+	t.Run("clone-reflect-off", func(t *testing.T) {
+		off := map[string]bool{"reflect": false}
+
+		// Deep copy is the default.
+		inner := &CloneInner{Tags: []string{"a"}}
+		if inner == voxgigstruct.Clone(map[string]any{"v": inner}).(map[string]any)["v"] {
+			t.Errorf("Expected pointer copy")
+		}
+
+		// Without reflect, only JSON-like nodes are copied.
+		out := voxgigstruct.CloneFlags(map[string]any{"v": inner}, off)
+		if inner != out.(map[string]any)["v"] {
+			t.Errorf("Expected shared pointer")
+		}
+		list := []string{"a"}
+		if &list[0] != &voxgigstruct.CloneFlags(list, off).([]string)[0] {
+			t.Errorf("Expected shared slice")
+		}
+
+		// Scalars are returned as is.
+		for _, v := range []any{"a", 1, 1.5, true, int64(2), uint8(3)} {
+			if v != voxgigstruct.Clone(v) {
+				t.Errorf("Expected: %v, Got: %v", v, voxgigstruct.Clone(v))
+			}
+		}
+	})
+
+	t.Run("clone-store-internals", func(t *testing.T) {
+		// Store internals are shared by copies of the store.
+		errs := &voxgigstruct.ListRef[any]{}
+		out := voxgigstruct.Clone(map[string]any{"$ERRS": errs})
+		if errs != out.(map[string]any)["$ERRS"] {
+			t.Errorf("Expected shared ListRef")
+		}
+
+		env := voxgigstruct.DefaultEnv()
+		out = voxgigstruct.Clone(map[string]any{"$ENV": env})
+		if env != out.(map[string]any)["$ENV"] {
+			t.Errorf("Expected shared Env")
+		}
+	})
+
+	t.Run("clone-reflect-func", func(t *testing.T) {
+		type withFunc struct {
+			F func() int
+		}
+		src := withFunc{F: func() int { return 1 }}
+
+		out := voxgigstruct.CloneFlags(src, map[string]bool{"reflect": true}).(withFunc)
+		if 1 != out.F() {
+			t.Errorf("Expected function reference")
+		}
+
+		out = voxgigstruct.CloneFlags(src, map[string]bool{"reflect": true, "func": false}).(withFunc)
+		if nil != out.F {
+			t.Errorf("Expected function removed")
+		}
+	})
+}
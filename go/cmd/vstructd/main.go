@@ -0,0 +1,66 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+// The vstructd command serves the struct utilities over HTTP+JSON
+// (see the server package), with optional named specs loaded from a
+// directory:
+//
+//	vstructd -addr :8080 -specs ./specs -reload 30s
+//
+// Each transform is limited (see the -max-depth, -max-nodes,
+// -max-bytes and -quota flags), as requests are untrusted.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	vs "github.com/voxgig/struct"
+	"github.com/voxgig/struct/ffi"
+	"github.com/voxgig/struct/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "Address to listen on.")
+	specs := flag.String("specs", "", "Directory of JSON spec files (name@version.json).")
+	reload := flag.Duration("reload", 0, "Interval to reload the spec files (0: never).")
+	maxBody := flag.Int64("max-body", server.DefaultMaxBody, "Maximum size of a request body.")
+	maxDepth := flag.Int("max-depth", ffi.DefaultLimits.MaxDepth, "Maximum depth of transform data and specs (0: no limit).")
+	maxNodes := flag.Int("max-nodes", ffi.DefaultLimits.MaxNodes, "Maximum number of values of transform data, specs and output (0: no limit).")
+	maxBytes := flag.Int("max-bytes", ffi.DefaultLimits.MaxBytes, "Maximum size of transform data, specs and output (0: no limit).")
+	quota := flag.Int64("quota", 0, "Maximum bytes allocated by each transform (0: no limit).")
+	flag.Parse()
+
+	opts := &server.Options{
+		MaxBody: *maxBody,
+		Limits:  &vs.Limits{MaxDepth: *maxDepth, MaxNodes: *maxNodes, MaxBytes: *maxBytes},
+	}
+	if 0 < *quota {
+		opts.Quota = &vs.Quota{MaxBytes: *quota}
+	}
+
+	if "" != *specs {
+		reg, err := vs.NewRegistry(vs.DirLoader(*specs, nil))
+		if nil != err {
+			log.Fatalf("vstructd: %v", err)
+		}
+		if 0 < *reload {
+			stop := reg.Watch(*reload, func(err error) {
+				log.Printf("vstructd: reload failed: %v", err)
+			})
+			defer stop()
+		}
+		opts.Registry = reg
+		log.Printf("vstructd: specs: %v", reg.Names())
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("vstructd: listening on %s", *addr)
+	log.Fatal(srv.ListenAndServe())
+}
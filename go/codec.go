@@ -0,0 +1,184 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Content types with built in codecs.
+const (
+	CT_JSON = "application/json"
+	CT_CSV  = "text/csv"
+)
+
+// Decode and encode nodes for a content type. Codecs for other
+// formats (YAML, CBOR, msgpack, etc.) can be added with RegisterCodec,
+// so that this package does not depend on third party libraries.
+type Codec interface {
+	Decode(r io.Reader) (any, error)
+	Encode(w io.Writer, node any) error
+}
+
+var (
+	codecMutex sync.RWMutex
+	codecs     = map[string]Codec{
+		CT_JSON: jsonCodec{},
+		CT_CSV:  csvCodec{},
+	}
+)
+
+// Register a codec for a content type (such as `application/yaml`),
+// replacing any existing codec for that type.
+func RegisterCodec(contentType string, codec Codec) {
+	codecMutex.Lock()
+	defer codecMutex.Unlock()
+	codecs[strings.ToLower(contentType)] = codec
+}
+
+// Decode a node from a reader, using the codec for the content
+// type. Parameters (such as charset) are ignored, and structured
+// suffixes (such as `application/ld+json`) use the suffix codec.
+func Decode(r io.Reader, contentType string) (any, error) {
+	codec, err := _codec(contentType)
+	if nil != err {
+		return nil, err
+	}
+	return codec.Decode(r)
+}
+
+// Encode a node to a writer, using the codec for the content type.
+func Encode(w io.Writer, node any, contentType string) error {
+	codec, err := _codec(contentType)
+	if nil != err {
+		return err
+	}
+	return codec.Encode(w, node)
+}
+
+func _codec(contentType string) (Codec, error) {
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	if nil != err {
+		return nil, NewPathError(ErrSpec, nil, err, "Invalid content type: %s", contentType)
+	}
+
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+
+	if codec, ok := codecs[mediatype]; ok {
+		return codec, nil
+	}
+
+	// Structured syntax suffix, for example application/ld+json.
+	if pI := strings.LastIndex(mediatype, "+"); -1 < pI {
+		slash := strings.Index(mediatype, "/")
+		suffix := mediatype[:slash+1] + mediatype[pI+1:]
+		if codec, ok := codecs[suffix]; ok {
+			return codec, nil
+		}
+	}
+
+	return nil, NewPathError(ErrNotFound, nil, nil, "Unsupported content type: %s", contentType)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Decode(r io.Reader) (any, error) {
+	var node any
+	err := json.NewDecoder(r).Decode(&node)
+	if nil != err {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (jsonCodec) Encode(w io.Writer, node any) error {
+	return json.NewEncoder(w).Encode(node)
+}
+
+// CSV is decoded into a list of maps, using the first row as the
+// keys. All values are strings. Encoding expects a list of maps, and
+// the header row is the union of the keys, in order of appearance
+// (sorted within each map). Non-string values are encoded as JSON.
+type csvCodec struct{}
+
+func (csvCodec) Decode(r io.Reader) (any, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if nil != err {
+		return nil, err
+	}
+
+	out := []any{}
+	if 0 == len(rows) {
+		return out, nil
+	}
+
+	header := rows[0]
+	for _, row := range rows[1:] {
+		item := map[string]any{}
+		for cI, key := range header {
+			if cI < len(row) {
+				item[key] = row[cI]
+			}
+		}
+		out = append(out, item)
+	}
+
+	return out, nil
+}
+
+func (csvCodec) Encode(w io.Writer, node any) error {
+	if !IsList(node) {
+		return NewPathError(ErrType, nil, nil, "CSV encoding requires a list, not: %s", Typify(node))
+	}
+	items := _listify(node)
+
+	header := []string{}
+	seen := map[string]bool{}
+	for iI, item := range items {
+		if !IsMap(item) {
+			return NewPathError(ErrType, []string{StrKey(iI)}, nil,
+				"CSV encoding requires a list of maps, not: %s", Typify(item))
+		}
+		for _, key := range KeysOf(item) {
+			if !seen[key] {
+				seen[key] = true
+				header = append(header, key)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); nil != err {
+		return err
+	}
+
+	for _, item := range items {
+		m := item.(map[string]any)
+		row := make([]string, len(header))
+		for cI, key := range header {
+			switch v := m[key].(type) {
+			case nil:
+			case string:
+				row[cI] = v
+			default:
+				b, err := json.Marshal(v)
+				if nil != err {
+					return err
+				}
+				row[cI] = string(b)
+			}
+		}
+		if err := cw.Write(row); nil != err {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,92 @@
+package voxgigstruct_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Decode(r io.Reader) (any, error) {
+	b, err := io.ReadAll(r)
+	return strings.ToUpper(string(b)), err
+}
+
+func (upperCodec) Encode(w io.Writer, node any) error {
+	_, err := io.WriteString(w, strings.ToLower(node.(string)))
+	return err
+}
+
+func TestCodec(t *testing.T) {
+
+	t.Run("codec-json", func(t *testing.T) {
+		node, err := voxgigstruct.Decode(strings.NewReader(`{"a":[1,"b"]}`),
+			"application/json; charset=utf-8")
+		expected := map[string]any{"a": []any{float64(1), "b"}}
+		if nil != err || !reflect.DeepEqual(expected, node) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, node, err)
+		}
+
+		node, err = voxgigstruct.Decode(strings.NewReader(`{"x":1}`), "application/ld+json")
+		if nil != err || !reflect.DeepEqual(map[string]any{"x": float64(1)}, node) {
+			t.Errorf("Unexpected: %v %v", node, err)
+		}
+
+		var buf bytes.Buffer
+		err = voxgigstruct.Encode(&buf, expected, voxgigstruct.CT_JSON)
+		if nil != err || "{\"a\":[1,\"b\"]}\n" != buf.String() {
+			t.Errorf("Unexpected: %q %v", buf.String(), err)
+		}
+	})
+
+	t.Run("codec-csv", func(t *testing.T) {
+		node, err := voxgigstruct.Decode(strings.NewReader("a,b\n1,x\n2,y\n"), "text/csv")
+		expected := []any{
+			map[string]any{"a": "1", "b": "x"},
+			map[string]any{"a": "2", "b": "y"},
+		}
+		if nil != err || !reflect.DeepEqual(expected, node) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, node, err)
+		}
+
+		var buf bytes.Buffer
+		err = voxgigstruct.Encode(&buf, []any{
+			map[string]any{"a": "1", "b": 2},
+			map[string]any{"a": "3", "c": []any{4}},
+		}, "text/csv")
+		if nil != err || "a,b,c\n1,2,\n3,,[4]\n" != buf.String() {
+			t.Errorf("Unexpected: %q %v", buf.String(), err)
+		}
+
+		if err := voxgigstruct.Encode(&buf, map[string]any{}, "text/csv"); nil == err {
+			t.Errorf("Expected error for non-list")
+		}
+	})
+
+	t.Run("codec-register", func(t *testing.T) {
+		if _, err := voxgigstruct.Decode(strings.NewReader("a"), "application/x-upper"); nil == err {
+			t.Errorf("Expected error for unsupported type")
+		}
+		if _, err := voxgigstruct.Decode(strings.NewReader("a"), "not a type;"); nil == err {
+			t.Errorf("Expected error for invalid type")
+		}
+
+		voxgigstruct.RegisterCodec("application/x-upper", upperCodec{})
+
+		node, err := voxgigstruct.Decode(strings.NewReader("abc"), "application/x-upper")
+		if nil != err || "ABC" != node {
+			t.Errorf("Unexpected: %v %v", node, err)
+		}
+
+		var buf bytes.Buffer
+		err = voxgigstruct.Encode(&buf, "ABC", "application/x-upper")
+		if nil != err || "abc" != buf.String() {
+			t.Errorf("Unexpected: %v %v", buf.String(), err)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+)
+
+// Compares strings in the order of a collation, returning -1, 0 or 1.
+// A *collate.Collator (from golang.org/x/text/collate) can be used
+// directly, so that this package does not depend on x/text:
+//
+//	keys := SortedKeys(node, collate.New(language.French))
+type Collator interface {
+	CompareString(a string, b string) int
+}
+
+// Keys of a node, as for KeysOf, with map keys sorted by a collator,
+// for human facing output. Keys that the collator considers equal are
+// sorted in byte order, so the order is deterministic. A nil collator
+// gives byte order, as for KeysOf. List keys are in index order.
+func SortedKeys(val any, coll Collator) []string {
+	keys := KeysOf(val)
+	if nil == coll || !IsMap(val) {
+		return keys
+	}
+
+	// KeysOf is in byte order, so a stable sort breaks ties.
+	sort.SliceStable(keys, func(i, j int) bool {
+		return coll.CompareString(keys[i], keys[j]) < 0
+	})
+
+	return keys
+}
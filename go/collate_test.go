@@ -0,0 +1,39 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+// Compares ignoring accents and case, a little like a primary
+// strength collation.
+type testCollator struct{}
+
+func (testCollator) CompareString(a string, b string) int {
+	fold := strings.NewReplacer("é", "e", "É", "e", "ö", "o", "Ö", "o")
+	return strings.Compare(strings.ToLower(fold.Replace(a)), strings.ToLower(fold.Replace(b)))
+}
+
+func TestCollate(t *testing.T) {
+
+	t.Run("collate-sortedkeys", func(t *testing.T) {
+		val := map[string]any{"fred": 1, "émile": 2, "eliza": 3, "Zoë": 4, "Öskar": 5, "oskar": 6}
+
+		bytewise := []string{"Zoë", "eliza", "fred", "oskar", "Öskar", "émile"}
+		if keys := voxgigstruct.SortedKeys(val, nil); !reflect.DeepEqual(bytewise, keys) {
+			t.Errorf("Unexpected: %v", keys)
+		}
+
+		collated := []string{"eliza", "émile", "fred", "oskar", "Öskar", "Zoë"}
+		if keys := voxgigstruct.SortedKeys(val, testCollator{}); !reflect.DeepEqual(collated, keys) {
+			t.Errorf("Unexpected: %v", keys)
+		}
+
+		if keys := voxgigstruct.SortedKeys([]any{"b", "a"}, testCollator{}); !reflect.DeepEqual([]string{"0", "1"}, keys) {
+			t.Errorf("Unexpected: %v", keys)
+		}
+	})
+}
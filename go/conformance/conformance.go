@@ -0,0 +1,175 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+// Package conformance runs the shared test corpus of the struct
+// utilities (the same cases used by every language implementation)
+// against a Subject, so that forks and wrappers of voxgigstruct can
+// check that they have not changed its behaviour.
+//
+//	func TestConformance(t *testing.T) {
+//		subject := conformance.DefaultSubject()
+//		subject.Merge = mywrapper.Merge
+//		conformance.RunConformance(t, subject)
+//	}
+package conformance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+	"github.com/voxgig/struct/testutil"
+)
+
+// The test corpus, copied from build/test/test.json.
+//
+//go:generate cp ../../build/test/test.json corpus.json
+//go:embed corpus.json
+var corpus []byte
+
+// The operations under test, with the signatures of the voxgigstruct
+// functions they correspond to. Operations that are nil are skipped.
+type Subject struct {
+	GetPath   func(path any, store any, current any) any                            // As GetPathState, with no state.
+	Merge     func(val any) any                                                     // As Merge.
+	Inject    func(val any, store any, modify voxgigstruct.Modify, current any) any // As InjectDescend, with no state.
+	Transform func(data any, spec any, modify voxgigstruct.Modify) any              // As TransformModify, with no extra.
+	Validate  func(data any, spec any) (any, error)                                 // As Validate.
+	Walk      func(val any, apply voxgigstruct.WalkApply) any                       // As Walk.
+}
+
+// The voxgigstruct implementation of each operation.
+func DefaultSubject() Subject {
+	return Subject{
+		GetPath: func(path any, store any, current any) any {
+			return voxgigstruct.GetPathState(path, store, current, nil)
+		},
+		Merge: voxgigstruct.Merge,
+		Inject: func(val any, store any, modify voxgigstruct.Modify, current any) any {
+			return voxgigstruct.InjectDescend(val, store, modify, current, nil)
+		},
+		Transform: func(data any, spec any, modify voxgigstruct.Modify) any {
+			return voxgigstruct.TransformModify(data, spec, nil, modify)
+		},
+		Validate: voxgigstruct.Validate,
+		Walk:     voxgigstruct.Walk,
+	}
+}
+
+// A copy of the test corpus, as parsed JSON.
+func Corpus() (map[string]any, error) {
+	var all map[string]any
+	if err := json.Unmarshal(corpus, &all); nil != err {
+		return nil, err
+	}
+	return all, nil
+}
+
+// Run the test corpus against a subject, as subtests of t named by
+// area and case set (for example, "merge-cases").
+func RunConformance(t *testing.T, subject Subject) {
+	t.Helper()
+
+	sdk, err := runner.TestSDK(nil)
+	if nil != err {
+		t.Fatalf("Failed to create SDK: %v", err)
+	}
+
+	pack, err := runner.MakeRunnerData(corpus, sdk)("struct", map[string]any{})
+	if nil != err {
+		t.Fatalf("Failed to create runner: %v", err)
+	}
+
+	run := func(area string, sets []string, subject any) {
+		spec, _ := pack.Spec[area].(map[string]any)
+		for _, set := range sets {
+			t.Run(area+"-"+set, func(t *testing.T) {
+				if nil == spec[set] {
+					t.Fatalf("Missing test set: %s.%s", area, set)
+				}
+				pack.RunSet(t, spec[set], subject)
+			})
+		}
+	}
+
+	if nil != subject.GetPath {
+		run("getpath", []string{"basic"}, func(v any) any {
+			m := v.(map[string]any)
+			return subject.GetPath(m["path"], m["store"], nil)
+		})
+		run("getpath", []string{"current"}, func(v any) any {
+			m := v.(map[string]any)
+			return subject.GetPath(m["path"], m["store"], m["current"])
+		})
+	}
+
+	if nil != subject.Merge {
+		run("merge", []string{"cases", "array", "integrity"}, subject.Merge)
+	}
+
+	if nil != subject.Inject {
+		run("inject", []string{"string"}, func(v any) any {
+			m := v.(map[string]any)
+			return subject.Inject(m["val"], m["store"], runner.NullModifier, m["current"])
+		})
+		run("inject", []string{"deep"}, func(v any) any {
+			m := v.(map[string]any)
+			return subject.Inject(m["val"], m["store"], nil, nil)
+		})
+	}
+
+	if nil != subject.Transform {
+		run("transform", []string{"paths", "cmds", "each", "pack"}, func(v any) any {
+			m := v.(map[string]any)
+			return subject.Transform(m["data"], m["spec"], nil)
+		})
+		run("transform", []string{"modify"}, func(v any) any {
+			m := v.(map[string]any)
+			return subject.Transform(m["data"], m["spec"], _atModifier)
+		})
+	}
+
+	if nil != subject.Validate {
+		run("validate", []string{"basic", "child", "one", "exact", "invalid"}, func(v any) (any, error) {
+			m := v.(map[string]any)
+			return subject.Validate(m["data"], m["spec"])
+		})
+	}
+
+	if nil != subject.Walk {
+		run("walk", []string{"basic"}, func(v any) any {
+			if runner.NULLMARK == v {
+				v = nil
+			}
+			return subject.Walk(v, _pathWalker)
+		})
+	}
+}
+
+// Prefix string values with "@", as expected by transform.modify.
+func _atModifier(
+	val any,
+	key any,
+	parent any,
+	state *voxgigstruct.Injection,
+	current any,
+	store any,
+) {
+	if nil != key && nil != parent {
+		if strval, ok := val.(string); ok {
+			if pm, ok := parent.(map[string]any); ok {
+				pm[fmt.Sprint(key)] = "@" + strval
+			}
+		}
+	}
+}
+
+// Suffix string values with their path, as expected by walk.basic.
+func _pathWalker(key *string, val any, parent any, path []string) any {
+	if str, ok := val.(string); ok {
+		return str + "~" + strings.Join(path, ".")
+	}
+	return val
+}
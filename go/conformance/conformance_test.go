@@ -0,0 +1,38 @@
+package conformance_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/voxgig/struct"
+	"github.com/voxgig/struct/conformance"
+)
+
+func TestConformance(t *testing.T) {
+
+	conformance.RunConformance(t, conformance.DefaultSubject())
+
+	t.Run("corpus-current", func(t *testing.T) {
+		// The embedded corpus must match the source (see go generate).
+		src, err := os.ReadFile("../../build/test/test.json")
+		if nil != err {
+			t.Skip("No corpus source")
+		}
+		all, _ := conformance.Corpus()
+		embedded, _ := os.ReadFile("corpus.json")
+		if !bytes.Equal(src, embedded) || nil == all["struct"] {
+			t.Errorf("Embedded corpus is out of date: run go generate")
+		}
+	})
+
+	t.Run("corpus-wrapped", func(t *testing.T) {
+		// A wrapper that only replaces some operations.
+		subject := conformance.Subject{
+			Merge: func(val any) any {
+				return voxgigstruct.MergeWith(val, voxgigstruct.MergeOptions{})
+			},
+		}
+		conformance.RunConformance(t, subject)
+	})
+}
@@ -0,0 +1,41 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Function applied to each leaf (scalar) of a transform output, as it
+// is injected (see TransformOptions.Convert). The path is the output
+// path of the leaf. The returned value replaces the leaf.
+type Convert func(path []string, val any) any
+
+// Apply a Convert to each injected leaf, then call the original
+// modifier, if any, with the converted value.
+func _convertModify(convert Convert, modify Modify) Modify {
+	return func(val any, key any, parent any, state *Injection, current any, store any) {
+		if !IsNode(val) {
+			var path []string
+			if nil != state {
+				path = _outPath(state)
+			}
+			if cval := convert(path, val); !_equal(cval, val) {
+				val = cval
+				_setLeaf(parent, key, val)
+			}
+		}
+
+		if nil != modify {
+			modify(val, key, parent, state, current, store)
+		}
+	}
+}
+
+// Set an existing leaf, keeping nil values (SetProp would delete them).
+func _setLeaf(parent any, key any, val any) {
+	switch p := parent.(type) {
+	case map[string]any:
+		p[StrKey(key)] = val
+	case []any:
+		if i, ok := _listIndex(key, len(p)); ok {
+			p[i] = val
+		}
+	}
+}
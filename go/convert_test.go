@@ -0,0 +1,84 @@
+package voxgigstruct_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestConvert(t *testing.T) {
+
+	t.Run("convert-leaves", func(t *testing.T) {
+		data := map[string]any{
+			"name":  "  alice ",
+			"score": math.NaN(),
+			"items": []any{map[string]any{"v": " a "}, map[string]any{"v": "b"}},
+		}
+		spec := map[string]any{
+			"name":  "`name`",
+			"score": "`score`",
+			"kind":  " user ",
+			"items": []any{"`$EACH`", "items", map[string]any{"v": "`$COPY`"}},
+		}
+
+		paths := []string{}
+		out := voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{
+			Convert: func(path []string, val any) any {
+				paths = append(paths, strings.Join(path, "."))
+				if s, ok := val.(string); ok {
+					return strings.TrimSpace(s)
+				}
+				if f, ok := val.(float64); ok && math.IsNaN(f) {
+					return nil
+				}
+				return val
+			},
+		})
+
+		expected := map[string]any{
+			"name":  "alice",
+			"score": nil,
+			"kind":  "user",
+			"items": []any{map[string]any{"v": "a"}, map[string]any{"v": "b"}},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		for _, path := range []string{"name", "score", "kind", "items.0.v", "items.1.v"} {
+			found := false
+			for _, p := range paths {
+				found = found || path == p
+			}
+			if !found {
+				t.Errorf("Expected path %s in %v", path, paths)
+			}
+		}
+	})
+
+	t.Run("convert-modify", func(t *testing.T) {
+		// Modify sees the converted value.
+		seen := []any{}
+		out := voxgigstruct.TransformWith(map[string]any{"a": 1}, map[string]any{"x": "`a`"},
+			&voxgigstruct.TransformOptions{
+				Convert: func(path []string, val any) any {
+					if n, ok := val.(int); ok {
+						return n * 10
+					}
+					return val
+				},
+				Modify: func(val any, key any, parent any, state *voxgigstruct.Injection, current any, store any) {
+					if "x" == key {
+						seen = append(seen, val)
+					}
+				},
+			})
+
+		if !reflect.DeepEqual(map[string]any{"x": 10}, out) || !reflect.DeepEqual([]any{10}, seen) {
+			t.Errorf("Unexpected: %v %v", out, seen)
+		}
+	})
+}
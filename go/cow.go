@@ -0,0 +1,123 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// A copy-on-write clone of a data structure. The clone shares the
+// nodes of the original until they are changed through the clone:
+// each change copies only the nodes on the path to the change (each
+// node at most once), so unchanged subtrees are never copied. The
+// original must not be changed while the clone is in use, and values
+// read from the clone must not be changed directly (use SetPath).
+// A COW is not safe for concurrent use.
+type COW struct {
+	root   any
+	owned  map[uintptr]bool // Nodes that have been copied.
+	copies int
+}
+
+// Create a copy-on-write clone of a value.
+func CloneCOW(val any) *COW {
+	return &COW{root: val, owned: map[uintptr]bool{}}
+}
+
+// The current value of the clone.
+func (c *COW) Value() any {
+	return c.root
+}
+
+// Get a value at a key path, as for GetPath.
+func (c *COW) GetPath(path any) any {
+	return GetPath(path, c.root)
+}
+
+// Set a value at a key path, as for SetPath, copying shared nodes on
+// the path first.
+func (c *COW) SetPath(path any, val any) {
+	parts, ok := _pathParts(path)
+	if !ok {
+		return
+	}
+	c.own(parts)
+	c.root = SetPath(parts, c.root, val)
+	c.mark(parts)
+}
+
+// Delete a value at a key path, as for DelPath, copying shared nodes
+// on the path first.
+func (c *COW) DelPath(path any) {
+	parts, ok := _pathParts(path)
+	if !ok {
+		return
+	}
+	c.own(parts)
+	c.root = DelPath(parts, c.root)
+	c.mark(parts)
+}
+
+// Number of nodes copied so far.
+func (c *COW) Copies() int {
+	return c.copies
+}
+
+// Copy the shared nodes on a path, down to the parent of the last key.
+func (c *COW) own(parts []string) {
+	if 0 == len(parts) || (1 == len(parts) && S_MT == parts[0]) {
+		return
+	}
+
+	c.root = c.copy(c.root)
+	node := c.root
+	for _, part := range parts[:len(parts)-1] {
+		child, found := _getProp(node, part)
+		if !found || !IsNode(child) {
+			return
+		}
+		owned := c.copy(child)
+		_setLeaf(node, part, owned)
+		node = owned
+	}
+}
+
+// Mark the nodes on a path as owned, as nodes created by SetPath, and
+// lists that grew, are new.
+func (c *COW) mark(parts []string) {
+	if 0 == len(parts) {
+		return
+	}
+	node := c.root
+	for pI := 0; IsNode(node) && pI < len(parts); pI++ {
+		if id := _nodeID(node); 0 != id {
+			c.owned[id] = true
+		}
+		node, _ = _getProp(node, parts[pI])
+	}
+}
+
+// A shallow copy of a node, unless already copied.
+func (c *COW) copy(val any) any {
+	id := _nodeID(val)
+	if 0 != id && c.owned[id] {
+		return val
+	}
+
+	var out any
+	switch v := val.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, child := range v {
+			m[k] = child
+		}
+		out = m
+	case []any:
+		// No spare capacity, so appends cannot reach the original.
+		out = append(make([]any, 0, len(v)), v...)
+	default:
+		return val
+	}
+
+	if id := _nodeID(out); 0 != id {
+		c.owned[id] = true
+	}
+	c.copies++
+	return out
+}
@@ -0,0 +1,89 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestCOW(t *testing.T) {
+
+	newDoc := func() map[string]any {
+		return map[string]any{
+			"a": map[string]any{"b": map[string]any{"c": 1}, "d": []any{1, 2}},
+			"e": map[string]any{"f": 2},
+		}
+	}
+
+	t.Run("cow-set", func(t *testing.T) {
+		orig := newDoc()
+		cow := voxgigstruct.CloneCOW(orig)
+
+		cow.SetPath("a.b.c", 3)
+		cow.SetPath("a.b.x", 4)
+		cow.SetPath("a.d.0", 5)
+		cow.SetPath("a.d.2", 6)
+		cow.SetPath("n.m", 7)
+
+		if !reflect.DeepEqual(newDoc(), orig) {
+			t.Errorf("Expected original unchanged: %v", orig)
+		}
+
+		expected := map[string]any{
+			"a": map[string]any{"b": map[string]any{"c": 3, "x": 4}, "d": []any{5, 2, 6}},
+			"e": map[string]any{"f": 2},
+			"n": map[string]any{"m": 7},
+		}
+		if !reflect.DeepEqual(expected, cow.Value()) {
+			t.Errorf("Expected: %v, Got: %v", expected, cow.Value())
+		}
+
+		// Each node is copied at most once: root, a, a.b and a.d.
+		if 4 != cow.Copies() {
+			t.Errorf("Expected: 4, Got: %v", cow.Copies())
+		}
+
+		// Unchanged subtrees are shared.
+		out := cow.Value().(map[string]any)
+		if reflect.ValueOf(orig["e"]).Pointer() != reflect.ValueOf(out["e"]).Pointer() {
+			t.Errorf("Expected shared subtree")
+		}
+		if 5 != cow.GetPath("a.d.0") {
+			t.Errorf("Unexpected: %v", cow.GetPath("a.d.0"))
+		}
+	})
+
+	t.Run("cow-del", func(t *testing.T) {
+		orig := newDoc()
+		cow := voxgigstruct.CloneCOW(orig)
+
+		cow.DelPath("a.d.0")
+		cow.DelPath("e.f")
+		cow.DelPath("x.y")
+
+		if !reflect.DeepEqual(newDoc(), orig) {
+			t.Errorf("Expected original unchanged: %v", orig)
+		}
+
+		expected := map[string]any{
+			"a": map[string]any{"b": map[string]any{"c": 1}, "d": []any{2}},
+			"e": map[string]any{},
+		}
+		if !reflect.DeepEqual(expected, cow.Value()) {
+			t.Errorf("Expected: %v, Got: %v", expected, cow.Value())
+		}
+	})
+
+	t.Run("cow-list-root", func(t *testing.T) {
+		orig := []any{map[string]any{"a": 1}, 2}
+		cow := voxgigstruct.CloneCOW(orig)
+		cow.SetPath("0.a", 3)
+		cow.SetPath("2", 4)
+
+		if !reflect.DeepEqual([]any{map[string]any{"a": 1}, 2}, orig) ||
+			!reflect.DeepEqual([]any{map[string]any{"a": 3}, 2, 4}, cow.Value()) {
+			t.Errorf("Unexpected: %v %v", orig, cow.Value())
+		}
+	})
+}
@@ -0,0 +1,68 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"context"
+)
+
+// Walk a data structure depth first, as for Walk, checking the
+// context between nodes. If the context is done, the walk stops and
+// the context error is returned (values already applied are not
+// restored).
+func WalkCtx(
+	ctx context.Context,
+	val any,
+	apply WalkApply,
+) (any, error) {
+	return _walk(val, _noErr(apply), nil, nil, nil, WalkOptions{}, ctx)
+}
+
+// Transform data using a spec, as for TransformWith, checking the
+// context between nodes. If the context is done, the transform stops
+// and the context error is returned, with no output.
+func TransformCtx(
+	ctx context.Context,
+	data any,
+	spec any,
+	opts *TransformOptions,
+) (out any, err error) {
+	if err := ctx.Err(); nil != err {
+		return nil, err
+	}
+
+	copts := TransformOptions{}
+	if nil != opts {
+		copts = *opts
+	}
+
+	// Modify is called after each node is injected.
+	modify := copts.Modify
+	copts.Modify = func(val any, key any, parent any, state *Injection, current any, store any) {
+		if err := ctx.Err(); nil != err {
+			panic(transformAbort{err: err})
+		}
+		if nil != modify {
+			modify(val, key, parent, state, current, store)
+		}
+	}
+
+	defer func() {
+		if r := recover(); nil != r {
+			abort, ok := r.(transformAbort)
+			if !ok {
+				panic(r)
+			}
+			out = nil
+			err = abort.err
+		}
+	}()
+
+	return TransformWith(data, spec, &copts), nil
+}
+
+// Unwinds a transform when the context is done, or a limit is
+// exceeded.
+type transformAbort struct {
+	err error
+}
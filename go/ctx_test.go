@@ -0,0 +1,76 @@
+package voxgigstruct_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestCtx(t *testing.T) {
+
+	doc := map[string]any{"a": []any{1, 2, 3}, "b": map[string]any{"c": 4}}
+
+	t.Run("ctx-walk", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		count := 0
+		_, err := voxgigstruct.WalkCtx(ctx, doc, func(key *string, val any, parent any, path []string) any {
+			count++
+			if 2 == count {
+				cancel()
+			}
+			return val
+		})
+		if !errors.Is(err, context.Canceled) || 2 != count {
+			t.Errorf("Unexpected: %v %d", err, count)
+		}
+
+		out, err := voxgigstruct.WalkCtx(context.Background(), doc,
+			func(key *string, val any, parent any, path []string) any { return val })
+		if nil != err || !reflect.DeepEqual(doc, out) {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+	})
+
+	t.Run("ctx-transform", func(t *testing.T) {
+		spec := map[string]any{"x": "`a.0`", "y": "`$STOP`", "z": "`b.c`"}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		log := &testLogger{}
+		out, err := voxgigstruct.TransformCtx(ctx, doc, spec, &voxgigstruct.TransformOptions{
+			Extra:  map[string]any{"$STOP": func() any { cancel(); return true }},
+			Logger: log,
+		})
+		if !errors.Is(err, context.Canceled) || nil != out {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+		for _, entry := range log.entries {
+			if "DEBUG" != entry[:5] {
+				t.Errorf("Unexpected log: %v", entry)
+			}
+		}
+
+		// Already cancelled.
+		if _, err := voxgigstruct.TransformCtx(ctx, doc, spec, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		// Custom modifiers are still called.
+		called := 0
+		out, err = voxgigstruct.TransformCtx(context.Background(), doc, map[string]any{"x": "`a.0`"},
+			&voxgigstruct.TransformOptions{
+				Modify: func(val any, key any, parent any, state *voxgigstruct.Injection, current any, store any) {
+					called++
+				},
+			})
+		if nil != err || !reflect.DeepEqual(map[string]any{"x": 1}, out) || 0 == called {
+			t.Errorf("Unexpected: %v %v %d", out, err, called)
+		}
+	})
+}
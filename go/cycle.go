@@ -0,0 +1,71 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"reflect"
+)
+
+// Handling of nodes that contain themselves (directly or indirectly),
+// which would otherwise be walked forever. Nodes that are shared, but
+// do not contain themselves, are not cycles.
+type CycleMode int
+
+const (
+	CycleIgnore CycleMode = iota // Do not check for cycles (the default).
+	CycleBreak                   // Do not descend into a repeated node.
+	CycleMarker                  // Replace a repeated node with `$CYCLE`.
+	CycleError                   // Stop with an ErrCycle error.
+)
+
+// The identity of a node (zero for scalars and empty lists).
+func _nodeID(val any) uintptr {
+	switch v := val.(type) {
+	case map[string]any:
+		return reflect.ValueOf(v).Pointer()
+	case []any:
+		if 0 < len(v) {
+			return reflect.ValueOf(v).Pointer()
+		}
+	}
+	return 0
+}
+
+// Clone, replacing nodes that are their own ancestors with a marker.
+func _cloneCycle(val any, flags map[string]bool, ancestors map[uintptr]bool) any {
+	id := _nodeID(val)
+	if 0 == id {
+		switch val.(type) {
+		case map[string]any:
+			return map[string]any{}
+		case []any:
+			return []any{}
+		}
+		if IsFunc(val) && !flags["func"] {
+			return nil
+		}
+		return _cloneOther(val, flags)
+	}
+
+	if ancestors[id] {
+		return S_DCYCLE
+	}
+	ancestors[id] = true
+	defer delete(ancestors, id)
+
+	switch v := val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			out[key] = _cloneCycle(child, flags, ancestors)
+		}
+		return out
+	default:
+		list := val.([]any)
+		out := make([]any, len(list))
+		for i, child := range list {
+			out[i] = _cloneCycle(child, flags, ancestors)
+		}
+		return out
+	}
+}
@@ -0,0 +1,70 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestCycle(t *testing.T) {
+
+	cyclic := func() map[string]any {
+		shared := map[string]any{"s": 1}
+		doc := map[string]any{"a": shared, "b": shared, "l": []any{1}}
+		doc["c"] = map[string]any{"up": doc}
+		doc["l"].([]any)[0] = doc["l"]
+		return doc
+	}
+
+	t.Run("cycle-clone", func(t *testing.T) {
+		out := voxgigstruct.CloneFlags(cyclic(), map[string]bool{"cycle": true})
+		expected := map[string]any{
+			"a": map[string]any{"s": 1},
+			"b": map[string]any{"s": 1},
+			"c": map[string]any{"up": "$CYCLE"},
+			"l": []any{"$CYCLE"},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		if s := voxgigstruct.Stringify(cyclic()); "{a:{s:1},b:{s:1},c:{up:$CYCLE},l:[$CYCLE]}" != s {
+			t.Errorf("Unexpected: %v", s)
+		}
+	})
+
+	t.Run("cycle-walk", func(t *testing.T) {
+		visit := func(mode voxgigstruct.CycleMode) ([]string, any, error) {
+			paths := []string{}
+			out, err := voxgigstruct.WalkWith(cyclic(), func(key *string, val any, parent any, path []string) any {
+				paths = append(paths, strings.Join(path, "."))
+				return val
+			}, &voxgigstruct.WalkOptions{Cycles: mode})
+			return paths, out, err
+		}
+
+		all := []string{"a.s", "a", "b.s", "b", "c.up", "c", "l.0", "l", ""}
+
+		paths, out, err := visit(voxgigstruct.CycleBreak)
+		if nil != err || !reflect.DeepEqual(all, paths) {
+			t.Errorf("Unexpected: %v %v", paths, err)
+		}
+		if !reflect.DeepEqual(reflect.ValueOf(out).Pointer(),
+			reflect.ValueOf(voxgigstruct.GetPath("c.up", out)).Pointer()) {
+			t.Errorf("Cycle not kept")
+		}
+
+		paths, out, err = visit(voxgigstruct.CycleMarker)
+		if nil != err || !reflect.DeepEqual(all, paths) || "$CYCLE" != voxgigstruct.GetPath("c.up", out) {
+			t.Errorf("Unexpected: %v %v", paths, err)
+		}
+
+		_, _, err = visit(voxgigstruct.CycleError)
+		if !errors.Is(err, voxgigstruct.ErrCycle) || "Node contains itself (at c.up)" != err.Error() {
+			t.Errorf("Unexpected: %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const S_DDEFAULT = "$DEFAULT"
+
+// A default value for a missing field, written into the output (and
+// the data) by validation. The default is given after a colon, as
+// JSON, or else as a string: `$DEFAULT:10`, `$DEFAULT:info`. A
+// present value must have the type of the default. For defaults that
+// are nodes, or to validate a present value against a shape, use the
+// list form ['`$DEFAULT`', default, shape?]:
+//
+//	{ "tags": ['`$DEFAULT`', ['a'], ['`$CHILD`', '`$STRING`']] }
+//
+// A literal shape value (such as 10) is also a default, but a list or
+// map literal is a shape, rather than a default.
+var validate_DEFAULT Injector
+
+// Set after ValidateCollect is defined, as for validate_ONE.
+func init_validate_DEFAULT() {
+	validate_DEFAULT = func(
+		state *Injection,
+		_val any,
+		current any,
+		ref *string,
+		store any,
+	) any {
+		if S_MVAL != state.Mode {
+			return nil
+		}
+
+		path := state.Path
+		var dflt, shape any
+		hasShape := false
+		listForm := false
+		var parent any
+
+		if nil != ref && strings.HasPrefix(*ref, S_DDEFAULT+":") {
+			dflt = _defaultArg((*ref)[len(S_DDEFAULT)+1:])
+			parent = state.Parent
+
+		} else {
+			if !IsList(state.Parent) || 0 != state.KeyI || NumKeys(state.Parent) < 2 {
+				_invalidSpec(state, store, "The $DEFAULT validator at field "+Pathify(state.Path, 1, 1)+
+					" must have a default value.")
+				return nil
+			}
+
+			// Skip the arguments, and replace the list with the value.
+			args := _listify(state.Parent)
+			state.KeyI = len(state.Keys)
+			dflt = args[1]
+			shape, hasShape = GetProp(args, 2), 2 < len(args)
+
+			listForm = true
+			path = path[:len(path)-1]
+			parent = GetProp(state.Nodes, len(state.Nodes)-2)
+			state.Parent = map[string]any{}
+		}
+
+		// The data node of the field, so that the default is also
+		// visible to later references.
+		key := path[len(path)-1]
+		data := GetPath(path[1:len(path)-1], GetProp(store, S_DTOP))
+
+		out := GetProp(data, key)
+		if nil == out {
+			out = Clone(dflt)
+			if IsNode(data) {
+				SetProp(data, key, Clone(out))
+			}
+
+		} else if hasShape {
+			out = _validateAt(state, store, path, out, shape)
+
+		} else if Typify(dflt) != Typify(out) {
+			_invalidType(state, store, path, Typify(dflt), Typify(out), out)
+		}
+
+		SetProp(parent, key, out)
+
+		// The list form is detached, and has no value of its own.
+		if listForm {
+			return nil
+		}
+		return out
+	}
+}
+
+// The default value of `$DEFAULT:value`, as JSON, or else a string.
+func _defaultArg(arg string) any {
+	var val any
+	if err := json.Unmarshal([]byte(arg), &val); nil != err {
+		return arg
+	}
+	return val
+}
@@ -0,0 +1,88 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestValidateDefault(t *testing.T) {
+
+	spec := func() any {
+		return map[string]any{
+			"port":  "`$DEFAULT:8080`",
+			"level": "`$DEFAULT:info`",
+			"name":  "`$DEFAULT:\"x\"`",
+			"tags":  []any{"`$DEFAULT`", []any{"a"}, []any{"`$CHILD`", "`$STRING`"}},
+			"opts":  []any{"`$DEFAULT`", map[string]any{"debug": false}},
+			"sub":   map[string]any{"n": "`$DEFAULT:1`"},
+		}
+	}
+
+	t.Run("default-fill", func(t *testing.T) {
+		out, err := voxgigstruct.Validate(map[string]any{}, spec())
+		expected := map[string]any{
+			"port":  float64(8080),
+			"level": "info",
+			"name":  "x",
+			"tags":  []any{"a"},
+			"opts":  map[string]any{"debug": false},
+			"sub":   map[string]any{"n": float64(1)},
+		}
+		if nil != err || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, err)
+		}
+	})
+
+	t.Run("default-present", func(t *testing.T) {
+		data := map[string]any{
+			"port":  9000,
+			"level": "debug",
+			"name":  "y",
+			"tags":  []any{"b", "c"},
+			"opts":  map[string]any{"trace": true},
+			"sub":   map[string]any{"n": 2},
+		}
+		out, err := voxgigstruct.Validate(voxgigstruct.Clone(data), spec())
+		if nil != err || !reflect.DeepEqual(data, out) {
+			t.Errorf("Expected: %v, Got: %v %v", data, out, err)
+		}
+	})
+
+	t.Run("default-invalid", func(t *testing.T) {
+		_, verrs := voxgigstruct.ValidateAll(map[string]any{
+			"port": "80",
+			"tags": []any{"a", 1},
+			"opts": true,
+		}, spec())
+		expected := []string{
+			"Expected field port to be number, but found string: 80.",
+			"Expected field tags.1 to be string, but found number: 1.",
+			"Expected field opts to be object, but found boolean: true.",
+		}
+		for _, msg := range expected {
+			if !strings.Contains(verrs.Error(), msg) {
+				t.Errorf("Expected: %v, Got: %v", msg, verrs)
+			}
+		}
+
+		_, err := voxgigstruct.Validate(map[string]any{}, map[string]any{"a": []any{"`$DEFAULT`"}})
+		if nil == err || !strings.Contains(err.Error(), "must have a default value") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("default-ref", func(t *testing.T) {
+		// The default is visible to later references to the data.
+		out, err := voxgigstruct.Validate(map[string]any{}, map[string]any{
+			"a": "`$DEFAULT:3`",
+			"b": "`a`",
+		})
+		expected := map[string]any{"a": float64(3), "b": float64(3)}
+		if nil != err || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, err)
+		}
+	})
+}
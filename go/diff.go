@@ -0,0 +1,262 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"reflect"
+)
+
+// Change operation names.
+const (
+	S_add     = "add"
+	S_remove  = "remove"
+	S_replace = "replace"
+	S_update  = "update"
+)
+
+// A change to a node tree at a path. Operations are "add", "remove"
+// and "replace". For "add" and "replace", Value is the new value; for
+// "remove" and "replace", Old is the previous value.
+type Op struct {
+	Op    string
+	Path  []string
+	Value any
+	Old   any
+}
+
+// Report the changes that Merge would make to the first element of
+// the list, without modifying it.
+func MergeDry(val any) []Op {
+	if !IsList(val) {
+		return []Op{}
+	}
+
+	list := _listify(val)
+	if 0 == len(list) {
+		return []Op{}
+	}
+
+	clist := make([]any, len(list))
+	clist[0] = Clone(list[0])
+	copy(clist[1:], list[1:])
+
+	return _changes(list[0], Merge(clist), []string{}, []Op{})
+}
+
+// Report the changes that Inject would make to the value, without
+// modifying it.
+func InjectDry(val any, store any) []Op {
+	return _changes(val, Inject(Clone(val), store), []string{}, []Op{})
+}
+
+// Report the changes that a transform specification would make to
+// the data, where the transform output is treated as a new version
+// of the data. Neither the data nor the spec are modified.
+func TransformDry(data any, spec any, opts *TransformOptions) []Op {
+	return _changes(data, TransformWith(data, spec, opts), []string{}, []Op{})
+}
+
+// Describe the structural differences between two node trees, as a
+// list of changes (in node form, so the result can itself be stored,
+// serialized or transformed). Each change is a map with the
+// properties `op` ("add", "remove" or "replace"), `path` (a list of
+// keys), `value` (the new value, for add and replace) and `old` (the
+// previous value, for remove and replace). Changes are listed in the
+// order of the keys. An empty list means the trees are equal.
+func Diff(a any, b any) any {
+	ops := _changes(a, b, []string{}, []Op{})
+
+	out := make([]any, len(ops))
+	for oI, op := range ops {
+		path := make([]any, len(op.Path))
+		for pI, part := range op.Path {
+			path[pI] = part
+		}
+
+		change := map[string]any{
+			"op":   op.Op,
+			"path": path,
+		}
+		if S_remove != op.Op {
+			change["value"] = op.Value
+		}
+		if S_add != op.Op {
+			change["old"] = op.Old
+		}
+		out[oI] = change
+	}
+
+	return out
+}
+
+// Collect the changes needed to convert before into after. Map keys
+// are visited in sorted order. Removed list elements are reported
+// from the end of the list so that the operations can be applied in
+// sequence.
+func _changes(before any, after any, path []string, ops []Op) []Op {
+	if IsMap(before) && IsMap(after) {
+		bm := before.(map[string]any)
+		am := after.(map[string]any)
+
+		for _, k := range KeysOf(bm) {
+			if _, has := am[k]; !has {
+				ops = append(ops, Op{Op: S_remove, Path: _childPath(path, k), Old: bm[k]})
+			}
+		}
+
+		for _, k := range KeysOf(am) {
+			bv, has := bm[k]
+			if has {
+				ops = _changes(bv, am[k], _childPath(path, k), ops)
+			} else {
+				ops = append(ops, Op{Op: S_add, Path: _childPath(path, k), Value: am[k]})
+			}
+		}
+
+	} else if IsList(before) && IsList(after) {
+		bl := _listify(before)
+		al := _listify(after)
+
+		for i := 0; i < len(bl) && i < len(al); i++ {
+			ops = _changes(bl[i], al[i], _childPath(path, StrKey(i)), ops)
+		}
+
+		for i := len(bl) - 1; len(al) <= i; i-- {
+			ops = append(ops, Op{Op: S_remove, Path: _childPath(path, StrKey(i)), Old: bl[i]})
+		}
+
+		for i := len(bl); i < len(al); i++ {
+			ops = append(ops, Op{Op: S_add, Path: _childPath(path, StrKey(i)), Value: al[i]})
+		}
+
+	} else if !_equal(before, after) {
+		if nil == before {
+			ops = append(ops, Op{Op: S_add, Path: path, Value: after})
+		} else if nil == after {
+			ops = append(ops, Op{Op: S_remove, Path: path, Old: before})
+		} else {
+			ops = append(ops, Op{Op: S_replace, Path: path, Value: after, Old: before})
+		}
+	}
+
+	return ops
+}
+
+// Deep equality, where functions are equal if they are the same function.
+func _equal(a any, b any) bool {
+	if IsFunc(a) && IsFunc(b) {
+		return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Extend a path with a key, without sharing the backing array.
+func _childPath(path []string, key string) []string {
+	out := make([]string, len(path)+1)
+	copy(out, path)
+	out[len(path)] = key
+	return out
+}
+
+// The changed subtree of after, relative to before, in JSON Merge
+// Patch form. Also returns false if nothing changed. Unchanged maps
+// result in an empty map, and other unchanged values in nil.
+func _changedTree(before any, after any) (any, bool) {
+	if IsMap(before) && IsMap(after) {
+		bm := before.(map[string]any)
+		am := after.(map[string]any)
+		out := map[string]any{}
+
+		for k := range bm {
+			if _, has := am[k]; !has {
+				out[k] = nil
+			}
+		}
+
+		for k, av := range am {
+			bv, has := bm[k]
+			if !has {
+				out[k] = av
+			} else if sub, changed := _changedTree(bv, av); changed {
+				out[k] = sub
+			}
+		}
+
+		return out, 0 < len(out)
+	}
+
+	if _equal(before, after) {
+		return nil, false
+	}
+
+	return after, true
+}
+
+// A change to an element of a list, matched by key.
+type ListChange struct {
+	Op    string // Operation: "add", "update" or "remove".
+	Key   any    // Value at the key path of the element.
+	Index int    // Index in the new list (add, update) or old list (remove).
+	Value any    // New element (add, update).
+	Old   any    // Old element (update, remove).
+}
+
+// Reconcile two lists by matching elements on the value at keypath
+// (resolved with getpath), rather than by index. Removals are listed
+// first, in descending old index order, followed by additions and
+// updates in new list order. Unchanged elements are not listed, even
+// if their position has changed. Elements without a key, or with a
+// duplicate key, cannot be matched and are added or removed.
+func ReconcileList(oldlist any, newlist any, keypath any) []ListChange {
+	changes := []ListChange{}
+
+	ol := _listify(oldlist)
+	nl := _listify(newlist)
+
+	keystr := func(elem any) (any, string, bool) {
+		key := GetPath(keypath, elem)
+		if nil == key || IsNode(key) {
+			return key, S_MT, false
+		}
+		return key, Typify(key) + S_CN + Stringify(key), true
+	}
+
+	// Index the old list by key.
+	oldindex := map[string]int{}
+	for oI, elem := range ol {
+		if _, ks, ok := keystr(elem); ok {
+			if _, dup := oldindex[ks]; !dup {
+				oldindex[ks] = oI
+			}
+		}
+	}
+
+	matched := make([]bool, len(ol))
+	var addupdate []ListChange
+
+	for nI, elem := range nl {
+		key, ks, ok := keystr(elem)
+		oI, has := oldindex[ks]
+
+		if ok && has && !matched[oI] {
+			matched[oI] = true
+			if !_equal(ol[oI], elem) {
+				addupdate = append(addupdate,
+					ListChange{Op: S_update, Key: key, Index: nI, Value: elem, Old: ol[oI]})
+			}
+		} else {
+			addupdate = append(addupdate,
+				ListChange{Op: S_add, Key: key, Index: nI, Value: elem})
+		}
+	}
+
+	for oI := len(ol) - 1; -1 < oI; oI-- {
+		if !matched[oI] {
+			key, _, _ := keystr(ol[oI])
+			changes = append(changes,
+				ListChange{Op: S_remove, Key: key, Index: oI, Old: ol[oI]})
+		}
+	}
+
+	return append(changes, addupdate...)
+}
@@ -0,0 +1,156 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestDiff(t *testing.T) {
+
+	t.Run("diff-merge-dry", func(t *testing.T) {
+		base := map[string]any{"a": 1, "b": map[string]any{"c": 2}, "d": []any{1, 2}}
+		overlay := map[string]any{"a": 1, "b": map[string]any{"c": 3, "e": 4}, "d": []any{5}}
+
+		ops := voxgigstruct.MergeDry([]any{base, overlay})
+
+		expected := []voxgigstruct.Op{
+			{Op: "replace", Path: []string{"b", "c"}, Value: 3, Old: 2},
+			{Op: "add", Path: []string{"b", "e"}, Value: 4},
+			{Op: "replace", Path: []string{"d", "0"}, Value: 5, Old: 1},
+		}
+		if !reflect.DeepEqual(expected, ops) {
+			t.Errorf("Expected: %v, Got: %v", expected, ops)
+		}
+
+		// Not modified.
+		if 2 != base["b"].(map[string]any)["c"] {
+			t.Errorf("Merge target was modified: %v", base)
+		}
+	})
+
+	t.Run("diff-inject-dry", func(t *testing.T) {
+		val := map[string]any{"x": "`a`", "y": 1, "z": []any{"`b`", 2}}
+		ops := voxgigstruct.InjectDry(val, map[string]any{"a": "A", "b": 1})
+
+		expected := []voxgigstruct.Op{
+			{Op: "replace", Path: []string{"x"}, Value: "A", Old: "`a`"},
+			{Op: "replace", Path: []string{"z", "0"}, Value: 1, Old: "`b`"},
+		}
+		if !reflect.DeepEqual(expected, ops) {
+			t.Errorf("Expected: %v, Got: %v", expected, ops)
+		}
+
+		if "`a`" != val["x"] {
+			t.Errorf("Inject value was modified: %v", val)
+		}
+	})
+
+	t.Run("diff-transform-dry", func(t *testing.T) {
+		data := map[string]any{"a": 1, "b": 2, "c": []any{1, 2, 3}}
+		spec := map[string]any{"a": 1, "b": "`a`", "c": []any{1}, "n": true}
+		ops := voxgigstruct.TransformDry(data, spec, nil)
+
+		expected := []voxgigstruct.Op{
+			{Op: "replace", Path: []string{"b"}, Value: 1, Old: 2},
+			{Op: "remove", Path: []string{"c", "2"}, Old: 3},
+			{Op: "remove", Path: []string{"c", "1"}, Old: 2},
+			{Op: "add", Path: []string{"n"}, Value: true},
+		}
+		if !reflect.DeepEqual(expected, ops) {
+			t.Errorf("Expected: %v, Got: %v", expected, ops)
+		}
+	})
+
+	t.Run("diff-transform-previous", func(t *testing.T) {
+		spec := map[string]any{"id": "`id`", "n": "`n`", "tags": "`tags`", "x": map[string]any{"y": "`y`", "z": 1}}
+
+		data0 := map[string]any{"id": "a", "n": 1, "tags": []any{"t0"}, "y": 2}
+		prev := voxgigstruct.Transform(data0, spec)
+
+		data1 := map[string]any{"id": "a", "n": 2, "tags": []any{"t0", "t1"}, "y": 2}
+		result0 := voxgigstruct.TransformWith(data1, spec, &voxgigstruct.TransformOptions{Previous: prev})
+		expected0 := map[string]any{"n": 2, "tags": []any{"t0", "t1"}}
+		if !reflect.DeepEqual(expected0, result0) {
+			t.Errorf("Expected: %v, Got: %v", expected0, result0)
+		}
+
+		result1 := voxgigstruct.TransformWith(data0, spec, &voxgigstruct.TransformOptions{Previous: prev})
+		expected1 := map[string]any{}
+		if !reflect.DeepEqual(expected1, result1) {
+			t.Errorf("Expected: %v, Got: %v", expected1, result1)
+		}
+
+		data2 := map[string]any{"id": "a", "n": 1, "tags": []any{"t0"}, "y": 3}
+		result2 := voxgigstruct.TransformWith(data2, spec, &voxgigstruct.TransformOptions{Previous: prev})
+		expected2 := map[string]any{"x": map[string]any{"y": 3}}
+		if !reflect.DeepEqual(expected2, result2) {
+			t.Errorf("Expected: %v, Got: %v", expected2, result2)
+		}
+
+		data3 := map[string]any{"id": "a", "tags": []any{"t0"}, "y": 2}
+		result3 := voxgigstruct.TransformWith(data3, spec, &voxgigstruct.TransformOptions{Previous: prev})
+		expected3 := map[string]any{"n": nil}
+		if !reflect.DeepEqual(expected3, result3) {
+			t.Errorf("Expected: %v, Got: %v", expected3, result3)
+		}
+	})
+
+	t.Run("diff-reconcile-list", func(t *testing.T) {
+		a := map[string]any{"id": "a", "v": 1}
+		b := map[string]any{"id": "b", "v": 2}
+		c := map[string]any{"id": "c", "v": 3}
+		b2 := map[string]any{"id": "b", "v": 22}
+		d := map[string]any{"id": "d", "v": 4}
+
+		changes := voxgigstruct.ReconcileList([]any{a, b, c}, []any{b2, c, d}, "id")
+
+		expected := []voxgigstruct.ListChange{
+			{Op: "remove", Key: "a", Index: 0, Old: a},
+			{Op: "update", Key: "b", Index: 0, Value: b2, Old: b},
+			{Op: "add", Key: "d", Index: 2, Value: d},
+		}
+		if !reflect.DeepEqual(expected, changes) {
+			t.Errorf("Expected: %v, Got: %v", expected, changes)
+		}
+
+		nested := voxgigstruct.ReconcileList(
+			[]any{map[string]any{"k": map[string]any{"id": 1}}},
+			[]any{map[string]any{"k": map[string]any{"id": 1}}, map[string]any{"x": 1}},
+			"k.id",
+		)
+		expectedNested := []voxgigstruct.ListChange{
+			{Op: "add", Key: nil, Index: 1, Value: map[string]any{"x": 1}},
+		}
+		if !reflect.DeepEqual(expectedNested, nested) {
+			t.Errorf("Expected: %v, Got: %v", expectedNested, nested)
+		}
+	})
+
+	t.Run("diff-structural", func(t *testing.T) {
+		a := map[string]any{"a": 1, "b": map[string]any{"c": 2, "d": 3}, "e": []any{1, 2}}
+		b := map[string]any{"a": 1, "b": map[string]any{"c": 4}, "e": []any{1}, "f": true}
+
+		result := voxgigstruct.Diff(a, b)
+		expected := []any{
+			map[string]any{"op": "remove", "path": []any{"b", "d"}, "old": 3},
+			map[string]any{"op": "replace", "path": []any{"b", "c"}, "value": 4, "old": 2},
+			map[string]any{"op": "remove", "path": []any{"e", "1"}, "old": 2},
+			map[string]any{"op": "add", "path": []any{"f"}, "value": true},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		if result := voxgigstruct.Diff(a, voxgigstruct.Clone(a)); !reflect.DeepEqual([]any{}, result) {
+			t.Errorf("Expected no changes, Got: %v", result)
+		}
+
+		result = voxgigstruct.Diff(1, "x")
+		expected = []any{map[string]any{"op": "replace", "path": []any{}, "value": "x", "old": 1}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+// Package voxgigstruct provides uniform manipulation of JSON-like
+// data structures: paths, merge, walk, inject, transform and validate.
+//
+// # Ordering
+//
+// Map keys are always processed in sorted order, wherever the order
+// can affect output, warnings or errors: KeysOf and Items return
+// sorted keys, injection descends in sorted key order (with
+// transforms after normal keys), and transforms that read maps, such
+// as `$EACH`, `$PACK` and `$ZIP`, iterate in key order. The same input
+// therefore always gives the same output, so there is no option to
+// enable this. Go maps are only ranged directly when the order cannot
+// be observed, such as when copying a map.
+package voxgigstruct
@@ -0,0 +1,331 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+// Support for editors of transform specifications (for example, a
+// language server): completions of paths and transform names at a
+// cursor, and diagnostics of unknown transforms and unresolved paths.
+// Specs are JSON text, which may be incomplete while being edited.
+// Offsets are byte offsets into the text.
+package editor
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	vs "github.com/voxgig/struct"
+)
+
+// Kinds of completion.
+const (
+	KindPath      = "path"
+	KindTransform = "transform"
+)
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic codes.
+const (
+	CodeSyntax           = "syntax"            // Malformed injection (such as an unmatched backtick).
+	CodeUnknownTransform = "unknown-transform" // A `$NAME` that is not a transform.
+	CodeUnresolvedPath   = "unresolved-path"   // A path with no value in the sample store.
+)
+
+// Options for an editor session.
+type Options struct {
+	// Sample data, used to complete and check paths.
+	Store any
+
+	// Names of custom transforms (such as the `$` keys of
+	// TransformOptions.Extra), in addition to the built in transforms.
+	Transforms []string
+}
+
+// A completion at a cursor.
+type Completion struct {
+	Label  string // The completed key or name.
+	Kind   string // KindPath or KindTransform.
+	Detail string // Type of the value (paths only).
+	Start  int    // Start of the text to replace with the label.
+	End    int    // End of the text to replace (the cursor).
+}
+
+// A problem found in a spec.
+type Diagnostic struct {
+	Start    int
+	End      int
+	Severity string
+	Code     string
+	Message  string
+}
+
+// An editor session, for one sample store.
+type Session struct {
+	store      any
+	transforms []string
+}
+
+// Create a session.
+func New(opts *Options) *Session {
+	s := &Session{transforms: vs.TransformNames()}
+	if nil != opts {
+		s.store = opts.Store
+		for _, name := range opts.Transforms {
+			if !strings.HasPrefix(name, vs.S_DS) {
+				name = vs.S_DS + name
+			}
+			s.transforms = append(s.transforms, name)
+		}
+	}
+	sort.Strings(s.transforms)
+	return s
+}
+
+// Completions at a cursor offset in the spec text. The cursor must
+// be inside an injection (after an opening backtick) of a JSON
+// string. Transform names are completed after `$`, and otherwise the
+// last part of the path is completed with the keys of the sample
+// store at the parent path.
+func (s *Session) Complete(spec []byte, offset int) []Completion {
+	out := []Completion{}
+
+	str := _stringAt(spec, offset)
+	if nil == str {
+		return out
+	}
+
+	// The text of the injection before the cursor.
+	cursor := str.decodedOffset(offset)
+	before := str.text[:cursor]
+	bI := strings.LastIndex(before, vs.S_BT)
+	if -1 == bI || 0 == strings.Count(before, vs.S_BT)%2 {
+		return out
+	}
+	prefix := before[bI+1:]
+
+	if strings.HasPrefix(prefix, vs.S_DS) {
+		for _, name := range s.transforms {
+			if strings.HasPrefix(name, prefix) && vs.S_DS != name {
+				out = append(out, Completion{
+					Label: name,
+					Kind:  KindTransform,
+					Start: str.raw[bI+1],
+					End:   offset,
+				})
+			}
+		}
+		return out
+	}
+
+	parent, part := "", prefix
+	if dI := strings.LastIndex(prefix, vs.S_DT); -1 < dI {
+		parent, part = prefix[:dI], prefix[dI+1:]
+	}
+
+	node := s.store
+	if vs.S_MT != parent {
+		node = vs.GetPath(parent, s.store)
+	}
+	if !vs.IsNode(node) {
+		return out
+	}
+
+	start := str.raw[bI+1+len(prefix)-len(part)]
+	for _, key := range vs.KeysOf(node) {
+		if strings.HasPrefix(key, part) {
+			out = append(out, Completion{
+				Label:  key,
+				Kind:   KindPath,
+				Detail: vs.Typify(vs.GetProp(node, key)),
+				Start:  start,
+				End:    offset,
+			})
+		}
+	}
+	return out
+}
+
+// Problems in the spec text, in order: malformed injections, unknown
+// transforms (errors), and paths that do not resolve in the sample
+// store (warnings, as a path may be relative to data that the sample
+// does not have, such as the items of `$EACH`).
+func (s *Session) Diagnose(spec []byte) []Diagnostic {
+	out := []Diagnostic{}
+
+	for _, str := range _strings(spec) {
+		segs, err := vs.ParseInjection(str.text)
+		if nil != err {
+			start := str.raw[strings.Index(str.text, vs.S_BT)]
+			for _, seg := range segs {
+				if vs.SegmentLiteral == seg.Kind {
+					if bI := strings.Index(seg.Text, vs.S_BT); -1 < bI {
+						start = str.raw[seg.Start+bI]
+						break
+					}
+				}
+			}
+			out = append(out, Diagnostic{
+				Start:    start,
+				End:      start + 1,
+				Severity: SeverityError,
+				Code:     CodeSyntax,
+				Message:  "Unmatched backtick",
+			})
+		}
+
+		for _, seg := range segs {
+			if vs.SegmentRef != seg.Kind {
+				continue
+			}
+			start, end := str.raw[seg.Start], str.raw[seg.End]
+
+			if seg.Transform {
+				if !s.isTransform(seg.Text) {
+					out = append(out, Diagnostic{
+						Start:    start,
+						End:      end,
+						Severity: SeverityError,
+						Code:     CodeUnknownTransform,
+						Message:  "Unknown transform: " + seg.Text,
+					})
+				}
+			} else if !s.resolves(seg.Text) {
+				out = append(out, Diagnostic{
+					Start:    start,
+					End:      end,
+					Severity: SeverityWarning,
+					Code:     CodeUnresolvedPath,
+					Message:  "Path not found in sample data: " + seg.Text,
+				})
+			}
+		}
+	}
+
+	return out
+}
+
+func (s *Session) isTransform(name string) bool {
+	// Ordering digits are allowed, as in `$MERGE1`.
+	name = strings.TrimRight(name, "0123456789")
+	i := sort.SearchStrings(s.transforms, name)
+	return i < len(s.transforms) && name == s.transforms[i]
+}
+
+// A path resolves in the store, or is relative (and so cannot be
+// checked).
+func (s *Session) resolves(path string) bool {
+	if strings.HasPrefix(path, vs.S_DT) {
+		return true
+	}
+	path = strings.TrimPrefix(path, vs.S_DTOP+vs.S_DT)
+	return vs.HasPath(path, s.store)
+}
+
+// A JSON string literal in the spec text.
+type jsonString struct {
+	text string // Decoded text.
+	raw  []int  // Offset in the spec of each byte of text, and of the end.
+}
+
+// The offset in the decoded text of a spec offset.
+func (js *jsonString) decodedOffset(offset int) int {
+	for i, r := range js.raw {
+		if offset <= r {
+			return i
+		}
+	}
+	return len(js.text)
+}
+
+// The string literal that contains an offset (after its opening
+// quote), if any. An unterminated string ends at the end of the line.
+func _stringAt(spec []byte, offset int) *jsonString {
+	for _, str := range _strings(spec) {
+		if str.raw[0] <= offset && offset <= str.raw[len(str.raw)-1] {
+			return str
+		}
+	}
+	return nil
+}
+
+// The string literals of JSON text, decoded.
+func _strings(spec []byte) []*jsonString {
+	out := []*jsonString{}
+
+	for i := 0; i < len(spec); i++ {
+		if '"' != spec[i] {
+			continue
+		}
+
+		str := &jsonString{}
+		var sb strings.Builder
+		j := i + 1
+	scan:
+		for j < len(spec) {
+			c := spec[j]
+			switch {
+			case '"' == c || '\n' == c:
+				break scan
+
+			case '\\' == c && j+1 < len(spec):
+				n, size := _unescape(spec[j:])
+				for k := 0; k < len(n); k++ {
+					str.raw = append(str.raw, j)
+				}
+				sb.WriteString(n)
+				j += size
+
+			default:
+				str.raw = append(str.raw, j)
+				sb.WriteByte(c)
+				j++
+			}
+		}
+
+		str.text = sb.String()
+		str.raw = append(str.raw, j)
+		out = append(out, str)
+		i = j
+	}
+
+	return out
+}
+
+// Decode an escape sequence, returning the text and the number of
+// bytes used.
+func _unescape(esc []byte) (string, int) {
+	switch esc[1] {
+	case 'b':
+		return "\b", 2
+	case 'f':
+		return "\f", 2
+	case 'n':
+		return "\n", 2
+	case 'r':
+		return "\r", 2
+	case 't':
+		return "\t", 2
+	case 'u':
+		if 6 <= len(esc) {
+			if code, err := strconv.ParseUint(string(esc[2:6]), 16, 32); nil == err {
+				r := rune(code)
+				// Surrogate pairs.
+				if 0xD800 <= r && r < 0xDC00 && 12 <= len(esc) && '\\' == esc[6] && 'u' == esc[7] {
+					if low, err := strconv.ParseUint(string(esc[8:12]), 16, 32); nil == err {
+						r = 0x10000 + (r-0xD800)<<10 + (rune(low) - 0xDC00)
+						return string(r), 12
+					}
+				}
+				if !utf8.ValidRune(r) {
+					r = utf8.RuneError
+				}
+				return string(r), 6
+			}
+		}
+	}
+	return string(esc[1]), 2
+}
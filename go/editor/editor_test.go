@@ -0,0 +1,112 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package editor_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct/editor"
+)
+
+var store = map[string]any{
+	"order": map[string]any{
+		"id":    "o1",
+		"items": []any{map[string]any{"sku": "a1"}},
+		"total": 12.5,
+	},
+	"owner": "alice",
+}
+
+func labels(cs []editor.Completion) []string {
+	out := []string{}
+	for _, c := range cs {
+		out = append(out, c.Label)
+	}
+	return out
+}
+
+func TestEditor(t *testing.T) {
+	s := editor.New(&editor.Options{Store: store, Transforms: []string{"UPPER"}})
+
+	t.Run("editor-complete-path", func(t *testing.T) {
+		spec := []byte(`{"a":"` + "`o" + `"}`)
+		offset := strings.Index(string(spec), "`o") + 2
+
+		cs := s.Complete(spec, offset)
+		if !reflect.DeepEqual([]string{"order", "owner"}, labels(cs)) {
+			t.Errorf("Expected: %v, Got: %v", []string{"order", "owner"}, labels(cs))
+		}
+		if offset-1 != cs[0].Start || offset != cs[0].End || "object" != cs[0].Detail {
+			t.Errorf("Expected: %v, Got: %v", offset-1, cs[0])
+		}
+
+		spec = []byte(`{"a":"` + "`order.t`" + `"}`)
+		offset = strings.Index(string(spec), ".t") + 2
+		cs = s.Complete(spec, offset)
+		if 1 != len(cs) || "total" != cs[0].Label || "number" != cs[0].Detail {
+			t.Errorf("Expected: %v, Got: %v", "total", cs)
+		}
+
+		// Not inside an injection.
+		spec = []byte(`{"a":"order"}`)
+		if cs = s.Complete(spec, 9); 0 != len(cs) {
+			t.Errorf("Expected: %v, Got: %v", 0, len(cs))
+		}
+	})
+
+	t.Run("editor-complete-transform", func(t *testing.T) {
+		spec := []byte(`{"a":["` + "`$E" + `"`)
+		cs := s.Complete(spec, len(spec)-1)
+		if !reflect.DeepEqual([]string{"$EACH"}, labels(cs)) {
+			t.Errorf("Expected: %v, Got: %v", []string{"$EACH"}, labels(cs))
+		}
+
+		spec = []byte(`{"a":"` + "`$U" + `"}`)
+		cs = s.Complete(spec, strings.Index(string(spec), "$U")+2)
+		if !reflect.DeepEqual([]string{"$UPPER", "$UUID"}, labels(cs)) {
+			t.Errorf("Expected: %v, Got: %v", []string{"$UPPER", "$UUID"}, labels(cs))
+		}
+	})
+
+	t.Run("editor-diagnose", func(t *testing.T) {
+		spec := `{"a":"` + "`order.id`" + `","b":"` + "`order.nope`" +
+			`","c":["` + "`$EACH`" + `","` + "`$NOPE`" + `"],"d":"x ` + "`owner" +
+			`","e":"` + "`.rel`" + `","f":"` + "`$UPPER`" + `"}`
+
+		ds := s.Diagnose([]byte(spec))
+		codes := []string{}
+		for _, d := range ds {
+			codes = append(codes, d.Code)
+		}
+		expected := []string{
+			editor.CodeUnresolvedPath,
+			editor.CodeUnknownTransform,
+			editor.CodeSyntax,
+		}
+		if !reflect.DeepEqual(expected, codes) {
+			t.Errorf("Expected: %v, Got: %v", expected, ds)
+		}
+
+		d := ds[0]
+		if "`order.nope`" != spec[d.Start:d.End] || editor.SeverityWarning != d.Severity {
+			t.Errorf("Expected: %v, Got: %v", "`order.nope`", spec[d.Start:d.End])
+		}
+		if "`$NOPE`" != spec[ds[1].Start:ds[1].End] {
+			t.Errorf("Expected: %v, Got: %v", "`$NOPE`", spec[ds[1].Start:ds[1].End])
+		}
+		if "`" != spec[ds[2].Start:ds[2].End] || ds[2].Start != strings.Index(spec, "`owner") {
+			t.Errorf("Expected: %v, Got: %v", strings.Index(spec, "`owner"), ds[2].Start)
+		}
+	})
+
+	t.Run("editor-escapes", func(t *testing.T) {
+		// Offsets are in the raw text, after escapes.
+		spec := `{"a":"é\n` + "`order.nope`" + `"}`
+		ds := s.Diagnose([]byte(spec))
+		if 1 != len(ds) || "`order.nope`" != spec[ds[0].Start:ds[0].End] {
+			t.Errorf("Expected: %v, Got: %v", "`order.nope`", ds)
+		}
+	})
+}
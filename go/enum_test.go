@@ -0,0 +1,69 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestEnum(t *testing.T) {
+
+	spec := func() any {
+		return map[string]any{
+			"level": []any{"`$ONE`", "debug", "info", "warn"},
+			"size":  []any{"`$ONE`", "`$STRING`", 1, 2},
+		}
+	}
+
+	t.Run("enum-match", func(t *testing.T) {
+		out, err := voxgigstruct.Validate(map[string]any{"level": "info", "size": 2.0}, spec())
+		expected := map[string]any{"level": "info", "size": 2.0}
+		if nil != err || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, err)
+		}
+
+		// Validators and enum values can be mixed.
+		out, err = voxgigstruct.Validate(map[string]any{"level": "warn", "size": "L"}, spec())
+		expected = map[string]any{"level": "warn", "size": "L"}
+		if nil != err || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, err)
+		}
+
+		// The first value is the default.
+		out, err = voxgigstruct.Validate(map[string]any{"size": 1}, spec())
+		expected = map[string]any{"level": "debug", "size": 1}
+		if nil != err || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, err)
+		}
+	})
+
+	t.Run("enum-reject", func(t *testing.T) {
+		errs := voxgigstruct.ListRefCreate[any]()
+		out, _ := voxgigstruct.ValidateCollect(
+			map[string]any{"level": "trace", "size": 3}, spec(), nil, errs)
+
+		expected := []any{
+			"Expected field level to be one of debug, info, warn, but found string: trace.",
+			"Expected field size to be one of string, 1, 2, but found number: 3.",
+		}
+		if !reflect.DeepEqual(expected, errs.List) {
+			t.Errorf("Expected: %v, Got: %v", expected, errs.List)
+		}
+
+		// The offending value is kept.
+		if "trace" != voxgigstruct.GetProp(out, "level") {
+			t.Errorf("Expected: %v, Got: %v", "trace", out)
+		}
+
+		// Types must match.
+		_, err := voxgigstruct.Validate(map[string]any{"level": "info", "size": "1"}, map[string]any{
+			"level": "`$STRING`",
+			"size":  []any{"`$ONE`", 1, 2},
+		})
+		if nil == err || !strings.Contains(err.Error(), "found string: 1") {
+			t.Errorf("Expected: %v, Got: %v", "found string: 1", err)
+		}
+	})
+}
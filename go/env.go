@@ -0,0 +1,94 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Sources of nondeterminism used by transforms ($WHEN, $RANDOM,
+// $UUID). Provide fixed sources to make transform output
+// reproducible. Nil fields use the default source.
+type Env struct {
+	Clock func() time.Time // Current time.
+	Rand  func() float64   // Random number in [0.0,1.0).
+	IDGen func() string    // Unique identifier.
+}
+
+// The default environment: system clock, math/rand and random v4 UUIDs.
+func DefaultEnv() *Env {
+	return &Env{
+		Clock: time.Now,
+		Rand:  rand.Float64,
+		IDGen: _uuid(crand.Read),
+	}
+}
+
+// A deterministic environment: the clock is fixed at the given time,
+// and random numbers and identifiers are generated from the seed. The
+// environment is safe for concurrent use, but the sequence of values
+// seen by each transform is then not deterministic, so use a fresh
+// environment for each transform to reproduce its output.
+func SeededEnv(seed int64, now time.Time) *Env {
+	src := &lockedRand{src: rand.New(rand.NewSource(seed))}
+	return &Env{
+		Clock: func() time.Time { return now },
+		Rand:  src.Float64,
+		IDGen: _uuid(src.Read),
+	}
+}
+
+// A random source that is safe for concurrent use, as *rand.Rand is not.
+type lockedRand struct {
+	mutex sync.Mutex
+	src   *rand.Rand
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.src.Float64()
+}
+
+func (r *lockedRand) Read(b []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.src.Read(b)
+}
+
+// Get the environment of an injection store, filling in defaults.
+func StoreEnv(store any) *Env {
+	env, _ := GetProp(store, S_DENV).(*Env)
+	return _resolveEnv(env)
+}
+
+func _resolveEnv(env *Env) *Env {
+	out := DefaultEnv()
+	if nil != env {
+		if nil != env.Clock {
+			out.Clock = env.Clock
+		}
+		if nil != env.Rand {
+			out.Rand = env.Rand
+		}
+		if nil != env.IDGen {
+			out.IDGen = env.IDGen
+		}
+	}
+	return out
+}
+
+// Generate version 4 UUIDs from a source of random bytes.
+func _uuid(read func([]byte) (int, error)) func() string {
+	return func() string {
+		b := make([]byte, 16)
+		read(b)
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	}
+}
@@ -0,0 +1,69 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors, for use with errors.Is. Errors returned by this
+// package wrap one of these, so callers can branch on the kind of
+// failure without parsing messages.
+var (
+	ErrNotFound   = errors.New("not found")          // A referenced value or resource is missing.
+	ErrType       = errors.New("invalid type")       // A value has the wrong type.
+	ErrIndexRange = errors.New("index out of range") // A list index is invalid.
+	ErrSpec       = errors.New("invalid spec")       // A path, pointer, reference, or spec is malformed.
+	ErrLimit      = errors.New("limit exceeded")     // A configured limit was reached.
+	ErrCycle      = errors.New("cycle")              // A node contains itself.
+
+	ErrLimitExceeded = ErrLimit // Alias of ErrLimit, as returned for Limits.
+)
+
+// An error with path context. Kind is one of the sentinel errors, and
+// Cause is the underlying error, if any. Both can be matched with
+// errors.Is and errors.As.
+type PathError struct {
+	Kind  error
+	Path  []string
+	Msg   string
+	Cause error
+}
+
+func (e *PathError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Msg)
+	if 0 < len(e.Path) {
+		sb.WriteString(" (at ")
+		sb.WriteString(strings.Join(e.Path, S_DT))
+		sb.WriteString(")")
+	}
+	if nil != e.Cause {
+		sb.WriteString(": ")
+		sb.WriteString(e.Cause.Error())
+	}
+	return sb.String()
+}
+
+func (e *PathError) Unwrap() []error {
+	errs := []error{}
+	if nil != e.Kind {
+		errs = append(errs, e.Kind)
+	}
+	if nil != e.Cause {
+		errs = append(errs, e.Cause)
+	}
+	return errs
+}
+
+// Create a PathError. The path may be nil.
+func NewPathError(kind error, path []string, cause error, format string, args ...any) *PathError {
+	return &PathError{
+		Kind:  kind,
+		Path:  path,
+		Msg:   fmt.Sprintf(format, args...),
+		Cause: cause,
+	}
+}
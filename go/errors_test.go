@@ -0,0 +1,52 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestErrors(t *testing.T) {
+
+	t.Run("errors-sentinel", func(t *testing.T) {
+		_, err := voxgigstruct.PointerToPath("a")
+		if !errors.Is(err, voxgigstruct.ErrSpec) {
+			t.Errorf("Expected ErrSpec, Got: %v", err)
+		}
+
+		_, err = voxgigstruct.SetPointer("/a/x", map[string]any{"a": []any{1}}, 2)
+		var perr *voxgigstruct.PathError
+		if !errors.Is(err, voxgigstruct.ErrIndexRange) || !errors.As(err, &perr) ||
+			"a.x" != strings.Join(perr.Path, ".") {
+			t.Errorf("Expected ErrIndexRange at a.x, Got: %v", err)
+		}
+		if "Invalid list index in JSON Pointer: /a/x (at a.x)" != err.Error() {
+			t.Errorf("Unexpected message: %v", err)
+		}
+
+		_, err = voxgigstruct.ResolveRefs(map[string]any{"$ref": "#/missing"}, nil)
+		if !errors.Is(err, voxgigstruct.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, Got: %v", err)
+		}
+
+		cause := errors.New("offline")
+		_, err = voxgigstruct.ResolveRefs(map[string]any{"$ref": "x.json"}, &voxgigstruct.RefOptions{
+			Loader: func(uri string) (any, error) { return nil, cause },
+		})
+		if !errors.Is(err, voxgigstruct.ErrNotFound) || !errors.Is(err, cause) {
+			t.Errorf("Expected ErrNotFound wrapping cause, Got: %v", err)
+		}
+
+		_, err = voxgigstruct.CompilePath("a..b")
+		if !errors.Is(err, voxgigstruct.ErrSpec) || errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected only ErrSpec, Got: %v", err)
+		}
+
+		_, err = voxgigstruct.Decode(strings.NewReader(""), "application/x-none")
+		if !errors.Is(err, voxgigstruct.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, Got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+	"strings"
+)
+
+// Syntax features of a spec, as reported by SpecFeatures.
+const (
+	FeatureFullRef       = "full-ref"       // A string that is a single reference: "`a.b`".
+	FeaturePartialRef    = "partial-ref"    // References within text: "id-`a`".
+	FeatureRelativePath  = "relative-path"  // A path relative to the current node: "`.a`".
+	FeatureTopPath       = "top-path"       // A path from the top of the data: "`$TOP.a`".
+	FeatureKeyTransform  = "key-transform"  // A transform as a map key: { "`$MERGE`": ... }.
+	FeatureListTransform = "list-transform" // A transform at the start of a list: ['`$EACH`', ...].
+	FeatureTransformArg  = "transform-arg"  // A transform with an inline argument: "`$REGEX:^a`".
+	FeatureOrdering      = "ordering"       // Ordering digits of a transform: "`$MERGE1`".
+	FeatureEscape        = "escape"         // An escaped backtick or dollar sign: "`$BT`".
+	FeatureInvalid       = "invalid"        // An unmatched backtick.
+)
+
+// The features used by a spec (see SpecFeatures).
+type Features struct {
+	Transforms   map[string]int // Uses of each transform or validator, by name (such as `$EACH`).
+	Custom       []string       // Sorted names of the transforms that are not built-in.
+	Syntax       map[string]int // Uses of each syntax feature (see the Feature constants).
+	PathDepths   map[int]int    // Number of path references of each depth (number of parts).
+	MaxPathDepth int            // Maximum depth of a path reference.
+	MaxDepth     int            // Maximum nesting depth of the spec (the root is 0).
+	Nodes        int            // Number of values in the spec, including the root.
+}
+
+// Report the transforms, path depths and syntax features used by a
+// transform or validation spec, such as to find the specs that use a
+// transform before it is changed. Transforms that are neither
+// built-in transforms nor validators (see TransformNames and
+// ValidatorNames) are listed as Custom, as they must be provided when
+// the spec is used. The spec is not modified.
+func SpecFeatures(spec any) *Features {
+	f := &Features{
+		Transforms: map[string]int{},
+		Custom:     []string{},
+		Syntax:     map[string]int{},
+		PathDepths: map[int]int{},
+	}
+
+	_specFeatures(spec, 0, f)
+
+	// The validation keys are also built-in.
+	known := map[string]bool{"$OPEN": true, S_DCLOSED: true}
+	for _, name := range append(TransformNames(), ValidatorNames()...) {
+		known[name] = true
+	}
+	for name := range f.Transforms {
+		if !known[name] {
+			f.Custom = append(f.Custom, name)
+		}
+	}
+	sort.Strings(f.Custom)
+
+	return f
+}
+
+func _specFeatures(val any, depth int, f *Features) {
+	f.Nodes++
+	if f.MaxDepth < depth {
+		f.MaxDepth = depth
+	}
+
+	switch v := val.(type) {
+	case string:
+		_stringFeatures(v, f)
+
+	case map[string]any:
+		for _, key := range KeysOf(v) {
+			if _stringFeatures(key, f) {
+				f.Syntax[FeatureKeyTransform]++
+			}
+			_specFeatures(v[key], depth+1, f)
+		}
+
+	default:
+		if IsList(val) {
+			for iI, elem := range _listify(val) {
+				if s, ok := elem.(string); ok && 0 == iI && _isFullTransform(s) {
+					f.Syntax[FeatureListTransform]++
+				}
+				_specFeatures(elem, depth+1, f)
+			}
+		}
+	}
+}
+
+// Count the features of a string, returning true if it is a single
+// transform reference.
+func _stringFeatures(s string, f *Features) bool {
+	if !strings.Contains(s, S_BT) {
+		return false
+	}
+
+	segments, err := ParseInjection(s)
+	if nil != err {
+		f.Syntax[FeatureInvalid]++
+	}
+
+	transform := false
+	for _, seg := range segments {
+		if SegmentRef != seg.Kind {
+			continue
+		}
+
+		raw := s[seg.Start:seg.End]
+		if strings.Contains(raw, "$BT") || strings.Contains(raw, "$DS") {
+			f.Syntax[FeatureEscape]++
+		}
+
+		if seg.Full {
+			f.Syntax[FeatureFullRef]++
+		} else {
+			f.Syntax[FeaturePartialRef]++
+		}
+
+		if seg.Transform && S_DTOP != seg.Text && S_DINDEX != seg.Text {
+			f.Transforms[seg.Text]++
+			transform = seg.Full
+			if S_MT != seg.Arg {
+				f.Syntax[FeatureTransformArg]++
+			} else if seg.Full && len(seg.Text)+2 < len(raw) {
+				f.Syntax[FeatureOrdering]++
+			}
+			continue
+		}
+
+		_pathFeatures(seg.Text, f)
+	}
+
+	return transform
+}
+
+func _pathFeatures(path string, f *Features) {
+	if strings.HasPrefix(path, S_DT) {
+		f.Syntax[FeatureRelativePath]++
+	}
+
+	depth := 0
+	for pI, part := range _splitPath(path) {
+		if 0 == pI && S_DTOP == part {
+			f.Syntax[FeatureTopPath]++
+		} else if S_MT != part {
+			depth++
+		}
+	}
+
+	f.PathDepths[depth]++
+	if f.MaxPathDepth < depth {
+		f.MaxPathDepth = depth
+	}
+}
+
+func _isFullTransform(s string) bool {
+	segments, _ := ParseInjection(s)
+	return 1 == len(segments) && segments[0].Full && segments[0].Transform
+}
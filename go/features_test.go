@@ -0,0 +1,88 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestSpecFeatures(t *testing.T) {
+
+	t.Run("features-transform", func(t *testing.T) {
+		spec := map[string]any{
+			"id":    "`order.id`",
+			"label": "order-`order.id`-`.code`",
+			"when":  "`$WHEN`",
+			"total": []any{"`$SUM`", "order.lines", "price"},
+			"lines": []any{"`$EACH`", "order.lines", map[string]any{
+				"sku": "`.sku`",
+				"top": "`$TOP.order.customer.name`",
+			}},
+			"`$MERGE1`": []any{"`base`"},
+			"tick":      "`$BT`x",
+			"hash":      "`$HASH`",
+			"bad":       "a`b",
+		}
+
+		f := voxgigstruct.SpecFeatures(spec)
+
+		transforms := map[string]int{"$WHEN": 1, "$SUM": 1, "$EACH": 1, "$MERGE": 1, "$BT": 1, "$HASH": 1}
+		if !reflect.DeepEqual(transforms, f.Transforms) {
+			t.Errorf("Expected: %v, Got: %v", transforms, f.Transforms)
+		}
+		if !reflect.DeepEqual([]string{"$HASH"}, f.Custom) {
+			t.Errorf("Unexpected custom transforms: %v", f.Custom)
+		}
+
+		syntax := map[string]int{
+			voxgigstruct.FeatureFullRef:       9,
+			voxgigstruct.FeaturePartialRef:    3,
+			voxgigstruct.FeatureRelativePath:  2,
+			voxgigstruct.FeatureTopPath:       1,
+			voxgigstruct.FeatureKeyTransform:  1,
+			voxgigstruct.FeatureListTransform: 2,
+			voxgigstruct.FeatureOrdering:      1,
+			voxgigstruct.FeatureEscape:        1,
+			voxgigstruct.FeatureInvalid:       1,
+		}
+		if !reflect.DeepEqual(syntax, f.Syntax) {
+			t.Errorf("Expected: %v, Got: %v", syntax, f.Syntax)
+		}
+
+		depths := map[int]int{1: 3, 2: 2, 3: 1}
+		if !reflect.DeepEqual(depths, f.PathDepths) || 3 != f.MaxPathDepth {
+			t.Errorf("Unexpected path depths: %v %v", f.PathDepths, f.MaxPathDepth)
+		}
+		if 3 != f.MaxDepth || 19 != f.Nodes {
+			t.Errorf("Unexpected size: %v %v", f.MaxDepth, f.Nodes)
+		}
+	})
+
+	t.Run("features-validate", func(t *testing.T) {
+		f := voxgigstruct.SpecFeatures(map[string]any{
+			"name":    "`$STRING`",
+			"code":    "`$REGEX:^[A-Z]+$`",
+			"tags":    []any{"`$CHILD`", "`$STRING`"},
+			"`$OPEN`": true,
+		})
+
+		transforms := map[string]int{"$STRING": 2, "$REGEX": 1, "$CHILD": 1, "$OPEN": 1}
+		if !reflect.DeepEqual(transforms, f.Transforms) {
+			t.Errorf("Expected: %v, Got: %v", transforms, f.Transforms)
+		}
+		if 1 != f.Syntax[voxgigstruct.FeatureTransformArg] || 0 != len(f.PathDepths) {
+			t.Errorf("Unexpected features: %v %v", f.Syntax, f.PathDepths)
+		}
+		if 0 != len(f.Custom) {
+			t.Errorf("Unexpected custom transforms: %v", f.Custom)
+		}
+	})
+
+	t.Run("features-plain", func(t *testing.T) {
+		f := voxgigstruct.SpecFeatures(map[string]any{"a": 1, "b": []any{"x"}})
+		if 0 != len(f.Transforms) || 0 != len(f.Syntax) || 4 != f.Nodes || 2 != f.MaxDepth {
+			t.Errorf("Unexpected features: %+v", f)
+		}
+	})
+}
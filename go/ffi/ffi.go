@@ -0,0 +1,230 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+// A flat interface to the struct utilities, with JSON requests and
+// responses as byte slices, for use across a foreign function
+// interface (cgo exports, WebAssembly, or an embedding runtime). Only
+// JSON data crosses the boundary, so custom transforms, validators and
+// modify functions are not available.
+//
+// A cgo shared library (built with -buildmode=c-shared) needs only a
+// single export:
+//
+//	//export vstruct_call
+//	func vstruct_call(op *C.char, req *C.char, reqlen C.int) *C.char {
+//		out := ffi.Call(C.GoString(op), C.GoBytes(unsafe.Pointer(req), reqlen))
+//		return C.CString(string(out)) // Freed by the caller.
+//	}
+//
+// Each operation takes a JSON object of named arguments, and returns
+// a Response as a JSON object. Tagged leaf values (see
+// voxgigstruct.ToJSON) are restored in requests, and tagged in
+// responses.
+package ffi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	vs "github.com/voxgig/struct"
+)
+
+// Version of the interface, incremented when an operation or the
+// Response changes incompatibly.
+const Version = 1
+
+// The result of an operation. Err is set if the operation failed, in
+// which case Out is null. For validate, Errs lists the messages of
+// all the problems found.
+type Response struct {
+	Out  any      `json:"out"`
+	Err  string   `json:"err,omitempty"`
+	Errs []string `json:"errs,omitempty"`
+}
+
+// Default limits of the transform operation, as the data and specs
+// of requests may be untrusted (see voxgigstruct.Limits).
+var DefaultLimits = vs.Limits{MaxDepth: 256, MaxNodes: 1 << 20, MaxBytes: 64 << 20}
+
+// Options for CallWith.
+type Options struct {
+	// Limits of the transform operation (default: DefaultLimits). The
+	// limits argument of a request can lower, but not raise, these.
+	Limits *vs.Limits
+
+	// Memory budget of the transform operation, if any.
+	Quota *vs.Quota
+}
+
+type operation func(args map[string]any, opts *Options, res *Response) error
+
+// Operations, and their arguments:
+//
+//	version    {}                             Version of the interface.
+//	getpath    {path, store}                  As GetPath.
+//	setpath    {path, store, val}             As SetPath, returning the store.
+//	merge      {val}                          As Merge, of a list of nodes.
+//	diff       {a, b}                         As Diff.
+//	inject     {val, store}                   As Inject.
+//	transform  {data, spec, limits?}          As Transform, within limits.
+//	validate   {data, spec}                   As ValidateAll.
+//	stringify  {val, max?}                    As Stringify, or StringifyBudget.
+//
+// The limits of transform are an object of any of maxDepth, maxNodes
+// and maxBytes (see voxgigstruct.Limits). A transform that exceeds a
+// limit fails with no output.
+var operations = map[string]operation{
+	"version": func(args map[string]any, opts *Options, res *Response) error {
+		res.Out = Version
+		return nil
+	},
+
+	"getpath": func(args map[string]any, opts *Options, res *Response) error {
+		res.Out = vs.GetPath(args["path"], args["store"])
+		return nil
+	},
+
+	"setpath": func(args map[string]any, opts *Options, res *Response) error {
+		store := args["store"]
+		if !vs.IsNode(store) {
+			return fmt.Errorf("setpath requires a store node, not: %s", vs.Typify(store))
+		}
+		vs.SetPath(args["path"], store, args["val"])
+		res.Out = store
+		return nil
+	},
+
+	"merge": func(args map[string]any, opts *Options, res *Response) error {
+		res.Out = vs.Merge(args["val"])
+		return nil
+	},
+
+	"diff": func(args map[string]any, opts *Options, res *Response) error {
+		res.Out = vs.Diff(args["a"], args["b"])
+		return nil
+	},
+
+	"inject": func(args map[string]any, opts *Options, res *Response) error {
+		res.Out = vs.Inject(args["val"], args["store"])
+		return nil
+	},
+
+	"transform": func(args map[string]any, opts *Options, res *Response) error {
+		tres := vs.TransformCollect(args["data"], args["spec"], &vs.TransformOptions{
+			Limits: limits(args["limits"], opts.Limits),
+			Quota:  opts.Quota,
+		})
+		if 0 < len(tres.Errs) {
+			return fmt.Errorf("%v", tres.Errs[0])
+		}
+		res.Out = tres.Out
+		return nil
+	},
+
+	"validate": func(args map[string]any, opts *Options, res *Response) error {
+		out, verrs := vs.ValidateAll(args["data"], args["spec"])
+		if nil != verrs {
+			res.Errs = verrs.Strings()
+			return verrs
+		}
+		res.Out = out
+		return nil
+	},
+
+	"stringify": func(args map[string]any, opts *Options, res *Response) error {
+		if max, ok := vs.ToNum(args["max"]); ok {
+			res.Out = vs.StringifyBudget(args["val"], int(max))
+		} else {
+			res.Out = vs.Stringify(args["val"])
+		}
+		return nil
+	},
+}
+
+// The names of the operations, sorted.
+func Ops() []string {
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Call an operation with a JSON request (an object of arguments, or
+// empty for no arguments), returning a JSON Response. Call never
+// panics, and always returns a Response.
+func Call(op string, req []byte) []byte {
+	return CallWith(op, req, nil)
+}
+
+// Call an operation, as for Call, with options (nil for the defaults).
+func CallWith(op string, req []byte, opts *Options) []byte {
+	if nil == opts {
+		opts = &Options{}
+	}
+	res := &Response{}
+
+	if err := call(op, req, opts, res); nil != err {
+		res.Out = nil
+		res.Err = err.Error()
+	}
+
+	// Tag leaf values of the output.
+	tagged, err := vs.ToJSON(res.Out)
+	if nil != err {
+		res = &Response{Err: "Invalid output: " + err.Error()}
+		tagged = []byte("null")
+	}
+	res.Out = json.RawMessage(tagged)
+
+	out, _ := json.Marshal(res)
+	return out
+}
+
+func call(op string, req []byte, opts *Options, res *Response) (err error) {
+	defer func() {
+		if r := recover(); nil != r {
+			err = fmt.Errorf("%s failed: %v", op, r)
+		}
+	}()
+
+	fn, ok := operations[op]
+	if !ok {
+		return fmt.Errorf("Unknown operation: %s", op)
+	}
+
+	args := map[string]any{}
+	if 0 < len(req) {
+		val, err := vs.FromJSON(req)
+		if nil != err {
+			return err
+		}
+		m, ok := val.(map[string]any)
+		if !ok {
+			return fmt.Errorf("The request must be a JSON object, not: %s", vs.Typify(val))
+		}
+		args = m
+	}
+
+	return fn(args, opts, res)
+}
+
+// The limits of a request, within the maximum limits.
+func limits(arg any, max *vs.Limits) *vs.Limits {
+	out := DefaultLimits
+	if nil != max {
+		out = *max
+	}
+
+	lower := func(limit *int, key string) {
+		if n, ok := vs.ToNum(vs.GetProp(arg, key)); ok && 0 < n && (0 == *limit || int(n) < *limit) {
+			*limit = int(n)
+		}
+	}
+	lower(&out.MaxDepth, "maxDepth")
+	lower(&out.MaxNodes, "maxNodes")
+	lower(&out.MaxBytes, "maxBytes")
+
+	return &out
+}
@@ -0,0 +1,106 @@
+package ffi_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	vs "github.com/voxgig/struct"
+	"github.com/voxgig/struct/ffi"
+)
+
+func call(t *testing.T, op string, req string) map[string]any {
+	out := ffi.Call(op, []byte(req))
+	res := map[string]any{}
+	if err := json.Unmarshal(out, &res); nil != err {
+		t.Fatalf("Invalid response: %s", out)
+	}
+	return res
+}
+
+func TestFFI(t *testing.T) {
+
+	t.Run("ffi-ops", func(t *testing.T) {
+		cases := []struct {
+			op  string
+			req string
+			out any
+		}{
+			{"version", ``, float64(ffi.Version)},
+			{"getpath", `{"path":"a.b","store":{"a":{"b":1}}}`, float64(1)},
+			{"setpath", `{"path":"a.c","store":{"a":{"b":1}},"val":2}`,
+				map[string]any{"a": map[string]any{"b": float64(1), "c": float64(2)}}},
+			{"merge", `{"val":[{"a":1},{"b":2}]}`, map[string]any{"a": float64(1), "b": float64(2)}},
+			{"diff", `{"a":{"x":1,"y":2},"b":{"x":1,"y":3}}`,
+				[]any{map[string]any{"op": "replace", "path": []any{"y"}, "old": float64(2), "value": float64(3)}}},
+			{"inject", `{"val":{"x":"` + "`a`" + `"},"store":{"a":3}}`, map[string]any{"x": float64(3)}},
+			{"transform", `{"data":{"a":[1,2]},"spec":{"n":["` + "`$EACH`" + `","a","` + "`$COPY`" + `"]}}`,
+				map[string]any{"n": []any{float64(1), float64(2)}}},
+			{"validate", `{"data":{"a":"x"},"spec":{"a":"` + "`$STRING`" + `"}}`, map[string]any{"a": "x"}},
+			{"stringify", `{"val":{"a":[1,2,3,4,5,6,7,8,9,10,11,12]},"max":22}`, "{a:[12 items elided]}"},
+		}
+		for _, c := range cases {
+			res := call(t, c.op, c.req)
+			if !reflect.DeepEqual(c.out, res["out"]) || nil != res["err"] {
+				t.Errorf("%s: Expected: %v, Got: %v", c.op, c.out, res)
+			}
+		}
+	})
+
+	t.Run("ffi-errors", func(t *testing.T) {
+		res := call(t, "validate", `{"data":{"a":1,"b":true},"spec":{"a":"`+"`$STRING`"+`","b":"`+"`$STRING`"+`"}}`)
+		errs, _ := res["errs"].([]any)
+		if nil != res["out"] || 2 != len(errs) || !strings.HasPrefix(res["err"].(string), "Invalid data:") {
+			t.Errorf("Unexpected response: %v", res)
+		}
+
+		for _, c := range [][2]string{
+			{"nope", `{}`},
+			{"getpath", `{`},
+			{"getpath", `[1]`},
+			{"setpath", `{"path":"a","store":1}`},
+		} {
+			res := call(t, c[0], c[1])
+			if msg, ok := res["err"].(string); !ok || "" == msg || nil != res["out"] {
+				t.Errorf("%s %s: Expected an error, Got: %v", c[0], c[1], res)
+			}
+		}
+	})
+
+	t.Run("ffi-limits", func(t *testing.T) {
+		spec := func(n int) string {
+			return `{"data":{},"spec":["` + "`$RANGE`" + `",1,` + strconv.Itoa(n) + `]`
+		}
+
+		for _, req := range []string{
+			spec(2000000000) + `}`,
+			spec(1000) + `,"limits":{"maxNodes":100}}`,
+		} {
+			res := call(t, "transform", req)
+			if msg, _ := res["err"].(string); !strings.Contains(msg, "limit") || nil != res["out"] {
+				t.Errorf("%s: Expected a limit error, Got: %v", req, res)
+			}
+		}
+
+		res := call(t, "transform", spec(3)+`,"limits":{"maxNodes":100}}`)
+		if !reflect.DeepEqual([]any{float64(1), float64(2), float64(3)}, res["out"]) {
+			t.Errorf("Unexpected response: %v", res)
+		}
+
+		// Requests cannot raise the limits of the options.
+		out := ffi.CallWith("transform", []byte(spec(20)+`,"limits":{"maxNodes":1000}}`),
+			&ffi.Options{Limits: &vs.Limits{MaxNodes: 10}})
+		if !strings.Contains(string(out), "limit") {
+			t.Errorf("Expected a limit error, Got: %s", out)
+		}
+	})
+
+	t.Run("ffi-names", func(t *testing.T) {
+		ops := ffi.Ops()
+		if 9 != len(ops) || "diff" != ops[0] {
+			t.Errorf("Unexpected ops: %v", ops)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strings"
+)
+
+// A `$FILTER` predicate function, called with each element of the
+// source, and its key (the index, for a list).
+type Predicate func(val any, key string) bool
+
+// Keep the elements of a list, or the entries of a map, that match a
+// predicate. Format: ['`$FILTER`', source-path, predicate]. The
+// source path is resolved as for `$EACH`, and the output has the type
+// of the source. The predicate is one of:
+//
+//   - A map of field paths to values: each field of the element must
+//     equal the value (the values are injected).
+//   - A field path: the field must be present (not nil).
+//   - The name of a Predicate in the store (such as one given in
+//     TransformOptions.Extra), as a reference: "`$ACTIVE`".
+//   - A Predicate.
+//
+// Unlike `$EACH`, the kept elements are copied as they are.
+var Transform_FILTER Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$FILTER", 2)
+	if !ok {
+		return nil
+	}
+
+	match := _filterPredicate(args[1], store, current, state)
+	if nil == match {
+		state.Warn("filter-predicate", "Invalid predicate for $FILTER: "+Stringify(args[1]))
+		return _replaceTransform(state, nil)
+	}
+
+	src := _itemsSource(args[0], store, current, state)
+	if nil == src {
+		return _replaceTransform(state, nil)
+	}
+	if !IsNode(src) {
+		state.Warn("filter-source", "Source for $FILTER is not a list or map: "+Typify(src))
+		return _replaceTransform(state, nil)
+	}
+
+	var out any = []any{}
+	if IsMap(src) {
+		out = map[string]any{}
+	}
+
+	for _, item := range Items(src) {
+		key := StrKey(item[0])
+		if !match(item[1], key) {
+			continue
+		}
+		if IsMap(out) {
+			SetProp(out, key, Clone(item[1]))
+		} else {
+			out = append(out.([]any), Clone(item[1]))
+		}
+	}
+
+	_reserveOutput(store, NumKeys(out), nil, state.Path)
+	return _replaceTransform(state, out)
+}
+
+// The match function of a `$FILTER` predicate, or nil if invalid.
+func _filterPredicate(pred any, store any, current any, state *Injection) Predicate {
+	switch p := pred.(type) {
+	case Predicate:
+		return p
+	case func(val any, key string) bool:
+		return p
+
+	case string:
+		if strings.HasPrefix(p, S_BT+S_DS) && strings.HasSuffix(p, S_BT) && 2 < len(p) {
+			switch fn := GetProp(store, p[1:len(p)-1]).(type) {
+			case Predicate:
+				return fn
+			case func(val any, key string) bool:
+				return fn
+			}
+			return nil
+		}
+		path, ok := _pathParts(p)
+		if !ok || S_MT == p {
+			return nil
+		}
+		return func(val any, _ string) bool {
+			return nil != GetPath(path, val)
+		}
+
+	case map[string]any:
+		fields := _injectArg(p, store, current, state)
+		return func(val any, _ string) bool {
+			for _, field := range KeysOf(fields) {
+				if !_filterEqual(GetPath(field, val), GetProp(fields, field)) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	return nil
+}
+
+// Equality of a field and a predicate value, where numbers of any
+// type are equal if their values are equal.
+func _filterEqual(a any, b any) bool {
+	if an, ok := ToNum(a); ok {
+		if bn, ok := ToNum(b); ok {
+			return an == bn
+		}
+	}
+	return _equal(a, b)
+}
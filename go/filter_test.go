@@ -0,0 +1,107 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestFilter(t *testing.T) {
+
+	data := map[string]any{
+		"want": "open",
+		"items": []any{
+			map[string]any{"id": 1, "status": "open", "meta": map[string]any{"vip": true}},
+			map[string]any{"id": 2, "status": "closed"},
+			map[string]any{"id": 3, "status": "open", "owner": "ann"},
+		},
+		"users": map[string]any{
+			"a": map[string]any{"age": 30},
+			"b": map[string]any{"age": 40.0},
+			"c": map[string]any{"age": 50},
+		},
+	}
+
+	t.Run("filter-equal", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"open":  []any{"`$FILTER`", "items", map[string]any{"status": "open"}},
+			"vip":   []any{"`$FILTER`", "items", map[string]any{"meta.vip": true}},
+			"want":  []any{"`$FILTER`", "items", map[string]any{"status": "`want`", "id": 3}},
+			"forty": []any{"`$FILTER`", "users", map[string]any{"age": 40}},
+		})
+		expected := map[string]any{
+			"open": []any{
+				map[string]any{"id": 1, "status": "open", "meta": map[string]any{"vip": true}},
+				map[string]any{"id": 3, "status": "open", "owner": "ann"},
+			},
+			"vip": []any{
+				map[string]any{"id": 1, "status": "open", "meta": map[string]any{"vip": true}},
+			},
+			"want": []any{
+				map[string]any{"id": 3, "status": "open", "owner": "ann"},
+			},
+			"forty": map[string]any{
+				"b": map[string]any{"age": 40.0},
+			},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("filter-present", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"owned": []any{"`$FILTER`", "items", "owner"},
+		})
+		expected := map[string]any{"owned": []any{
+			map[string]any{"id": 3, "status": "open", "owner": "ann"},
+		}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("filter-function", func(t *testing.T) {
+		var older voxgigstruct.Predicate = func(val any, key string) bool {
+			age, _ := voxgigstruct.ToNum(voxgigstruct.GetProp(val, "age"))
+			return 35 < age && "c" != key
+		}
+
+		out := voxgigstruct.TransformWith(data, map[string]any{
+			"older": []any{"`$FILTER`", "users", "`$OLDER`"},
+			"even": []any{"`$FILTER`", "items", func(val any, key string) bool {
+				return "1" == key
+			}},
+		}, &voxgigstruct.TransformOptions{Extra: map[string]any{"$OLDER": older}})
+		expected := map[string]any{
+			"older": map[string]any{"b": map[string]any{"age": 40.0}},
+			"even":  []any{map[string]any{"id": 2, "status": "closed"}},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("filter-invalid", func(t *testing.T) {
+		result := voxgigstruct.TransformCollect(data, map[string]any{
+			"a": []any{"`$FILTER`", "items", "`$MISSING`"},
+			"b": []any{"`$FILTER`", "want", "id"},
+			"c": []any{"`$FILTER`", "missing", "id"},
+			"d": "x",
+		}, nil)
+
+		expected := map[string]any{"d": "x"}
+		if !reflect.DeepEqual(expected, result.Out) {
+			t.Errorf("Expected: %v, Got: %v", expected, result.Out)
+		}
+
+		codes := []string{}
+		for _, w := range result.Warnings {
+			codes = append(codes, w.Code)
+		}
+		if !reflect.DeepEqual([]string{"filter-predicate", "filter-source"}, codes) {
+			t.Errorf("Unexpected warnings: %v", result.Warnings)
+		}
+	})
+}
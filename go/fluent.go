@@ -0,0 +1,108 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"math"
+)
+
+// A value for nil-safe chained reads, without parsing a path:
+//
+//	name := V(node).Get("a").Index(2).Get("name").Str("default")
+//
+// Each step of a missing value, or of a value of the wrong type, is
+// also missing, and the typed extractors at the end of the chain
+// return their default for a missing value, or a value of another
+// type. A Value is immutable, and does not copy the node.
+type Value struct {
+	val any
+}
+
+// Start a chain of reads of a value.
+func V(val any) Value {
+	return Value{val: val}
+}
+
+// The property of a map (or list) with a key (as for GetProp).
+func (v Value) Get(key string) Value {
+	if !IsNode(v.val) {
+		return Value{}
+	}
+	return Value{val: GetProp(v.val, key)}
+}
+
+// The element of a list at an index. Negative indexes count back from
+// the end of the list.
+func (v Value) Index(i int) Value {
+	if !IsList(v.val) {
+		return Value{}
+	}
+	list := _listify(v.val)
+	if iI, ok := ToIndex(i, len(list)); ok {
+		return Value{val: list[iI]}
+	}
+	return Value{}
+}
+
+// True if the value is not missing (nil).
+func (v Value) Exists() bool {
+	return nil != v.val
+}
+
+// The value itself, or nil if missing.
+func (v Value) Val() any {
+	return v.val
+}
+
+// The value as a string, or dflt if it is not a string.
+func (v Value) Str(dflt string) string {
+	if s, ok := v.val.(string); ok {
+		return s
+	}
+	return dflt
+}
+
+// The value as a float64, or dflt if it is not a number (see ToNum).
+func (v Value) Num(dflt float64) float64 {
+	if n, ok := ToNum(v.val); ok {
+		return n
+	}
+	return dflt
+}
+
+// The value as an int, or dflt if it is not a number with an integer
+// value that fits in an int.
+func (v Value) Int(dflt int) int {
+	if i, ok := v.val.(int); ok {
+		return i
+	}
+	n, ok := ToNum(v.val)
+	if !ok || n != math.Trunc(n) || n < math.MinInt || math.MaxInt <= n {
+		return dflt
+	}
+	return int(n)
+}
+
+// The value as a bool, or dflt if it is not a bool.
+func (v Value) Bool(dflt bool) bool {
+	if b, ok := v.val.(bool); ok {
+		return b
+	}
+	return dflt
+}
+
+// The value as a list (not a copy), or nil if it is not a list.
+func (v Value) List() []any {
+	if !IsList(v.val) {
+		return nil
+	}
+	return _listify(v.val)
+}
+
+// The value as a map (not a copy), or nil if it is not a map.
+func (v Value) Map() map[string]any {
+	if m, ok := v.val.(map[string]any); ok {
+		return m
+	}
+	return nil
+}
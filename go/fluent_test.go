@@ -0,0 +1,70 @@
+package voxgigstruct_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestFluent(t *testing.T) {
+
+	node := map[string]any{
+		"a": []any{
+			map[string]any{"name": "x"},
+			nil,
+			map[string]any{"name": "z", "n": 3.0, "big": 1e300, "ok": true},
+		},
+		"j": json.Number("7"),
+		"m": map[string]any{"k": 1},
+	}
+
+	t.Run("fluent-chain", func(t *testing.T) {
+		V := voxgigstruct.V
+
+		cases := []struct {
+			got      any
+			expected any
+		}{
+			{V(node).Get("a").Index(2).Get("name").Str("d"), "z"},
+			{V(node).Get("a").Index(-1).Get("name").Str("d"), "z"},
+			{V(node).Get("a").Index(0).Get("name").Str("d"), "x"},
+			{V(node).Get("a").Index(1).Get("name").Str("d"), "d"},
+			{V(node).Get("a").Index(3).Get("name").Str("d"), "d"},
+			{V(node).Get("b").Index(0).Get("name").Str("d"), "d"},
+			{V(node).Get("m").Index(0).Exists(), false},
+			{V(node).Get("a").Get("0").Get("name").Str("d"), "x"},
+			{V(nil).Get("a").Index(0).Str("d"), "d"},
+			{V("s").Get("a").Exists(), false},
+
+			{V(node).Get("a").Index(2).Get("n").Num(0), 3.0},
+			{V(node).Get("a").Index(2).Get("n").Int(0), 3},
+			{V(node).Get("a").Index(2).Get("big").Int(-1), -1},
+			{V(node).Get("a").Index(2).Get("name").Num(-1), -1.0},
+			{V(node).Get("j").Int(0), 7},
+			{V(node).Get("m").Get("k").Int(0), 1},
+
+			{V(node).Get("a").Index(2).Get("ok").Bool(false), true},
+			{V(node).Get("a").Index(2).Get("n").Bool(false), false},
+
+			{V(node).Get("a").Exists(), true},
+			{V(node).Get("m").Get("k").Val(), 1},
+			{len(V(node).Get("a").List()), 3},
+			{V(node).Get("m").List() == nil, true},
+			{V(node).Get("a").Map() == nil, true},
+		}
+
+		for cI, c := range cases {
+			if !reflect.DeepEqual(c.expected, c.got) {
+				t.Errorf("%d: Expected: %v, Got: %v", cI, c.expected, c.got)
+			}
+		}
+
+		// Nodes are not copied.
+		V(node).Get("m").Map()["k"] = 2
+		if 2 != node["m"].(map[string]any)["k"] {
+			t.Errorf("Expected the node to be shared: %v", node["m"])
+		}
+	})
+}
@@ -0,0 +1,86 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strings"
+)
+
+// Options for matching map keys.
+type KeyOptions struct {
+	// Match map keys case insensitively (Unicode case folding). An
+	// exact match is preferred; otherwise the least matching key (in
+	// byte order) is used, so that `ID` is preferred over `Id` and `id`.
+	CaseInsensitive bool
+}
+
+// Get a property of a node, as for GetProp, with key options.
+func GetPropWith(val any, key any, opts *KeyOptions, alts ...any) any {
+	var alt any
+	if 0 < len(alts) {
+		alt = alts[0]
+	}
+
+	out, _ := _getPropWith(val, key, opts)
+	if nil == out {
+		return alt
+	}
+	return out
+}
+
+// The key is present in a node, as for HasKey, with key options.
+func HasKeyWith(val any, key any, opts *KeyOptions) bool {
+	_, found := _getPropWith(val, key, opts)
+	return found
+}
+
+// Get a value at a key path, as for GetPath, with key options. Paths
+// are resolved from the store, without wildcards.
+func GetPathWith(path any, store any, opts *KeyOptions) any {
+	if nil == opts || !opts.CaseInsensitive {
+		return GetPath(path, store)
+	}
+
+	parts, ok := _pathParts(path)
+	if !ok {
+		return nil
+	}
+
+	val := store
+	for _, part := range parts {
+		if nil == val {
+			break
+		}
+		if S_MT != part {
+			val, _ = _getPropWith(val, part, opts)
+		}
+	}
+	return val
+}
+
+func _getPropWith(val any, key any, opts *KeyOptions) (any, bool) {
+	if nil == opts || !opts.CaseInsensitive || !IsMap(val) {
+		return _getProp(val, key)
+	}
+
+	m := val.(map[string]any)
+	ks := StrKey(key)
+
+	if out, found := m[ks]; found {
+		return out, true
+	}
+
+	match := S_MT
+	found := false
+	for k := range m {
+		if strings.EqualFold(k, ks) && (!found || k < match) {
+			match = k
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, false
+	}
+	return m[match], true
+}
@@ -0,0 +1,53 @@
+package voxgigstruct_test
+
+import (
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestFold(t *testing.T) {
+
+	fold := &voxgigstruct.KeyOptions{CaseInsensitive: true}
+
+	t.Run("fold-getprop", func(t *testing.T) {
+		val := map[string]any{"Id": 1, "ID": 2, "name": "a", "Nil": nil}
+
+		if 1 != voxgigstruct.GetPropWith(val, "Id", fold) {
+			t.Errorf("Exact match not preferred")
+		}
+		if 2 != voxgigstruct.GetPropWith(val, "id", fold) {
+			t.Errorf("Least match not preferred")
+		}
+		if "a" != voxgigstruct.GetPropWith(val, "NAME", fold) {
+			t.Errorf("No match")
+		}
+		if nil != voxgigstruct.GetPropWith(val, "NAME", nil) {
+			t.Errorf("Unexpected match")
+		}
+		if "x" != voxgigstruct.GetPropWith(val, "q", fold, "x") {
+			t.Errorf("No alt")
+		}
+		if !voxgigstruct.HasKeyWith(val, "nil", fold) || voxgigstruct.HasKeyWith(val, "nil", nil) {
+			t.Errorf("Unexpected HasKeyWith")
+		}
+		if "b" != voxgigstruct.GetPropWith([]any{"a", "b"}, 1, fold) {
+			t.Errorf("No list match")
+		}
+	})
+
+	t.Run("fold-getpath", func(t *testing.T) {
+		store := map[string]any{
+			"User": map[string]any{"Addresses": []any{map[string]any{"CITY": "x"}}},
+		}
+		if "x" != voxgigstruct.GetPathWith("user.addresses.0.city", store, fold) {
+			t.Errorf("No match")
+		}
+		if nil != voxgigstruct.GetPathWith("user.addresses.0.city", store, nil) {
+			t.Errorf("Unexpected match")
+		}
+		if "x" != voxgigstruct.GetPathWith("User.Addresses.0.CITY", store, nil) {
+			t.Errorf("No exact match")
+		}
+	})
+}
@@ -0,0 +1,120 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"math"
+)
+
+// Store key of the index of a `$REPEAT` template.
+const S_DINDEX = "$INDEX"
+
+// Maximum number of elements generated by `$RANGE` or `$REPEAT`,
+// whatever the output limits of the transform.
+const MaxGenerate = 1 << 20
+
+// Generate a list of integers, from start to end inclusive, counting
+// by step (default 1, and may be negative). The arguments are
+// injected. Format: ['`$RANGE`', start, end, step?].
+var Transform_RANGE Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$RANGE", 2)
+	if !ok {
+		return nil
+	}
+	args = _injectArgs(args, store, current, state)
+
+	start, sok := _intArg(args, 0, 0)
+	end, eok := _intArg(args, 1, 0)
+	step, tok := _intArg(args, 2, 1)
+	if !sok || !eok || !tok || 0 == step {
+		state.Warn("range-args", "Invalid arguments for $RANGE: "+Stringify(args))
+		return _replaceTransform(state, nil)
+	}
+
+	count := 0
+	if (0 < step && start <= end) || (step < 0 && end <= start) {
+		count = (end-start)/step + 1
+	}
+	_reserveOutput(store, count, 0, state.Path)
+	if MaxGenerate < count {
+		state.Warn("range-count", "Too many elements for $RANGE: "+Stringify(count))
+		return _replaceTransform(state, nil)
+	}
+
+	out := make([]any, count)
+	for i := range out {
+		out[i] = start + i*step
+	}
+
+	return _replaceTransform(state, out)
+}
+
+// Repeat a template a number of times. Each copy is injected with its
+// index available as `$INDEX`. The count is injected. Format:
+// ['`$REPEAT`', count, template].
+var Transform_REPEAT Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$REPEAT", 1)
+	if !ok {
+		return nil
+	}
+
+	// The template is injected for each copy.
+	template := GetProp(args, 1)
+
+	count, cok := _intArg([]any{_injectArg(args[0], store, current, state)}, 0, 0)
+	if !cok || count < 0 {
+		state.Warn("repeat-args", "Invalid count for $REPEAT: "+Stringify(args[0]))
+		return _replaceTransform(state, nil)
+	}
+
+	_reserveOutput(store, count, template, state.Path)
+	if MaxGenerate < count {
+		state.Warn("repeat-count", "Too many copies for $REPEAT: "+Stringify(count))
+		return _replaceTransform(state, nil)
+	}
+
+	tstore := map[string]any{}
+	for k, v := range _storeMap(store) {
+		tstore[k] = v
+	}
+	tcur := map[string]any{S_DTOP: current}
+
+	out := make([]any, count)
+	for i := range out {
+		tstore[S_DINDEX] = i
+		tval := Clone(template)
+		nested := _nestedState(tval, tstore, state)
+		nested.Prefix = append(nested.Prefix, StrKey(i))
+		out[i] = InjectDescend(tval, tstore, state.Modify, tcur, nested)
+	}
+
+	return _replaceTransform(state, out)
+}
+
+// An optional integer argument.
+func _intArg(args []any, index int, dflt int) (int, bool) {
+	n, ok := _numArg(args, index, float64(dflt))
+	if !ok || n != math.Trunc(n) || math.MaxInt32 < math.Abs(n) {
+		return 0, false
+	}
+	return int(n), true
+}
+
+func _storeMap(store any) map[string]any {
+	if m, ok := store.(map[string]any); ok {
+		return m
+	}
+	return map[string]any{}
+}
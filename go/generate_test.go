@@ -0,0 +1,91 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestGenerate(t *testing.T) {
+
+	t.Run("generate-range", func(t *testing.T) {
+		out := voxgigstruct.Transform(map[string]any{"n": 3}, map[string]any{
+			"months": []any{"`$RANGE`", 1, 12},
+			"down":   []any{"`$RANGE`", "`n`", 0, -1},
+			"odd":    []any{"`$RANGE`", 1, 8, 2},
+			"none":   []any{"`$RANGE`", 5, 1},
+		})
+		expected := map[string]any{
+			"months": []any{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+			"down":   []any{3, 2, 1, 0},
+			"odd":    []any{1, 3, 5, 7},
+			"none":   []any{},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("generate-repeat", func(t *testing.T) {
+		out := voxgigstruct.Transform(map[string]any{"n": 2, "name": "slot"}, map[string]any{
+			"slots": []any{"`$REPEAT`", "`n`", map[string]any{
+				"i":     "`$INDEX`",
+				"label": "`name`-`$INDEX`",
+			}},
+			"zeros": []any{"`$REPEAT`", 3, 0},
+			"empty": []any{"`$REPEAT`", 0, "x"},
+		})
+		expected := map[string]any{
+			"slots": []any{
+				map[string]any{"i": 0, "label": "slot-0"},
+				map[string]any{"i": 1, "label": "slot-1"},
+			},
+			"zeros": []any{0, 0, 0},
+			"empty": []any{},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("generate-nested", func(t *testing.T) {
+		// A calendar: each month has its own list of weeks.
+		out := voxgigstruct.Transform(map[string]any{}, []any{"`$REPEAT`", 2, map[string]any{
+			"month": "`$INDEX`",
+			"weeks": []any{"`$RANGE`", 1, 2},
+		}})
+		expected := []any{
+			map[string]any{"month": 0, "weeks": []any{1, 2}},
+			map[string]any{"month": 1, "weeks": []any{1, 2}},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("generate-invalid", func(t *testing.T) {
+		warns := voxgigstruct.ListRefCreate[any]()
+		out := voxgigstruct.TransformWith(map[string]any{}, map[string]any{
+			"a": []any{"`$RANGE`", 1, 5, 0},
+			"b": []any{"`$REPEAT`", -1, "x"},
+			"c": 1,
+			"d": []any{"`$RANGE`", 0, 2000000000},
+			"e": []any{"`$REPEAT`", voxgigstruct.MaxGenerate + 1, "x"},
+		}, &voxgigstruct.TransformOptions{Extra: map[string]any{"$WARNS": warns}})
+		if !reflect.DeepEqual(map[string]any{"c": 1}, out) || 4 != len(warns.List) {
+			t.Errorf("Expected: %v, Got: %v %v", map[string]any{"c": 1}, out, warns.List)
+		}
+
+		// Output limits are checked before generating.
+		errs := voxgigstruct.ListRefCreate[any]()
+		out = voxgigstruct.TransformWith(map[string]any{}, []any{"`$RANGE`", 1, 1000000},
+			&voxgigstruct.TransformOptions{
+				Extra:    map[string]any{"$ERRS": errs},
+				MaxNodes: 100,
+			})
+		if nil != out || 1 != len(errs.List) {
+			t.Errorf("Expected: %v, Got: %v %v", nil, out, errs.List)
+		}
+	})
+}
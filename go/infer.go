@@ -0,0 +1,126 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+)
+
+// Infer a validation shape from one or more example documents, as a
+// starting point for a shape that is then edited by hand. Scalars
+// give type validators (`$STRING`, `$NUMBER`, `$BOOLEAN`), lists give
+// a `$CHILD` shape of all their elements, and maps give a closed map
+// shape of all their keys. Where the examples disagree:
+//
+//   - A key that is missing (or nil) in some examples is optional,
+//     and the map shape lists the other keys as `$REQUIRED`.
+//   - Values of different types give a `$ONE` of the types.
+//
+// Values that are always nil, and non-JSON values, accept any value.
+// The examples are not modified.
+func InferShape(examples ...any) any {
+	vals := []any{}
+	for _, ex := range examples {
+		if nil != ex {
+			vals = append(vals, ex)
+		}
+	}
+	return _inferShape(vals)
+}
+
+// The shape of a set of (non-nil) values at the same position.
+func _inferShape(vals []any) any {
+	if 0 == len(vals) {
+		return "`$ANY`"
+	}
+
+	// Group by type, in order of first appearance.
+	types := []string{}
+	groups := map[string][]any{}
+	for _, val := range vals {
+		t := Typify(val)
+		if S_object == t && !IsMap(val) {
+			t = "any"
+		}
+		if _, has := groups[t]; !has {
+			types = append(types, t)
+		}
+		groups[t] = append(groups[t], val)
+	}
+
+	shapes := []any{}
+	for _, t := range types {
+		shape := _inferTypeShape(t, groups[t])
+		if "`$ANY`" == shape {
+			return shape
+		}
+		shapes = append(shapes, shape)
+	}
+
+	if 1 == len(shapes) {
+		return shapes[0]
+	}
+	return append([]any{"`$ONE`"}, shapes...)
+}
+
+func _inferTypeShape(t string, vals []any) any {
+	switch t {
+	case S_string:
+		return "`$STRING`"
+	case S_number:
+		return "`$NUMBER`"
+	case S_boolean:
+		return "`$BOOLEAN`"
+	case S_function:
+		return "`$FUNCTION`"
+
+	case S_array:
+		elems := []any{}
+		for _, val := range vals {
+			for _, elem := range _listify(val) {
+				if nil != elem {
+					elems = append(elems, elem)
+				}
+			}
+		}
+		if 0 == len(elems) {
+			return "`$ARRAY`"
+		}
+		return []any{"`$CHILD`", _inferShape(elems)}
+
+	case S_object:
+		keys := []string{}
+		children := map[string][]any{}
+		for _, val := range vals {
+			for _, kv := range Items(val) {
+				key := kv[0].(string)
+				if _, has := children[key]; !has {
+					keys = append(keys, key)
+					children[key] = []any{}
+				}
+				if nil != kv[1] {
+					children[key] = append(children[key], kv[1])
+				}
+			}
+		}
+		if 0 == len(keys) {
+			return "`$OBJECT`"
+		}
+
+		shape := map[string]any{}
+		required := []any{}
+		sort.Strings(keys)
+		for _, key := range keys {
+			shape[key] = _inferShape(children[key])
+			if len(vals) == len(children[key]) {
+				required = append(required, key)
+			}
+		}
+		if len(required) < len(keys) {
+			shape[S_BT+S_DREQUIRED+S_BT] = required
+		}
+		return shape
+	}
+
+	return "`$ANY`"
+}
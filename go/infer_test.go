@@ -0,0 +1,84 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestInferShape(t *testing.T) {
+
+	t.Run("infer-basic", func(t *testing.T) {
+		shape := voxgigstruct.InferShape(map[string]any{
+			"id":   "a1",
+			"n":    1,
+			"ok":   true,
+			"tags": []any{"x", "y"},
+			"none": []any{},
+			"sub":  map[string]any{"a": 1.5},
+			"nil":  nil,
+		})
+		expected := map[string]any{
+			"id":          "`$STRING`",
+			"n":           "`$NUMBER`",
+			"ok":          "`$BOOLEAN`",
+			"tags":        []any{"`$CHILD`", "`$STRING`"},
+			"none":        "`$ARRAY`",
+			"sub":         map[string]any{"a": "`$NUMBER`"},
+			"nil":         "`$ANY`",
+			"`$REQUIRED`": []any{"id", "n", "none", "ok", "sub", "tags"},
+		}
+		if !reflect.DeepEqual(expected, shape) {
+			t.Errorf("Expected: %v, Got: %v", expected, shape)
+		}
+	})
+
+	t.Run("infer-disagree", func(t *testing.T) {
+		examples := []any{
+			map[string]any{"id": "a1", "v": 1, "items": []any{map[string]any{"k": "x"}}},
+			map[string]any{"id": "b2", "v": "one", "note": "n",
+				"items": []any{map[string]any{"k": "y", "extra": true}}},
+		}
+		shape := voxgigstruct.InferShape(examples...)
+		expected := map[string]any{
+			"`$REQUIRED`": []any{"id", "items", "v"},
+			"id":          "`$STRING`",
+			"v":           []any{"`$ONE`", "`$NUMBER`", "`$STRING`"},
+			"note":        "`$STRING`",
+			"items": []any{"`$CHILD`", map[string]any{
+				"`$REQUIRED`": []any{"k"},
+				"k":           "`$STRING`",
+				"extra":       "`$BOOLEAN`",
+			}},
+		}
+		if !reflect.DeepEqual(expected, shape) {
+			t.Errorf("Expected: %v, Got: %v", expected, shape)
+		}
+
+		// The examples are valid.
+		for _, ex := range examples {
+			if _, err := voxgigstruct.Validate(ex, voxgigstruct.Clone(shape)); nil != err {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}
+
+		_, err := voxgigstruct.Validate(map[string]any{"id": "c3", "v": true, "items": []any{}},
+			voxgigstruct.Clone(shape))
+		if nil == err {
+			t.Errorf("Expected an error for v")
+		}
+	})
+
+	t.Run("infer-scalar", func(t *testing.T) {
+		if out := voxgigstruct.InferShape(); "`$ANY`" != out {
+			t.Errorf("Expected: %v, Got: %v", "`$ANY`", out)
+		}
+		if out := voxgigstruct.InferShape(1, 2.5); "`$NUMBER`" != out {
+			t.Errorf("Expected: %v, Got: %v", "`$NUMBER`", out)
+		}
+		if out := voxgigstruct.InferShape(map[string]any{}); "`$OBJECT`" != out {
+			t.Errorf("Expected: %v, Got: %v", "`$OBJECT`", out)
+		}
+	})
+}
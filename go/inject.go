@@ -0,0 +1,117 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A transform name, with optional ordering digits.
+var reTransformRef = regexp.MustCompile(`^\$[A-Z]+[0-9]*$`)
+
+// Kinds of injection string segment.
+type SegmentKind string
+
+const (
+	SegmentLiteral SegmentKind = "literal" // Text that is not injected.
+	SegmentRef     SegmentKind = "ref"     // A path or transform reference.
+)
+
+// A segment of an injection string (see ParseInjection).
+type Segment struct {
+	Kind SegmentKind
+
+	// Literal text, or the reference, with the `$BT` and `$DS` escapes
+	// resolved, and any ordering digits of a transform name removed.
+	Text string
+
+	Start int // Byte offset of the segment in the string.
+	End   int // Byte offset after the segment (including backticks).
+
+	Transform bool // The reference is a transform name, such as `$EACH`.
+
+	// The argument of a transform that has one, such as the pattern of
+	// `$REGEX:^[a-z]+$` (whose Text is `$REGEX`).
+	Arg string
+
+	// The reference is the whole string, so the injected value
+	// replaces the string (rather than being inserted as text).
+	Full bool
+}
+
+// Split a spec string into literal and reference segments, as the
+// injection engine does. An unmatched backtick is literal text (as
+// for the engine), but is also reported as an ErrSpec error, with
+// the segments.
+func ParseInjection(s string) ([]Segment, error) {
+	segments := []Segment{}
+
+	if m := reInjectFull.FindStringSubmatchIndex(s); nil != m {
+		// The ordering digits of a transform are not captured.
+		ref := s[m[2]:m[3]]
+		seg := Segment{
+			Kind:      SegmentRef,
+			Text:      _unescapeRef(ref),
+			Start:     0,
+			End:       len(s),
+			Transform: _isTransformRef(ref),
+			Full:      true,
+		}
+		if am := reInjectArg.FindStringSubmatch(seg.Text); nil != am {
+			seg.Text, seg.Arg, seg.Transform = am[1], am[2], true
+		}
+		segments = append(segments, seg)
+		return segments, nil
+	}
+
+	last := 0
+	literal := func(end int) {
+		if last < end {
+			segments = append(segments, Segment{
+				Kind:  SegmentLiteral,
+				Text:  s[last:end],
+				Start: last,
+				End:   end,
+			})
+		}
+	}
+
+	for _, m := range reInjectPart.FindAllStringSubmatchIndex(s, -1) {
+		literal(m[0])
+		ref := s[m[2]:m[3]]
+		segments = append(segments, Segment{
+			Kind:      SegmentRef,
+			Text:      _unescapeRef(ref),
+			Start:     m[0],
+			End:       m[1],
+			Transform: _isTransformRef(ref),
+		})
+		last = m[1]
+	}
+	literal(len(s))
+
+	for _, seg := range segments {
+		if SegmentLiteral == seg.Kind {
+			if bI := strings.Index(seg.Text, S_BT); -1 < bI {
+				return segments, NewPathError(ErrSpec, nil, nil,
+					"Unmatched backtick at offset %d: %s", seg.Start+bI, s)
+			}
+		}
+	}
+
+	return segments, nil
+}
+
+// Resolve the escapes of a reference, as the engine does.
+func _unescapeRef(ref string) string {
+	if 3 < len(ref) {
+		ref = strings.ReplaceAll(ref, "$BT", S_BT)
+		ref = strings.ReplaceAll(ref, "$DS", S_DS)
+	}
+	return ref
+}
+
+func _isTransformRef(ref string) bool {
+	return reTransformRef.MatchString(ref)
+}
@@ -0,0 +1,88 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestInjectParse(t *testing.T) {
+
+	show := func(segs []voxgigstruct.Segment) []string {
+		out := []string{}
+		for _, seg := range segs {
+			out = append(out, fmt.Sprintf("%s:%q:%d-%d:%v:%v",
+				seg.Kind, seg.Text, seg.Start, seg.End, seg.Transform, seg.Full))
+		}
+		return out
+	}
+
+	t.Run("inject-parse", func(t *testing.T) {
+		checks := []struct {
+			in  string
+			out []string
+		}{
+			{"`a.b`", []string{`ref:"a.b":0-5:false:true`}},
+			{"`$EACH1`", []string{`ref:"$EACH":0-8:true:true`}},
+			{"`$COPY`", []string{`ref:"$COPY":0-7:true:true`}},
+			{"x`a`y`$BT`", []string{
+				`literal:"x":0-1:false:false`,
+				`ref:"a":1-4:false:false`,
+				`literal:"y":4-5:false:false`,
+				`ref:"$BT":5-10:true:false`,
+			}},
+			{"`a$BTb`", []string{"ref:\"a`b\":0-7:false:true"}},
+			{"`a$DSb` and more", []string{
+				`ref:"a$b":0-7:false:false`,
+				`literal:" and more":7-16:false:false`,
+			}},
+			{"plain", []string{`literal:"plain":0-5:false:false`}},
+			{"", []string{}},
+		}
+
+		for _, check := range checks {
+			segs, err := voxgigstruct.ParseInjection(check.in)
+			if nil != err || !reflect.DeepEqual(check.out, show(segs)) {
+				t.Errorf("%q Expected: %q, Got: %q %v", check.in, check.out, show(segs), err)
+			}
+		}
+	})
+
+	t.Run("inject-parse-error", func(t *testing.T) {
+		segs, err := voxgigstruct.ParseInjection("a`b`c`d")
+		expected := []string{
+			`literal:"a":0-1:false:false`,
+			`ref:"b":1-4:false:false`,
+			"literal:\"c`d\":4-7:false:false",
+		}
+		if !errors.Is(err, voxgigstruct.ErrSpec) || !reflect.DeepEqual(expected, show(segs)) ||
+			"Unmatched backtick at offset 5: a`b`c`d" != err.Error() {
+			t.Errorf("Unexpected: %q %v", show(segs), err)
+		}
+	})
+
+	t.Run("inject-parse-parity", func(t *testing.T) {
+		// The segments give the same result as the engine.
+		store := map[string]any{"a": map[string]any{"b": 1}, "c": "C", "a`b": 2}
+		for _, spec := range []string{"`a.b`", "x`c`y`a.b`", "`a$BTb`", "no refs"} {
+			segs, _ := voxgigstruct.ParseInjection(spec)
+			var out any = ""
+			for _, seg := range segs {
+				if voxgigstruct.SegmentLiteral == seg.Kind {
+					out = out.(string) + seg.Text
+				} else if seg.Full {
+					out = voxgigstruct.GetPath(seg.Text, store)
+				} else {
+					out = out.(string) + voxgigstruct.Stringify(voxgigstruct.GetPath(seg.Text, store))
+				}
+			}
+			expected := voxgigstruct.Inject(map[string]any{"x": spec}, store).(map[string]any)["x"]
+			if !reflect.DeepEqual(expected, out) {
+				t.Errorf("%q Expected: %v, Got: %v", spec, expected, out)
+			}
+		}
+	})
+}
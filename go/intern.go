@@ -0,0 +1,52 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Deduplicates strings, so that equal string values and map keys
+// share memory. Use a single Interner for a set of documents with the
+// same vocabulary. An Interner is not safe for concurrent use.
+type Interner struct {
+	strs map[string]string
+}
+
+// Create an Interner.
+func NewInterner() *Interner {
+	return &Interner{strs: map[string]string{}}
+}
+
+// Intern the string values and map keys of a data structure, in place
+// (as for Walk, lists may be replaced, so use the returned value).
+func (in *Interner) Intern(val any) any {
+	return Walk(val, func(key *string, val any, parent any, path []string) any {
+		switch v := val.(type) {
+		case string:
+			return in.str(v)
+		case map[string]any:
+			// Assigning an existing key also replaces the stored key.
+			for k, cv := range v {
+				v[in.str(k)] = cv
+			}
+		}
+		return val
+	})
+}
+
+// Number of distinct strings.
+func (in *Interner) Len() int {
+	return len(in.strs)
+}
+
+func (in *Interner) str(s string) string {
+	if is, ok := in.strs[s]; ok {
+		return is
+	}
+	in.strs[s] = s
+	return s
+}
+
+// Intern the string values and map keys of a data structure, in place,
+// to reduce the memory used by large documents with many repeated
+// strings (see Interner).
+func Intern(val any) any {
+	return NewInterner().Intern(val)
+}
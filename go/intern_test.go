@@ -0,0 +1,46 @@
+package voxgigstruct_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/voxgig/struct"
+)
+
+func TestIntern(t *testing.T) {
+
+	t.Run("intern-basic", func(t *testing.T) {
+		var doc any
+		src := `{"items":[{"status":"active","kind":"a"},{"status":"active","kind":"b"}],"status":"active"}`
+		if err := json.Unmarshal([]byte(src), &doc); nil != err {
+			t.Fatal(err)
+		}
+		expected := voxgigstruct.Clone(doc)
+
+		in := voxgigstruct.NewInterner()
+		doc = in.Intern(doc)
+
+		if !reflect.DeepEqual(expected, doc) {
+			t.Errorf("Unexpected: %v", doc)
+		}
+
+		items := voxgigstruct.GetProp(doc, "items").([]any)
+		s0 := items[0].(map[string]any)["status"].(string)
+		s1 := items[1].(map[string]any)["status"].(string)
+		s2 := voxgigstruct.GetProp(doc, "status").(string)
+		if unsafe.StringData(s0) != unsafe.StringData(s1) || unsafe.StringData(s0) != unsafe.StringData(s2) {
+			t.Errorf("Values not interned")
+		}
+
+		// Keys and values share the vocabulary.
+		if 6 != in.Len() {
+			t.Errorf("Unexpected length: %d", in.Len())
+		}
+
+		if "x" != voxgigstruct.Intern("x") {
+			t.Errorf("Unexpected scalar")
+		}
+	})
+}
@@ -0,0 +1,158 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Build a map from a list of key/value pairs, in the Items format.
+// Keys are converted with StrKey, and a later pair replaces an
+// earlier pair with the same key, so FromItems(Items(m)) is a copy of
+// the map m.
+func FromItems(items [][2]any) map[string]any {
+	out := make(map[string]any, len(items))
+	for _, item := range items {
+		out[StrKey(item[0])] = item[1]
+	}
+	return out
+}
+
+// Build a multimap (a map of keys to lists of values) from a list of
+// key/value pairs, in the Items format. The values of each key are in
+// pair order, so no pairs are lost.
+func Multimap(items [][2]any) map[string]any {
+	out := map[string]any{}
+	for _, item := range items {
+		key := StrKey(item[0])
+		list, _ := out[key].([]any)
+		out[key] = append(list, item[1])
+	}
+	return out
+}
+
+// List the key/value pairs of a multimap, in the Items format. Keys
+// are in sorted order, and the values of each key in list order. A
+// value that is not a list is a single value, so MultimapItems of a
+// plain map is the same as Items.
+func MultimapItems(val any) [][2]any {
+	out := [][2]any{}
+	for _, kv := range Items(val) {
+		if IsList(kv[1]) {
+			for _, v := range _listify(kv[1]) {
+				out = append(out, [2]any{kv[0], v})
+			}
+		} else {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// Convert pairs in the Items format to a list of two element lists,
+// which is the form used by JSON data and the `$PAIRS` transform.
+func PairList(items [][2]any) []any {
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[i] = []any{item[0], item[1]}
+	}
+	return out
+}
+
+// Convert a list of two element lists to pairs in the Items format.
+// Also returns false if any element is not a pair; such elements are
+// skipped.
+func ListPairs(val any) ([][2]any, bool) {
+	out := [][2]any{}
+	ok := true
+	for _, pair := range _listify(val) {
+		if !IsList(pair) || 2 != NumKeys(pair) {
+			ok = false
+			continue
+		}
+		p := _listify(pair)
+		out = append(out, [2]any{p[0], p[1]})
+	}
+	return out, ok
+}
+
+// Convert a map (or list) to a list of [key, value] pairs, with keys
+// in sorted order. Format: ['`$PAIRS`', source-path, multi?]. The
+// source path is resolved as for `$EACH`. If multi is true, the
+// source is a multimap, and each value of a list gives a pair.
+var Transform_PAIRS Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$PAIRS", 1)
+	if !ok {
+		return nil
+	}
+
+	src := _itemsSource(args[0], store, current, state)
+	if nil == src {
+		return _replaceTransform(state, nil)
+	}
+	if !IsNode(src) {
+		state.Warn("pairs-source", "Source for $PAIRS is not a list or map: "+Typify(src))
+		return _replaceTransform(state, nil)
+	}
+
+	items := Items(src)
+	if true == GetProp(args, 1) {
+		items = MultimapItems(src)
+	}
+
+	_reserveOutput(store, len(items), nil, state.Path)
+	return _replaceTransform(state, Clone(PairList(items)))
+}
+
+// Convert a list of [key, value] pairs to a map. Format:
+// ['`$FROMPAIRS`', source-path, multi?]. Later pairs replace earlier
+// pairs with the same key, unless multi is true, when the values of
+// each key are collected into a list. Elements that are not pairs
+// are skipped, with a warning.
+var Transform_FROMPAIRS Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$FROMPAIRS", 1)
+	if !ok {
+		return nil
+	}
+
+	src := _itemsSource(args[0], store, current, state)
+	if nil == src {
+		return _replaceTransform(state, nil)
+	}
+	if !IsList(src) {
+		state.Warn("pairs-source", "Source for $FROMPAIRS is not a list: "+Typify(src))
+		return _replaceTransform(state, nil)
+	}
+
+	items, valid := ListPairs(src)
+	if !valid {
+		state.Warn("pairs-item", "Source for $FROMPAIRS has elements that are not pairs.")
+	}
+
+	_reserveOutput(store, len(items), nil, state.Path)
+
+	var out map[string]any
+	if true == GetProp(args, 1) {
+		out = Multimap(items)
+	} else {
+		out = FromItems(items)
+	}
+	return _replaceTransform(state, Clone(out))
+}
+
+func _itemsSource(srcpath any, store any, current any, state *Injection) any {
+	srcstore := GetProp(store, state.Base, store)
+	src := GetPathState(srcpath, srcstore, current, nil)
+	if parts, ok := _pathParts(srcpath); ok {
+		_markUsed(store, parts)
+	}
+	return src
+}
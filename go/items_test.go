@@ -0,0 +1,112 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestItems(t *testing.T) {
+
+	t.Run("items-map", func(t *testing.T) {
+		m := map[string]any{"a": 1, "b": 2}
+		if out := voxgigstruct.FromItems(voxgigstruct.Items(m)); !reflect.DeepEqual(m, out) {
+			t.Errorf("Expected: %v, Got: %v", m, out)
+		}
+
+		items := [][2]any{{"a", 1}, {1, "x"}, {"a", 2}}
+		expected := map[string]any{"a": 2, "1": "x"}
+		if out := voxgigstruct.FromItems(items); !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("items-multimap", func(t *testing.T) {
+		items := [][2]any{{"a", 1}, {"b", 2}, {"a", 3}}
+		mm := voxgigstruct.Multimap(items)
+		expected := map[string]any{"a": []any{1, 3}, "b": []any{2}}
+		if !reflect.DeepEqual(expected, mm) {
+			t.Errorf("Expected: %v, Got: %v", expected, mm)
+		}
+
+		flat := voxgigstruct.MultimapItems(mm)
+		sorted := [][2]any{{"a", 1}, {"a", 3}, {"b", 2}}
+		if !reflect.DeepEqual(sorted, flat) {
+			t.Errorf("Expected: %v, Got: %v", sorted, flat)
+		}
+
+		plain := map[string]any{"x": 1}
+		if out := voxgigstruct.MultimapItems(plain); !reflect.DeepEqual(voxgigstruct.Items(plain), out) {
+			t.Errorf("Expected: %v, Got: %v", voxgigstruct.Items(plain), out)
+		}
+	})
+
+	t.Run("items-pairlist", func(t *testing.T) {
+		items := [][2]any{{"a", 1}, {"b", nil}}
+		list := voxgigstruct.PairList(items)
+		expected := []any{[]any{"a", 1}, []any{"b", nil}}
+		if !reflect.DeepEqual(expected, list) {
+			t.Errorf("Expected: %v, Got: %v", expected, list)
+		}
+
+		back, ok := voxgigstruct.ListPairs(append(list, "bad", []any{1}))
+		if ok || !reflect.DeepEqual(items, back) {
+			t.Errorf("Expected: %v false, Got: %v %v", items, back, ok)
+		}
+	})
+
+	t.Run("items-transform", func(t *testing.T) {
+		data := map[string]any{
+			"headers": []any{
+				[]any{"accept", "json"},
+				[]any{"x-tag", "a"},
+				[]any{"x-tag", "b"},
+			},
+			"env": map[string]any{"b": 2, "a": 1},
+		}
+
+		out := voxgigstruct.Transform(data, map[string]any{
+			"single": []any{"`$FROMPAIRS`", "headers"},
+			"multi":  []any{"`$FROMPAIRS`", "headers", true},
+			"pairs":  []any{"`$PAIRS`", "env"},
+		})
+		expected := map[string]any{
+			"single": map[string]any{"accept": "json", "x-tag": "b"},
+			"multi": map[string]any{
+				"accept": []any{"json"},
+				"x-tag":  []any{"a", "b"},
+			},
+			"pairs": []any{[]any{"a", 1}, []any{"b", 2}},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		// Round trip of a multimap.
+		out = voxgigstruct.Transform(map[string]any{
+			"mm": map[string]any{"x": []any{1, 2}},
+		}, map[string]any{"p": []any{"`$PAIRS`", "mm", true}})
+		expected = map[string]any{"p": []any{[]any{"x", 1}, []any{"x", 2}}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("items-transform-warn", func(t *testing.T) {
+		res := voxgigstruct.TransformCollect(map[string]any{
+			"s": "str",
+			"l": []any{[]any{"a", 1}, "bad"},
+		}, map[string]any{
+			"p": []any{"`$PAIRS`", "s"},
+			"f": []any{"`$FROMPAIRS`", "l"},
+		}, nil)
+		if 2 != len(res.Warnings) || "pairs-item" != res.Warnings[0].Code {
+			t.Errorf("Expected: 2 warnings, Got: %v", res.Warnings)
+		}
+		expected := map[string]any{"f": map[string]any{"a": 1}}
+		if !reflect.DeepEqual(expected, res.Out) {
+			t.Errorf("Expected: %v, Got: %v", expected, res.Out)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How a Go type that is a leaf (a single value, even if it is a slice
+// or struct) is handled. Leaf values are never walked into, and are
+// not lists (see IsList).
+type LeafType struct {
+	// Text of the value for Stringify. The default is the JSON encoding
+	// of the value.
+	Format func(val any) string
+
+	// Copy of the value for Clone. The default is the value itself.
+	Clone func(val any) any
+
+	// Type tag name, for lossless JSON (see ToJSON). Leaves without a
+	// tag are encoded as plain JSON values.
+	Tag string
+
+	// JSON value of the leaf for its tag. The default is the decoded
+	// JSON encoding of the value.
+	Value func(val any) any
+
+	// Restore a value from the JSON value of its tag. The default
+	// decodes the JSON value into the leaf type.
+	Untag func(value any) (any, error)
+}
+
+var (
+	leafTypes      atomic.Pointer[map[reflect.Type]LeafType]
+	leafTypesMutex sync.Mutex
+	leafFormats    atomic.Bool // Any leaf type has a Format.
+)
+
+func init() {
+	leafTypes.Store(&map[reflect.Type]LeafType{
+		// The JSON encodings of these types are used by Stringify.
+		reflect.TypeOf(time.Time{}): {Tag: "time"},
+		reflect.TypeOf(json.Number("")): {
+			// As text, to keep the precision.
+			Tag:   "number",
+			Value: func(val any) any { return string(val.(json.Number)) },
+			Untag: func(value any) (any, error) {
+				if s, ok := value.(string); ok {
+					return json.Number(s), nil
+				}
+				return nil, NewPathError(ErrType, nil, nil, "Expected string")
+			},
+		},
+		reflect.TypeOf([]byte{}): {
+			Clone: func(val any) any {
+				return append([]byte{}, val.([]byte)...)
+			},
+			Tag: "bytes",
+		},
+	})
+}
+
+// Register a Go type as a leaf type, by example value. The built in
+// leaf types are time.Time, json.Number and []byte. Registration is
+// global, and should be done before use (for example, in init).
+func RegisterLeafType(example any, leaf LeafType) {
+	leafTypesMutex.Lock()
+	defer leafTypesMutex.Unlock()
+
+	types := map[reflect.Type]LeafType{}
+	for t, l := range *leafTypes.Load() {
+		types[t] = l
+	}
+	types[reflect.TypeOf(example)] = leaf
+	leafTypes.Store(&types)
+
+	if nil != leaf.Format {
+		leafFormats.Store(true)
+	}
+}
+
+// Value is of a registered leaf type.
+func IsLeaf(val any) bool {
+	_, ok := _leafType(val)
+	return ok
+}
+
+func _leafType(val any) (LeafType, bool) {
+	if nil == val {
+		return LeafType{}, false
+	}
+	leaf, ok := (*leafTypes.Load())[reflect.TypeOf(val)]
+	return leaf, ok
+}
+
+// Clone a value that is not a JSON-like node: leaf types as they
+// define (or as type tags, if enabled), and other values by
+// reflection, if enabled.
+func _cloneOther(val any, flags map[string]bool) any {
+	if leaf, ok := _leafType(val); ok {
+		if flags["tag"] && S_MT != leaf.Tag {
+			return _tagLeaf(val, leaf)
+		}
+		if nil != leaf.Clone {
+			return leaf.Clone(val)
+		}
+		return val
+	}
+	if flags["reflect"] {
+		return _cloneReflect(val, flags)
+	}
+	return val
+}
+
+// Replace leaves that have a Format with their text, for Stringify.
+func _formatLeaves(val any) any {
+	if !leafFormats.Load() {
+		return val
+	}
+	out, _ := _formatLeavesIn(val, map[uintptr]bool{})
+	return out
+}
+
+// Nodes are copied only if they contain leaves to format. Nodes that
+// contain themselves are not formatted again.
+func _formatLeavesIn(val any, ancestors map[uintptr]bool) (any, bool) {
+	if id := _nodeID(val); 0 != id {
+		if ancestors[id] {
+			return val, false
+		}
+		ancestors[id] = true
+		defer delete(ancestors, id)
+	}
+
+	switch v := val.(type) {
+	case map[string]any:
+		var out map[string]any
+		for k, child := range v {
+			if fchild, changed := _formatLeavesIn(child, ancestors); changed {
+				if nil == out {
+					out = make(map[string]any, len(v))
+					for ok, ochild := range v {
+						out[ok] = ochild
+					}
+				}
+				out[k] = fchild
+			}
+		}
+		if nil == out {
+			return val, false
+		}
+		return out, true
+
+	case []any:
+		var out []any
+		for i, child := range v {
+			if fchild, changed := _formatLeavesIn(child, ancestors); changed {
+				if nil == out {
+					out = append([]any{}, v...)
+				}
+				out[i] = fchild
+			}
+		}
+		if nil == out {
+			return val, false
+		}
+		return out, true
+	}
+
+	if leaf, ok := _leafType(val); ok && nil != leaf.Format {
+		return leaf.Format(val), true
+	}
+	return val, false
+}
@@ -0,0 +1,102 @@
+package voxgigstruct_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voxgig/struct"
+)
+
+type leafMoney struct {
+	cents    int
+	currency string
+}
+
+func TestLeaf(t *testing.T) {
+
+	when := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("leaf-builtin", func(t *testing.T) {
+		raw := []byte("abc")
+		val := map[string]any{
+			"when": when,
+			"num":  json.Number("1.50"),
+			"raw":  raw,
+			"list": []any{when},
+		}
+
+		if voxgigstruct.IsList(raw) || voxgigstruct.IsNode(raw) || !voxgigstruct.IsLeaf(raw) ||
+			!voxgigstruct.IsLeaf(when) || voxgigstruct.IsLeaf([]any{}) || voxgigstruct.IsLeaf(nil) {
+			t.Errorf("Unexpected leaf types")
+		}
+
+		paths := []string{}
+		voxgigstruct.Walk(val, func(key *string, v any, parent any, path []string) any {
+			paths = append(paths, strings.Join(path, "."))
+			return v
+		})
+		expected := []string{"list.0", "list", "num", "raw", "when", ""}
+		if !reflect.DeepEqual(expected, paths) {
+			t.Errorf("Expected: %v, Got: %v", expected, paths)
+		}
+
+		out := voxgigstruct.Clone(val).(map[string]any)
+		if !reflect.DeepEqual(val, out) {
+			t.Errorf("Expected: %v, Got: %v", val, out)
+		}
+		out["raw"].([]byte)[0] = 'x'
+		if 'a' != raw[0] {
+			t.Errorf("Expected []byte copy")
+		}
+
+		str := voxgigstruct.Stringify(val)
+		if "{list:[2025-01-02T03:04:05Z],num:1.50,raw:YWJj,when:2025-01-02T03:04:05Z}" != str {
+			t.Errorf("Unexpected: %s", str)
+		}
+	})
+
+	t.Run("leaf-custom", func(t *testing.T) {
+		money := leafMoney{cents: 150, currency: "EUR"}
+
+		// Without registration, unexported fields are lost.
+		if "{a:{}}" != voxgigstruct.Stringify(map[string]any{"a": money}) {
+			t.Errorf("Unexpected: %s", voxgigstruct.Stringify(map[string]any{"a": money}))
+		}
+
+		voxgigstruct.RegisterLeafType(leafMoney{}, voxgigstruct.LeafType{
+			Format: func(val any) string {
+				m := val.(leafMoney)
+				return fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency)
+			},
+		})
+
+		if !voxgigstruct.IsLeaf(money) {
+			t.Errorf("Expected leaf")
+		}
+
+		val := map[string]any{"a": money, "b": []any{money, 1}}
+		if "{a:1.50 EUR,b:[1.50 EUR,1]}" != voxgigstruct.Stringify(val) {
+			t.Errorf("Unexpected: %s", voxgigstruct.Stringify(val))
+		}
+
+		// The value itself is not changed.
+		if money != val["a"] || money != val["b"].([]any)[0] {
+			t.Errorf("Unexpected: %v", val)
+		}
+
+		if money != voxgigstruct.Clone(val).(map[string]any)["a"] {
+			t.Errorf("Expected verbatim copy")
+		}
+
+		// Cycles are still handled.
+		cyclic := map[string]any{"m": money}
+		cyclic["self"] = cyclic
+		if "{m:1.50 EUR,self:$CYCLE}" != voxgigstruct.Stringify(cyclic) {
+			t.Errorf("Unexpected: %s", voxgigstruct.Stringify(cyclic))
+		}
+	})
+}
@@ -0,0 +1,133 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Limits on the size of values, to protect recursive operations from
+// untrusted input (zero means no limit). Values are checked before
+// they are processed, so that a value that is too deep cannot exhaust
+// the stack. A value that exceeds a limit is rejected with an
+// ErrLimitExceeded *PathError, with the path at which the limit was
+// reached.
+type Limits struct {
+	MaxDepth int // Maximum depth of nesting (the root is 0).
+	MaxNodes int // Maximum number of values, including the root.
+	MaxBytes int // Maximum (approximate) JSON size.
+}
+
+// Check that a value is within the limits. A nil Limits allows any
+// value. The value is not modified, and is walked with an explicit
+// stack, so a value that contains itself also exceeds any limit.
+func (l *Limits) Check(val any) error {
+	if nil == l || (0 == l.MaxDepth && 0 == l.MaxNodes && 0 == l.MaxBytes) {
+		return nil
+	}
+
+	type entry struct {
+		val  any
+		path []string
+	}
+
+	nodes, bytes := 0, 0
+	stack := []entry{{val: val, path: []string{}}}
+
+	for 0 < len(stack) {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		nodes++
+		bytes += _leafSize(top.val)
+
+		if 0 < l.MaxDepth && l.MaxDepth < len(top.path) {
+			return NewPathError(ErrLimitExceeded, top.path, nil,
+				"Maximum depth of %d exceeded", l.MaxDepth)
+		}
+		if 0 < l.MaxNodes && l.MaxNodes < nodes {
+			return NewPathError(ErrLimitExceeded, top.path, nil,
+				"Maximum node count of %d exceeded", l.MaxNodes)
+		}
+		if 0 < l.MaxBytes && l.MaxBytes < bytes {
+			return NewPathError(ErrLimitExceeded, top.path, nil,
+				"Maximum size of %d bytes exceeded", l.MaxBytes)
+		}
+
+		// Push children in reverse, so they are checked in order.
+		items := Items(top.val)
+		for iI := len(items) - 1; -1 < iI; iI-- {
+			ckey := StrKey(items[iI][0])
+			bytes += len(ckey) + 4
+			stack = append(stack, entry{val: items[iI][1], path: _childPath(top.path, ckey)})
+		}
+	}
+
+	return nil
+}
+
+// Clone a value, as for Clone, if it is within the limits.
+func CloneLimited(val any, limits *Limits) (any, error) {
+	if err := limits.Check(val); nil != err {
+		return nil, err
+	}
+	return Clone(val), nil
+}
+
+// Merge a list of values, as for MergeChecked, if each value, and the
+// merged output, are within the limits. If a value exceeds a limit,
+// nothing is merged. If the output exceeds a limit, the first value
+// of the list has already been modified.
+func MergeLimited(val any, limits *Limits, opts MergeOptions) (any, error) {
+	for vI, v := range _listify(val) {
+		if err := limits.Check(v); nil != err {
+			return nil, NewPathError(ErrLimitExceeded, nil, err, "Merge value %d is too large", vI)
+		}
+	}
+
+	out, err := MergeChecked(val, opts)
+	if nil != err {
+		return nil, err
+	}
+	if err := limits.Check(out); nil != err {
+		return nil, NewPathError(ErrLimitExceeded, nil, err, "Merged value is too large")
+	}
+	return out, nil
+}
+
+// Inject store values into a value, as for Inject, if the value and
+// the store are within the limits. The node and byte limits also
+// apply to the injected output, and injection stops when they are
+// exceeded.
+func InjectLimited(val any, store any, limits *Limits) (any, error) {
+	if err := limits.Check(val); nil != err {
+		return nil, NewPathError(ErrLimitExceeded, nil, err, "Injected value is too large")
+	}
+	if err := limits.Check(store); nil != err {
+		return nil, NewPathError(ErrLimitExceeded, nil, err, "Store is too large")
+	}
+
+	var modify Modify
+	if nil != limits && (0 < limits.MaxNodes || 0 < limits.MaxBytes) {
+		usage := &outputUsage{maxNodes: limits.MaxNodes, maxBytes: limits.MaxBytes}
+		modify = usage.modify(nil)
+	}
+
+	state := _injectState(val, store, modify)
+	return _injectLimited(val, store, modify, nil, state)
+}
+
+// Check the data and spec of a transform against the limits.
+func _checkTransformLimits(data any, spec any, opts *TransformOptions) error {
+	if err := opts.Limits.Check(data); nil != err {
+		return NewPathError(ErrLimitExceeded, nil, err, "Transform data is too large")
+	}
+	if err := opts.Limits.Check(spec); nil != err {
+		return NewPathError(ErrLimitExceeded, nil, err, "Transform spec is too large")
+	}
+	return nil
+}
+
+// The lower of two limits, where zero means no limit.
+func _minLimit(a int, b int) int {
+	if 0 == a || (0 < b && b < a) {
+		return b
+	}
+	return a
+}
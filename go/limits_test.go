@@ -0,0 +1,134 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestLimits(t *testing.T) {
+
+	// Nested 100 deep: {"a":{"a":...{"a":1}}}.
+	var deep any = 1
+	for i := 0; i < 100; i++ {
+		deep = map[string]any{"a": deep}
+	}
+
+	t.Run("limits-check", func(t *testing.T) {
+		var limits *voxgigstruct.Limits
+		if nil != limits.Check(deep) {
+			t.Errorf("Expected no limits")
+		}
+
+		err := (&voxgigstruct.Limits{MaxDepth: 2}).Check(deep)
+		var perr *voxgigstruct.PathError
+		if !errors.Is(err, voxgigstruct.ErrLimitExceeded) || !errors.As(err, &perr) ||
+			!reflect.DeepEqual([]string{"a", "a", "a"}, perr.Path) {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		err = (&voxgigstruct.Limits{MaxNodes: 3}).Check([]any{1, 2, 3})
+		if nil == err || "Maximum node count of 3 exceeded (at 2)" != err.Error() {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		err = (&voxgigstruct.Limits{MaxBytes: 10}).Check(map[string]any{"a": "0123456789"})
+		if !errors.Is(err, voxgigstruct.ErrLimit) {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		if nil != (&voxgigstruct.Limits{MaxDepth: 100, MaxNodes: 101}).Check(deep) {
+			t.Errorf("Expected within limits")
+		}
+
+		// A node that contains itself exceeds any limit.
+		cyclic := map[string]any{}
+		cyclic["self"] = cyclic
+		if !errors.Is((&voxgigstruct.Limits{MaxNodes: 1000}).Check(cyclic), voxgigstruct.ErrLimit) {
+			t.Errorf("Expected limit error")
+		}
+	})
+
+	t.Run("limits-clone-merge", func(t *testing.T) {
+		out, err := voxgigstruct.CloneLimited(deep, &voxgigstruct.Limits{MaxDepth: 10})
+		if nil != out || !errors.Is(err, voxgigstruct.ErrLimit) {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+
+		out, err = voxgigstruct.CloneLimited(map[string]any{"a": 1}, &voxgigstruct.Limits{MaxDepth: 10})
+		if nil != err || !reflect.DeepEqual(map[string]any{"a": 1}, out) {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+
+		limits := &voxgigstruct.Limits{MaxNodes: 2}
+		out, err = voxgigstruct.MergeLimited([]any{map[string]any{"a": 1}, map[string]any{"b": 2}},
+			limits, voxgigstruct.MergeOptions{})
+		if nil == err || "Merged value is too large: Maximum node count of 2 exceeded (at b)" != err.Error() {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+
+		first := map[string]any{"a": 1}
+		_, err = voxgigstruct.MergeLimited([]any{first, deep}, limits, voxgigstruct.MergeOptions{})
+		if !errors.Is(err, voxgigstruct.ErrLimit) || 1 != len(first) {
+			t.Errorf("Unexpected: %v %v", first, err)
+		}
+	})
+
+	t.Run("limits-inject", func(t *testing.T) {
+		store := map[string]any{"x": []any{1, 2, 3, 4, 5}}
+
+		out, err := voxgigstruct.InjectLimited(map[string]any{"a": "`x`", "b": "`x`"}, store,
+			&voxgigstruct.Limits{MaxNodes: 3})
+		if nil != out || !errors.Is(err, voxgigstruct.ErrLimit) {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+
+		out, err = voxgigstruct.InjectLimited(map[string]any{"a": "`x.1`"}, store,
+			&voxgigstruct.Limits{MaxDepth: 2, MaxNodes: 10})
+		if nil != err || !reflect.DeepEqual(map[string]any{"a": 2}, out) {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+
+		_, err = voxgigstruct.InjectLimited(map[string]any{"a": "`x.1`"}, store,
+			&voxgigstruct.Limits{MaxDepth: 1})
+		if nil == err || "Store is too large: Maximum depth of 1 exceeded (at x.0)" != err.Error() {
+			t.Errorf("Unexpected: %v", err)
+		}
+	})
+
+	t.Run("limits-transform", func(t *testing.T) {
+		errs := voxgigstruct.ListRefCreate[any]()
+		out := voxgigstruct.TransformWith(map[string]any{"d": deep}, map[string]any{"x": "`d.a`"},
+			&voxgigstruct.TransformOptions{
+				Extra:  map[string]any{"$ERRS": errs},
+				Limits: &voxgigstruct.Limits{MaxDepth: 50},
+			})
+		if nil != out || 1 != len(errs.List) || !errors.Is(errs.List[0].(error), voxgigstruct.ErrLimit) {
+			t.Errorf("Unexpected: %v %v", out, errs.List)
+		}
+
+		// Output limits also apply.
+		errs = voxgigstruct.ListRefCreate[any]()
+		items := []any{}
+		for i := 0; i < 4; i++ {
+			items = append(items, map[string]any{"v": i})
+		}
+		out = voxgigstruct.TransformWith(map[string]any{"items": items},
+			map[string]any{"out": []any{"`$EACH`", "items", map[string]any{"v": "`$COPY`", "w": "W"}}},
+			&voxgigstruct.TransformOptions{
+				Extra:  map[string]any{"$ERRS": errs},
+				Limits: &voxgigstruct.Limits{MaxNodes: 10},
+			})
+		if nil != out || 1 != len(errs.List) {
+			t.Errorf("Unexpected: %v %v", out, errs.List)
+		}
+
+		out = voxgigstruct.TransformWith(map[string]any{"x": 1}, map[string]any{"a": "`x`"},
+			&voxgigstruct.TransformOptions{Limits: &voxgigstruct.Limits{MaxDepth: 5, MaxNodes: 5}})
+		if !reflect.DeepEqual(map[string]any{"a": 1}, out) {
+			t.Errorf("Unexpected: %v", out)
+		}
+	})
+}
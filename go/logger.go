@@ -0,0 +1,39 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Structured logging of trace output, handler panics and limit
+// violations. The method set matches *slog.Logger, so a slog logger
+// can be used directly; the arguments are alternating keys and
+// values. Provide a logger with TransformOptions.Logger, or as the
+// `$LOG` property of an injection store (or the extra store of
+// ValidateCollect).
+type Logger interface {
+	Debug(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Get the logger of an injection store, or nil if there is none.
+func StoreLogger(store any) Logger {
+	log, _ := GetProp(store, S_DLOG).(Logger)
+	return log
+}
+
+func _logDebug(log Logger, msg string, args ...any) {
+	if nil != log {
+		log.Debug(msg, args...)
+	}
+}
+
+func _logWarn(log Logger, msg string, args ...any) {
+	if nil != log {
+		log.Warn(msg, args...)
+	}
+}
+
+func _logError(log Logger, msg string, args ...any) {
+	if nil != log {
+		log.Error(msg, args...)
+	}
+}
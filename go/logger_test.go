@@ -0,0 +1,83 @@
+package voxgigstruct_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+type testLogger struct {
+	entries []string
+}
+
+func (l *testLogger) log(level string, msg string, args ...any) {
+	l.entries = append(l.entries, strings.TrimSpace(fmt.Sprintln(append([]any{level, msg}, args...)...)))
+}
+
+func (l *testLogger) Debug(msg string, args ...any) { l.log("DEBUG", msg, args...) }
+func (l *testLogger) Warn(msg string, args ...any)  { l.log("WARN", msg, args...) }
+func (l *testLogger) Error(msg string, args ...any) { l.log("ERROR", msg, args...) }
+
+func TestLogger(t *testing.T) {
+
+	t.Run("logger-trace", func(t *testing.T) {
+		log := &testLogger{}
+		result := voxgigstruct.TransformWith(
+			map[string]any{"a": 1},
+			map[string]any{"a": "`$COPY`"},
+			&voxgigstruct.TransformOptions{Logger: log},
+		)
+		if !reflect.DeepEqual(map[string]any{"a": 1}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+		if 1 != len(log.entries) || "DEBUG inject ref $COPY mode val path a" != log.entries[0] {
+			t.Errorf("Unexpected log: %q", log.entries)
+		}
+	})
+
+	t.Run("logger-panic", func(t *testing.T) {
+		log := &testLogger{}
+		var boom voxgigstruct.Injector = func(
+			state *voxgigstruct.Injection, val any, current any, ref *string, store any,
+		) any {
+			panic("boom")
+		}
+
+		func() {
+			defer func() {
+				if r := recover(); "boom" != r {
+					t.Errorf("Expected panic to continue, Got: %v", r)
+				}
+			}()
+			voxgigstruct.TransformWith(nil, map[string]any{"a": map[string]any{"b": "`$BOOM`"}},
+				&voxgigstruct.TransformOptions{
+					Extra:  map[string]any{"$BOOM": boom},
+					Logger: log,
+				})
+		}()
+
+		last := log.entries[len(log.entries)-1]
+		if "ERROR inject handler panic ref $BOOM mode val path a.b panic boom" != last {
+			t.Errorf("Unexpected log: %q", log.entries)
+		}
+	})
+
+	t.Run("logger-store", func(t *testing.T) {
+		log := &testLogger{}
+		store := map[string]any{"$LOG": log}
+		if voxgigstruct.StoreLogger(store) != log {
+			t.Errorf("Expected store logger")
+		}
+		if nil != voxgigstruct.StoreLogger(map[string]any{}) {
+			t.Errorf("Expected no logger")
+		}
+
+		_, err := voxgigstruct.ValidateCollect(1, "`$NUMBER`", store, nil)
+		if nil != err || 0 == len(log.entries) {
+			t.Errorf("Expected validation trace: %v %q", err, log.entries)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"time"
+)
+
+// A record of the inputs of a transform that uses generators
+// (`$UUID`, `$RANDOM`, `$WHEN`), so that the output can be replayed
+// exactly. Store the manifest with the output (it encodes as JSON),
+// and provide it as TransformOptions.Manifest to replay the transform.
+type Manifest struct {
+	Seed      int64     `json:"seed"`      // Seed of the random numbers and identifiers.
+	Clock     time.Time `json:"clock"`     // Fixed time of `$WHEN`.
+	SpecHash  string    `json:"specHash"`  // SHA-256 of the spec (hex), if checked.
+	InputHash string    `json:"inputHash"` // SHA-256 of the data (hex), if checked.
+}
+
+// Create a manifest for a transform of data with a spec, with a
+// random seed and the current time.
+func NewManifest(data any, spec any) *Manifest {
+	return &Manifest{
+		Seed:      rand.Int63(),
+		Clock:     time.Now().UTC(),
+		SpecHash:  HashValue(spec),
+		InputHash: HashValue(data),
+	}
+}
+
+// The environment of the manifest (see SeededEnv).
+func (m *Manifest) Env() *Env {
+	return SeededEnv(m.Seed, m.Clock)
+}
+
+// Check that data and a spec match the hashes of the manifest. Empty
+// hashes are not checked.
+func (m *Manifest) Check(data any, spec any) error {
+	if S_MT != m.SpecHash && m.SpecHash != HashValue(spec) {
+		return NewPathError(ErrSpec, nil, nil,
+			"Manifest spec hash does not match: expected %s, found %s", m.SpecHash, HashValue(spec))
+	}
+	if S_MT != m.InputHash && m.InputHash != HashValue(data) {
+		return NewPathError(ErrSpec, nil, nil,
+			"Manifest input hash does not match: expected %s, found %s", m.InputHash, HashValue(data))
+	}
+	return nil
+}
+
+// The SHA-256 (hex) of the JSON form of a value (see ToJSON), with
+// sorted map keys. Values that cannot be encoded are hashed as their
+// Stringify form.
+func HashValue(val any) string {
+	src, err := ToJSON(val)
+	if nil != err {
+		src = []byte(Stringify(val))
+	}
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
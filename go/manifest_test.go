@@ -0,0 +1,86 @@
+package voxgigstruct_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestManifest(t *testing.T) {
+
+	spec := map[string]any{
+		"id":   "`$UUID`",
+		"r":    "`$RANDOM`",
+		"when": "`$WHEN`",
+		"a":    "`a`",
+	}
+	data := map[string]any{"a": 1}
+
+	t.Run("manifest-record-replay", func(t *testing.T) {
+		first := voxgigstruct.TransformCollect(data, spec, &voxgigstruct.TransformOptions{Record: true})
+		if nil == first.Manifest || 0 < len(first.Errs) {
+			t.Fatalf("Expected a manifest: %v %v", first.Manifest, first.Errs)
+		}
+
+		// The manifest is stored as JSON.
+		src, err := json.Marshal(first.Manifest)
+		if nil != err {
+			t.Fatal(err)
+		}
+		var manifest voxgigstruct.Manifest
+		if err := json.Unmarshal(src, &manifest); nil != err {
+			t.Fatal(err)
+		}
+
+		replay := voxgigstruct.TransformCollect(data, spec, &voxgigstruct.TransformOptions{Manifest: &manifest})
+		if !reflect.DeepEqual(first.Out, replay.Out) {
+			t.Errorf("Expected: %v, Got: %v", first.Out, replay.Out)
+		}
+
+		out := voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{Manifest: &manifest})
+		if !reflect.DeepEqual(first.Out, out) {
+			t.Errorf("Expected: %v, Got: %v", first.Out, out)
+		}
+
+		// A new manifest gives a different output.
+		other := voxgigstruct.TransformCollect(data, spec, &voxgigstruct.TransformOptions{Record: true})
+		if reflect.DeepEqual(first.Out, other.Out) {
+			t.Errorf("Expected different output: %v", other.Out)
+		}
+	})
+
+	t.Run("manifest-mismatch", func(t *testing.T) {
+		manifest := voxgigstruct.NewManifest(data, spec)
+
+		result := voxgigstruct.TransformCollect(map[string]any{"a": 2}, spec,
+			&voxgigstruct.TransformOptions{Manifest: manifest})
+		if nil != result.Out || 1 != len(result.Errs) {
+			t.Fatalf("Expected an error: %v %v", result.Out, result.Errs)
+		}
+		if err, _ := result.Errs[0].(error); !errors.Is(err, voxgigstruct.ErrSpec) {
+			t.Errorf("Expected ErrSpec: %v", result.Errs[0])
+		}
+
+		// Without hashes, only the environment is used.
+		manifest.InputHash = ""
+		result = voxgigstruct.TransformCollect(map[string]any{"a": 2}, spec,
+			&voxgigstruct.TransformOptions{Manifest: manifest})
+		if 0 < len(result.Errs) || 2 != voxgigstruct.GetProp(result.Out, "a") {
+			t.Errorf("Unexpected result: %v %v", result.Out, result.Errs)
+		}
+	})
+
+	t.Run("manifest-hash", func(t *testing.T) {
+		a := voxgigstruct.HashValue(map[string]any{"x": 1, "y": []any{"a", true}})
+		b := voxgigstruct.HashValue(map[string]any{"y": []any{"a", true}, "x": 1})
+		if a != b || 64 != len(a) {
+			t.Errorf("Expected equal hashes: %v %v", a, b)
+		}
+		if a == voxgigstruct.HashValue(map[string]any{"x": 2}) {
+			t.Errorf("Expected different hashes")
+		}
+	})
+}
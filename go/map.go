@@ -0,0 +1,88 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Project each element of a source list (or each value of a source
+// map). Format: ['`$MAP`', source-path, projection]. The source path
+// is resolved as for `$EACH`, and the output has the type of the
+// source. The projection is one of:
+//
+//   - A path (such as "name" or "address.city"): the value at the path
+//     of each element, or nil if missing. No spec is injected.
+//   - A sub-spec: injected with each element as the current value, so
+//     that "`.name`" is the name of the element.
+//
+// Unlike `$EACH`, map keys are kept, and there is no `$META` key.
+var Transform_MAP Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$MAP", 2)
+	if !ok {
+		return nil
+	}
+
+	src := _itemsSource(args[0], store, current, state)
+	if nil == src {
+		return _replaceTransform(state, nil)
+	}
+	if !IsNode(src) {
+		state.Warn("map-source", "Source for $MAP is not a list or map: "+Typify(src))
+		return _replaceTransform(state, nil)
+	}
+
+	items := Items(src)
+	proj := args[1]
+	vals := make([]any, len(items))
+
+	if path, ok := proj.(string); ok && !reInjectPart.MatchString(path) {
+		parts, _ := _pathParts(path)
+		cpath := &Path{src: path, parts: parts, wild: _stringWildcards(path)}
+		_reserveOutput(store, len(items), nil, state.Path)
+		for iI, item := range items {
+			vals[iI] = Clone(GetPath(cpath, item[1]))
+		}
+
+	} else {
+		_reserveOutput(store, len(items), proj, state.Path)
+
+		// Relative references are resolved against the data of a map,
+		// so a scalar sub-spec is wrapped in a map.
+		wrap := !IsNode(proj)
+
+		// Parallel data structures: elements :: sub-specs.
+		tcur := make([]any, len(items))
+		tval := make([]any, len(items))
+		for iI, item := range items {
+			tcur[iI] = item[1]
+			tval[iI] = Clone(proj)
+			if wrap {
+				tval[iI] = map[string]any{"value": tval[iI]}
+			}
+		}
+
+		out := InjectDescend(tval, store, state.Modify, map[string]any{S_DTOP: tcur},
+			_nestedState(tval, store, state))
+		vals = _listify(out)
+		if wrap {
+			for iI, v := range vals {
+				vals[iI] = GetProp(v, "value")
+			}
+		}
+	}
+
+	if IsList(src) {
+		return _replaceTransform(state, vals)
+	}
+
+	out := map[string]any{}
+	for iI, item := range items {
+		if iI < len(vals) && nil != vals[iI] {
+			out[item[0].(string)] = vals[iI]
+		}
+	}
+	return _replaceTransform(state, out)
+}
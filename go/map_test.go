@@ -0,0 +1,74 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestMap(t *testing.T) {
+
+	data := map[string]any{
+		"people": []any{
+			map[string]any{"name": "ann", "address": map[string]any{"city": "cork"}},
+			map[string]any{"name": "bob"},
+		},
+		"byid": map[string]any{
+			"a1": map[string]any{"name": "ann", "age": 30},
+			"b2": map[string]any{"name": "bob", "age": 40},
+		},
+	}
+
+	t.Run("map-path", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"names":  []any{"`$MAP`", "people", "name"},
+			"cities": []any{"`$MAP`", "people", "address.city"},
+			"ages":   []any{"`$MAP`", "byid", "age"},
+		})
+		expected := map[string]any{
+			"names":  []any{"ann", "bob"},
+			"cities": []any{"cork", nil},
+			"ages":   map[string]any{"a1": 30, "b2": 40},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("map-spec", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"people": []any{"`$MAP`", "people", map[string]any{
+				"label": "name: `.name`",
+				"kind":  "person",
+			}},
+			"byid": []any{"`$MAP`", "byid", "`.name`"},
+		})
+		expected := map[string]any{
+			"people": []any{
+				map[string]any{"label": "name: ann", "kind": "person"},
+				map[string]any{"label": "name: bob", "kind": "person"},
+			},
+			"byid": map[string]any{"a1": "ann", "b2": "bob"},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("map-source", func(t *testing.T) {
+		result := voxgigstruct.TransformCollect(data, map[string]any{
+			"a": []any{"`$MAP`", "people.0.name", "x"},
+			"b": []any{"`$MAP`", "missing", "x"},
+			"c": []any{"`$MAP`", "people", "missing"},
+		}, nil)
+
+		expected := map[string]any{"c": []any{nil, nil}}
+		if !reflect.DeepEqual(expected, result.Out) {
+			t.Errorf("Expected: %v, Got: %v", expected, result.Out)
+		}
+		if 1 != len(result.Warnings) || "map-source" != result.Warnings[0].Code {
+			t.Errorf("Unexpected warnings: %v", result.Warnings)
+		}
+	})
+}
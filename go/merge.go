@@ -0,0 +1,563 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+	"strings"
+)
+
+// A conflict found by Merge3: both sides changed the value at Path,
+// to different values. A nil value means the key was removed (or not
+// present).
+type Conflict struct {
+	Path   []string
+	Base   any
+	Mine   any
+	Theirs any
+}
+
+// Three-way structural merge of two versions (mine and theirs) of a
+// common base. Changes made on only one side are applied; a value
+// changed on both sides to the same value is kept. Maps are merged by
+// key, and lists are merged by index if all three lists have the same
+// length, otherwise a list is treated as a single value. Where both
+// sides made different changes, a Conflict is reported and the merged
+// result uses mine. None of the arguments are modified.
+func Merge3(base any, mine any, theirs any) (any, []Conflict) {
+	conflicts := []Conflict{}
+	out := _merge3(base, mine, theirs, []string{}, &conflicts)
+	return out, conflicts
+}
+
+func _merge3(base any, mine any, theirs any, path []string, conflicts *[]Conflict) any {
+	if _equal(mine, theirs) || _equal(base, theirs) {
+		return Clone(mine)
+	}
+
+	if _equal(base, mine) {
+		return Clone(theirs)
+	}
+
+	// Both sides changed a map: merge by key.
+	if IsMap(mine) && IsMap(theirs) {
+		if !IsMap(base) {
+			base = map[string]any{}
+		}
+		bm := base.(map[string]any)
+		mm := mine.(map[string]any)
+		tm := theirs.(map[string]any)
+
+		keyset := map[string]bool{}
+		keys := []string{}
+		for _, m := range []map[string]any{bm, mm, tm} {
+			for k := range m {
+				if !keyset[k] {
+					keyset[k] = true
+					keys = append(keys, k)
+				}
+			}
+		}
+		sort.Strings(keys)
+
+		out := map[string]any{}
+		for _, k := range keys {
+			val := _merge3(bm[k], mm[k], tm[k], _childPath(path, k), conflicts)
+			if nil != val {
+				out[k] = val
+			}
+		}
+		return out
+	}
+
+	// Both sides changed a list of the same length: merge by index.
+	if IsList(mine) && IsList(theirs) && IsList(base) &&
+		NumKeys(base) == NumKeys(mine) && NumKeys(base) == NumKeys(theirs) {
+		bl := _listify(base)
+		ml := _listify(mine)
+		tl := _listify(theirs)
+
+		out := make([]any, len(ml))
+		for i := range ml {
+			out[i] = _merge3(bl[i], ml[i], tl[i], _childPath(path, StrKey(i)), conflicts)
+		}
+		return out
+	}
+
+	*conflicts = append(*conflicts, Conflict{
+		Path:   path,
+		Base:   base,
+		Mine:   mine,
+		Theirs: theirs,
+	})
+
+	return Clone(mine)
+}
+
+// How lists are merged by MergeWith.
+type ListMerge string
+
+const (
+	ListIndex   ListMerge = "index"   // Merge by index, as for Merge (the default).
+	ListConcat  ListMerge = "concat"  // Append later lists to earlier lists.
+	ListReplace ListMerge = "replace" // Later lists replace earlier lists.
+	ListUnion   ListMerge = "union"   // Append, removing duplicates (deep equality).
+)
+
+// How the values at a path are merged by MergeWith.
+type MergePolicy string
+
+const (
+	PolicyDeep    MergePolicy = "deep"    // Merge nodes, and lists by index.
+	PolicyReplace MergePolicy = "replace" // Later values replace earlier values.
+	PolicyConcat  MergePolicy = "concat"  // Merge nodes, and append later lists.
+	PolicyIgnore  MergePolicy = "ignore"  // Keep earlier values, if defined.
+)
+
+// How MergeWith merges a map and a list at the same path.
+type KindMerge string
+
+const (
+	KindKeys    KindMerge = "keys"    // Merge the later node into the earlier by key, as for Merge (the default).
+	KindReplace KindMerge = "replace" // The later node replaces the earlier.
+	KindError   KindMerge = "error"   // Keep the earlier node, and report an error (see MergeChecked).
+	KindWrap    KindMerge = "wrap"    // Wrap the map in a list, and merge the lists.
+)
+
+// Options for MergeWith.
+type MergeOptions struct {
+	// Strategy for all lists.
+	Lists ListMerge
+
+	// Strategy for lists at specific paths, overriding Lists. Paths
+	// are dotted (see GetPath), and a `*` part matches any key.
+	ListPaths map[string]ListMerge
+
+	// Merge policies for specific paths, as for ListPaths, for example
+	// {"servers.*": "concat"}. A policy overrides the list strategy.
+	Policies map[string]MergePolicy
+
+	// How a map and a list at the same path are merged. Wrapped maps
+	// are merged with the list strategy of the path. As for Merge,
+	// KindKeys replaces nodes at the top level, and empty nodes.
+	Kinds KindMerge
+
+	// Resolve a collision between two (non-nil) scalars at the same
+	// path, where a is the earlier value, and b the later value. The
+	// returned value is used. The default is to use b.
+	Resolver func(path []string, a any, b any) any
+
+	// If not nil, filled with the index of the source (in the merge
+	// list) that provided the value of each leaf of the output. Keys
+	// are dotted paths ("" for a scalar output), with the keys escaped
+	// as for EscPathKey, and empty nodes are leaves.
+	Provenance map[string]int
+
+	// If not nil, called for each change applied to the output, in
+	// order. Nodes that are merged are not changes themselves, but
+	// the values set inside them are. Values are not cloned.
+	OnChange func(MergeChange)
+}
+
+// A change applied by MergeWith.
+type MergeChange struct {
+	Path   []string
+	Old    any // Previous value (nil if none).
+	New    any
+	Source int // Index of the source (in the merge list) of the change.
+}
+
+// Merge a list of values into each other, as for Merge, with options
+// to control how lists are merged. The first element is modified.
+func MergeWith(val any, opts MergeOptions) any {
+	out, _ := MergeChecked(val, opts)
+	return out
+}
+
+// Merge a list of values, as for MergeWith, also returning an ErrType
+// *PathError for the first map and list at the same path, if
+// opts.Kinds is KindError. The merge continues, keeping the earlier
+// node at each such path.
+func MergeChecked(val any, opts MergeOptions) (any, error) {
+	if !IsList(val) {
+		return val, nil
+	}
+
+	list := _listify(val)
+	if 0 == len(list) {
+		return nil, nil
+	}
+	if 1 == len(list) {
+		return list[0], nil
+	}
+
+	m := _newMerger(opts)
+
+	out := GetProp(list, 0, make(map[string]any))
+	m.mark([]string{}, out)
+
+	for oI, obj := range list[1:] {
+		m.src = oI + 1
+
+		if m.collide(out, obj) {
+			prev := out
+			out = m.opts.Resolver([]string{}, out, obj)
+			m.resolved([]string{}, prev, out)
+			m.change([]string{}, prev, out)
+
+		} else if IsNode(obj) && IsNode(out) && IsMap(obj) != IsMap(out) &&
+			KindReplace != m.kinds() && KindKeys != m.kinds() {
+			out = m.conflict(out, obj, []string{})
+
+		} else if !IsNode(obj) || !IsNode(out) || IsMap(obj) != IsMap(out) {
+			// Nodes win, also over nodes of a different kind.
+			m.change([]string{}, out, obj)
+			out = obj
+			m.mark([]string{}, out)
+		} else {
+			out = m.merge(out, obj, []string{})
+		}
+	}
+
+	return out, m.err
+}
+
+type merger struct {
+	opts      MergeOptions
+	listPaths [][]string
+	listKinds []ListMerge
+
+	policyPaths [][]string
+	policyKinds []MergePolicy
+
+	// Index of the current source, for provenance.
+	src int
+
+	// Index of the provenance keys, by path.
+	prov *provNode
+
+	// The first kind conflict, for KindError.
+	err error
+}
+
+func _newMerger(opts MergeOptions) *merger {
+	m := &merger{opts: opts}
+
+	patterns := make([]string, 0, len(opts.ListPaths))
+	for pattern := range opts.ListPaths {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		m.listPaths = append(m.listPaths, _splitPath(pattern))
+		m.listKinds = append(m.listKinds, opts.ListPaths[pattern])
+	}
+
+	patterns = patterns[:0]
+	for pattern := range opts.Policies {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		m.policyPaths = append(m.policyPaths, _splitPath(pattern))
+		m.policyKinds = append(m.policyKinds, opts.Policies[pattern])
+	}
+
+	if nil != opts.Provenance {
+		m.prov = &provNode{}
+		for key := range opts.Provenance {
+			path := []string{}
+			if S_MT != key {
+				path = _splitPath(key)
+			}
+			m.prov.at(path).leaf = true
+		}
+	}
+	return m
+}
+
+// The list strategy for a path.
+func (m *merger) lists(path []string) ListMerge {
+	switch m.policy(path) {
+	case PolicyDeep:
+		return ListIndex
+	case PolicyConcat:
+		return ListConcat
+	case PolicyReplace:
+		return ListReplace
+	}
+
+	for pI, pattern := range m.listPaths {
+		if _matchPath(pattern, path) {
+			return m.listKinds[pI]
+		}
+	}
+	if "" == m.opts.Lists {
+		return ListIndex
+	}
+	return m.opts.Lists
+}
+
+func (m *merger) kinds() KindMerge {
+	if "" == m.opts.Kinds {
+		return KindKeys
+	}
+	return m.opts.Kinds
+}
+
+// Merge a map and a list at the same path (for KindKeys, KindError
+// and KindWrap), returning the (possibly new) out.
+func (m *merger) conflict(out any, obj any, path []string) any {
+	if KindKeys == m.kinds() {
+		return m.merge(out, obj, path)
+	}
+
+	if KindError == m.kinds() {
+		if nil == m.err {
+			m.err = NewPathError(ErrType, append([]string{}, path...), nil,
+				"Cannot merge %s into %s at: %s", Typify(obj), Typify(out), Pathify(path))
+		}
+		return out
+	}
+
+	if IsMap(obj) {
+		obj = []any{obj}
+	} else {
+		prev := out
+		out = []any{out}
+		m.change(path, prev, out)
+	}
+	return m.merge(out, obj, path)
+}
+
+// The merge policy for a path, if any.
+func (m *merger) policy(path []string) MergePolicy {
+	for pI, pattern := range m.policyPaths {
+		if _matchPath(pattern, path) {
+			return m.policyKinds[pI]
+		}
+	}
+	return ""
+}
+
+// Merge obj onto out, returning the (possibly new) out. Nodes of
+// different kinds (for KindKeys) are merged by key.
+func (m *merger) merge(out any, obj any, path []string) any {
+	if IsList(obj) && IsList(out) {
+		switch m.lists(path) {
+		case ListConcat:
+			res := append(append([]any{}, _listify(out)...), _listify(obj)...)
+			m.markFrom(path, res, len(_listify(out)))
+			m.changeFrom(path, res, len(_listify(out)))
+			return res
+		case ListReplace:
+			m.mark(path, obj)
+			m.change(path, out, obj)
+			return obj
+		case ListUnion:
+			start := len(_listUnion(_listify(out), nil))
+			res := _listUnion(_listify(out), _listify(obj))
+			m.markFrom(path, res, start)
+			m.changeFrom(path, res, start)
+			return res
+		}
+	}
+
+	for _, key := range KeysOf(obj) {
+		val := GetProp(obj, key)
+		childpath := _childPath(path, key)
+		policy := m.policy(childpath)
+
+		if PolicyIgnore == policy && nil != GetProp(out, key) {
+			continue
+
+		} else if PolicyReplace == policy {
+			m.change(childpath, GetProp(out, key), val)
+			out = SetProp(out, key, Clone(val))
+			m.mark(childpath, val)
+
+		} else if child := GetProp(out, key); IsNode(val) && IsNode(child) &&
+			IsMap(child) != IsMap(val) && KindReplace != m.kinds() &&
+			!(KindKeys == m.kinds() && IsEmpty(val)) {
+			out = SetProp(out, key, m.conflict(child, val, childpath))
+
+		} else if IsNode(val) && (!IsEmpty(val) || (IsList(val) && _appends(m.lists(childpath)))) {
+			// Empty nodes replace, unless appended.
+			child := GetProp(out, key)
+			if !IsNode(child) || IsMap(child) != IsMap(val) {
+				// Create a new node, so that the input is not shared.
+				prev := child
+				if IsList(val) {
+					child = []any{}
+				} else {
+					child = map[string]any{}
+				}
+				m.mark(childpath, nil)
+				if nil != prev {
+					m.change(childpath, prev, child)
+				}
+			}
+			out = SetProp(out, key, m.merge(child, val, childpath))
+
+		} else if prev := GetProp(out, key); m.collide(prev, val) {
+			res := m.opts.Resolver(childpath, prev, val)
+			out = SetProp(out, key, res)
+			m.resolved(childpath, prev, res)
+			m.change(childpath, prev, res)
+
+		} else {
+			m.change(childpath, GetProp(out, key), val)
+			out = SetProp(out, key, val)
+			m.mark(childpath, val)
+		}
+	}
+
+	return out
+}
+
+// Two scalars collide, and there is a resolver.
+func (m *merger) collide(a any, b any) bool {
+	return nil != m.opts.Resolver && nil != a && nil != b && !IsNode(a) && !IsNode(b)
+}
+
+// Emit a change, if the value changed.
+func (m *merger) change(path []string, old any, val any) {
+	if nil != m.opts.OnChange && !_equal(old, val) {
+		m.opts.OnChange(MergeChange{Path: path, Old: old, New: val, Source: m.src})
+	}
+}
+
+// Emit the list elements from index start as changes.
+func (m *merger) changeFrom(path []string, list []any, start int) {
+	for iI := start; iI < len(list); iI++ {
+		m.change(_childPath(path, StrKey(iI)), nil, list[iI])
+	}
+}
+
+// Record the current source as the provenance of the leaves of val
+// at path, replacing any previous provenance under the path.
+func (m *merger) mark(path []string, val any) {
+	if nil == m.opts.Provenance {
+		return
+	}
+
+	node := m.prov.at(path)
+	m.unmark(path, node)
+	m.markLeaves(path, node, val)
+}
+
+// Remove the provenance keys under a path.
+func (m *merger) unmark(path []string, node *provNode) {
+	if node.leaf {
+		delete(m.opts.Provenance, _provKey(path))
+		node.leaf = false
+	}
+	for key, child := range node.children {
+		m.unmark(_childPath(path, key), child)
+	}
+	node.children = nil
+}
+
+// Record the current source as the provenance of the list elements
+// from index start.
+func (m *merger) markFrom(path []string, list []any, start int) {
+	if nil == m.opts.Provenance {
+		return
+	}
+	for iI := start; iI < len(list); iI++ {
+		m.mark(_childPath(path, StrKey(iI)), list[iI])
+	}
+}
+
+// A resolved collision keeps the earlier provenance if the earlier
+// value was chosen.
+func (m *merger) resolved(path []string, prev any, res any) {
+	if !_equal(prev, res) {
+		m.mark(path, res)
+	}
+}
+
+func (m *merger) markLeaves(path []string, node *provNode, val any) {
+	if nil == val {
+		return
+	}
+	if IsNode(val) && !IsEmpty(val) {
+		for _, key := range KeysOf(val) {
+			m.markLeaves(_childPath(path, key), node.at([]string{key}), GetProp(val, key))
+		}
+		return
+	}
+	m.opts.Provenance[_provKey(path)] = m.src
+	node.leaf = true
+}
+
+// A node of the provenance index. The provenance keys under a path
+// are found from its node, rather than by scanning all the keys.
+type provNode struct {
+	leaf     bool // A provenance key is recorded for the path.
+	children map[string]*provNode
+}
+
+// The node of a path below this node, created if needed.
+func (n *provNode) at(path []string) *provNode {
+	for _, key := range path {
+		child, ok := n.children[key]
+		if !ok {
+			if nil == n.children {
+				n.children = map[string]*provNode{}
+			}
+			child = &provNode{}
+			n.children[key] = child
+		}
+		n = child
+	}
+	return n
+}
+
+// The provenance key of a path: the escaped keys, joined with dots.
+func _provKey(path []string) string {
+	keys := make([]string, len(path))
+	for pI, key := range path {
+		keys[pI] = EscPathKey(key)
+	}
+	return strings.Join(keys, S_DT)
+}
+
+func _appends(lists ListMerge) bool {
+	return ListConcat == lists || ListUnion == lists
+}
+
+// The distinct elements of both lists, in order of first appearance.
+func _listUnion(a []any, b []any) []any {
+	out := []any{}
+	for _, list := range [][]any{a, b} {
+		for _, item := range list {
+			found := false
+			for _, existing := range out {
+				if _equal(existing, item) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// Match a path against a pattern, where a `*` part matches any key.
+func _matchPath(pattern []string, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for pI, part := range pattern {
+		if S_ST != part && part != path[pI] {
+			return false
+		}
+	}
+	return true
+}
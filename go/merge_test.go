@@ -0,0 +1,399 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestMerge(t *testing.T) {
+
+	t.Run("merge-three-way", func(t *testing.T) {
+		base := map[string]any{
+			"a": 1, "b": 2, "c": 3, "d": map[string]any{"x": 1, "y": 2}, "l": []any{1, 2},
+		}
+		mine := map[string]any{
+			"a": 10, "b": 2, "c": 30, "d": map[string]any{"x": 1, "y": 20}, "l": []any{5, 2}, "m": 1,
+		}
+		theirs := map[string]any{
+			"a": 1, "b": 20, "c": 31, "d": map[string]any{"x": 2, "y": 2}, "l": []any{1, 6},
+		}
+
+		result, conflicts := voxgigstruct.Merge3(base, mine, theirs)
+		expected := map[string]any{
+			"a": 10, "b": 20, "c": 30, "d": map[string]any{"x": 2, "y": 20}, "l": []any{5, 6}, "m": 1,
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		expectedConflicts := []voxgigstruct.Conflict{
+			{Path: []string{"c"}, Base: 3, Mine: 30, Theirs: 31},
+		}
+		if !reflect.DeepEqual(expectedConflicts, conflicts) {
+			t.Errorf("Expected: %v, Got: %v", expectedConflicts, conflicts)
+		}
+
+		// Arguments are not modified.
+		if 1 != base["d"].(map[string]any)["x"] || 20 != mine["d"].(map[string]any)["y"] {
+			t.Errorf("Arguments were modified")
+		}
+	})
+
+	t.Run("merge-three-way-removed", func(t *testing.T) {
+		base := map[string]any{"a": 1, "b": 2, "c": []any{1}}
+		mine := map[string]any{"b": 2, "c": []any{1, 2}}
+		theirs := map[string]any{"a": 1, "b": 3, "c": []any{3}}
+
+		result, conflicts := voxgigstruct.Merge3(base, mine, theirs)
+		expected := map[string]any{"b": 3, "c": []any{1, 2}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		expectedConflicts := []voxgigstruct.Conflict{
+			{Path: []string{"c"}, Base: []any{1}, Mine: []any{1, 2}, Theirs: []any{3}},
+		}
+		if !reflect.DeepEqual(expectedConflicts, conflicts) {
+			t.Errorf("Expected: %v, Got: %v", expectedConflicts, conflicts)
+		}
+
+		result, conflicts = voxgigstruct.Merge3(nil, map[string]any{"a": 1}, map[string]any{"a": 2})
+		if !reflect.DeepEqual(map[string]any{"a": 1}, result) || 1 != len(conflicts) {
+			t.Errorf("Unexpected: %v %v", result, conflicts)
+		}
+	})
+
+	t.Run("merge-with-lists", func(t *testing.T) {
+		vals := func() []any {
+			return []any{
+				map[string]any{"a": []any{1, 2}, "b": map[string]any{"c": []any{"x"}}, "d": []any{1, 2}},
+				map[string]any{"a": []any{3}, "b": map[string]any{"c": []any{"y"}}, "d": []any{}},
+			}
+		}
+
+		result := voxgigstruct.MergeWith(vals(), voxgigstruct.MergeOptions{})
+		expected := map[string]any{
+			"a": []any{3, 2}, "b": map[string]any{"c": []any{"y"}}, "d": []any{},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.MergeWith(vals(), voxgigstruct.MergeOptions{Lists: voxgigstruct.ListConcat})
+		expected = map[string]any{
+			"a": []any{1, 2, 3}, "b": map[string]any{"c": []any{"x", "y"}}, "d": []any{1, 2},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.MergeWith(vals(), voxgigstruct.MergeOptions{
+			Lists: voxgigstruct.ListReplace,
+			ListPaths: map[string]voxgigstruct.ListMerge{
+				"*.c": voxgigstruct.ListConcat,
+			},
+		})
+		expected = map[string]any{
+			"a": []any{3}, "b": map[string]any{"c": []any{"x", "y"}}, "d": []any{},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.MergeWith([]any{[]any{1}, []any{2}},
+			voxgigstruct.MergeOptions{Lists: voxgigstruct.ListConcat})
+		if !reflect.DeepEqual([]any{1, 2}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+	})
+
+	t.Run("merge-with-union", func(t *testing.T) {
+		result := voxgigstruct.MergeWith([]any{
+			map[string]any{"tags": []any{"a", "b", "a"}, "hosts": []any{map[string]any{"h": 1}}},
+			map[string]any{"tags": []any{"b", "c"}, "hosts": []any{map[string]any{"h": 1}, map[string]any{"h": 2}}},
+			map[string]any{"tags": []any{}},
+		}, voxgigstruct.MergeOptions{Lists: voxgigstruct.ListUnion})
+
+		expected := map[string]any{
+			"tags":  []any{"a", "b", "c"},
+			"hosts": []any{map[string]any{"h": 1}, map[string]any{"h": 2}},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+	})
+
+	t.Run("merge-with-kinds", func(t *testing.T) {
+		vals := func() []any {
+			return []any{
+				map[string]any{"a": map[string]any{"x": 1}, "b": []any{1}, "c": 1},
+				map[string]any{"a": []any{2}, "b": map[string]any{"y": 2}, "c": 2},
+			}
+		}
+
+		// As for Merge, by default.
+		result, err := voxgigstruct.MergeChecked(vals(), voxgigstruct.MergeOptions{})
+		expected := map[string]any{"a": map[string]any{"0": 2, "x": 1}, "b": []any{1}, "c": 2}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result, err)
+		}
+		if merged := voxgigstruct.Merge(vals()); !reflect.DeepEqual(merged, result) {
+			t.Errorf("Expected: %v, Got: %v", merged, result)
+		}
+
+		for _, in := range [][]any{
+			{map[string]any{"a": []any{1, 2}}, map[string]any{"a": map[string]any{"x": 1, "1": 3}}},
+			{map[string]any{"a": map[string]any{"x": 1}}, map[string]any{"a": []any{map[string]any{"y": 1}}}},
+			{map[string]any{"a": map[string]any{"x": 1}}, map[string]any{"a": []any{}}},
+			{map[string]any{"a": 1}, []any{2}},
+		} {
+			expected := voxgigstruct.Merge(voxgigstruct.Clone(in))
+			if result := voxgigstruct.MergeWith(in, voxgigstruct.MergeOptions{}); !reflect.DeepEqual(expected, result) {
+				t.Errorf("Expected: %v, Got: %v", expected, result)
+			}
+		}
+
+		result, err = voxgigstruct.MergeChecked(vals(), voxgigstruct.MergeOptions{
+			Kinds: voxgigstruct.KindReplace,
+		})
+		expected = map[string]any{"a": []any{2}, "b": map[string]any{"y": 2}, "c": 2}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result, err)
+		}
+
+		result, err = voxgigstruct.MergeChecked(vals(), voxgigstruct.MergeOptions{
+			Kinds: voxgigstruct.KindError,
+		})
+		expected = map[string]any{"a": map[string]any{"x": 1}, "b": []any{1}, "c": 2}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+		var perr *voxgigstruct.PathError
+		if !errors.Is(err, voxgigstruct.ErrType) || !errors.As(err, &perr) ||
+			!reflect.DeepEqual([]string{"a"}, perr.Path) {
+			t.Errorf("Expected a type error at a: %v", err)
+		}
+
+		// Empty nodes are also kinds.
+		_, err = voxgigstruct.MergeChecked([]any{[]any{1}, map[string]any{}},
+			voxgigstruct.MergeOptions{Kinds: voxgigstruct.KindError})
+		if !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected a type error: %v", err)
+		}
+
+		result = voxgigstruct.MergeWith(vals(), voxgigstruct.MergeOptions{
+			Kinds: voxgigstruct.KindWrap,
+			Lists: voxgigstruct.ListConcat,
+		})
+		expected = map[string]any{
+			"a": []any{map[string]any{"x": 1}, 2},
+			"b": []any{1, map[string]any{"y": 2}},
+			"c": 2,
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.MergeWith([]any{
+			[]any{map[string]any{"x": 1}, 3},
+			map[string]any{"y": 2},
+		}, voxgigstruct.MergeOptions{Kinds: voxgigstruct.KindWrap})
+		expected2 := []any{map[string]any{"x": 1, "y": 2}, 3}
+		if !reflect.DeepEqual(expected2, result) {
+			t.Errorf("Expected: %v, Got: %v", expected2, result)
+		}
+	})
+
+	t.Run("merge-with-resolver", func(t *testing.T) {
+		collisions := []string{}
+		opts := voxgigstruct.MergeOptions{
+			Resolver: func(path []string, a any, b any) any {
+				collisions = append(collisions, strings.Join(path, "."))
+				if an, ok := a.(int); ok {
+					if bn, ok := b.(int); ok && bn < an {
+						return a
+					}
+				}
+				return b
+			},
+		}
+
+		result := voxgigstruct.MergeWith([]any{
+			map[string]any{"a": 5, "b": map[string]any{"c": 1, "d": "x"}, "l": []any{3}},
+			map[string]any{"a": 2, "b": map[string]any{"c": 7, "d": map[string]any{"e": 1}}, "l": []any{1, 4}},
+		}, opts)
+
+		expected := map[string]any{
+			"a": 5, "b": map[string]any{"c": 7, "d": map[string]any{"e": 1}}, "l": []any{3, 4},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+		if !reflect.DeepEqual([]string{"a", "b.c", "l.0"}, collisions) {
+			t.Errorf("Unexpected collisions: %v", collisions)
+		}
+
+		if result := voxgigstruct.MergeWith([]any{3, 1}, opts); 3 != result {
+			t.Errorf("Unexpected: %v", result)
+		}
+	})
+
+	t.Run("merge-with-policies", func(t *testing.T) {
+		over := map[string]any{
+			"servers": map[string]any{"a": []any{"x2"}, "b": []any{"y2"}},
+			"meta":    map[string]any{"v": 2, "w": 2},
+			"opts":    map[string]any{"q": 2},
+			"tags":    []any{"t2"},
+		}
+		result := voxgigstruct.MergeWith([]any{
+			map[string]any{
+				"servers": map[string]any{"a": []any{"x1"}},
+				"meta":    map[string]any{"v": 1},
+				"opts":    map[string]any{"p": 1},
+				"tags":    []any{"t1", "t0"},
+			},
+			over,
+		}, voxgigstruct.MergeOptions{
+			Lists: voxgigstruct.ListConcat,
+			Policies: map[string]voxgigstruct.MergePolicy{
+				"servers.*": voxgigstruct.PolicyConcat,
+				"meta.*":    voxgigstruct.PolicyIgnore,
+				"opts":      voxgigstruct.PolicyReplace,
+				"tags":      voxgigstruct.PolicyDeep,
+			},
+		})
+
+		expected := map[string]any{
+			"servers": map[string]any{"a": []any{"x1", "x2"}, "b": []any{"y2"}},
+			"meta":    map[string]any{"v": 1, "w": 2},
+			"opts":    map[string]any{"q": 2},
+			"tags":    []any{"t2", "t0"},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		// Replaced values are not shared with the input.
+		voxgigstruct.SetPath("opts.q", result, 3)
+		if 2 != over["opts"].(map[string]any)["q"] {
+			t.Errorf("Input modified: %v", over)
+		}
+	})
+
+	t.Run("merge-with-provenance", func(t *testing.T) {
+		prov := map[string]int{}
+		result := voxgigstruct.MergeWith([]any{
+			map[string]any{"db": map[string]any{"host": "a", "port": 1}, "tags": []any{"x"}, "opt": 1},
+			map[string]any{"db": map[string]any{"host": "b"}, "opt": map[string]any{"on": true}},
+			map[string]any{"db": map[string]any{"user": "u"}, "tags": []any{"y"}, "e": map[string]any{}},
+		}, voxgigstruct.MergeOptions{
+			Provenance: prov,
+			ListPaths:  map[string]voxgigstruct.ListMerge{"tags": voxgigstruct.ListConcat},
+		})
+
+		expected := map[string]any{
+			"db":   map[string]any{"host": "b", "port": 1, "user": "u"},
+			"tags": []any{"x", "y"},
+			"opt":  map[string]any{"on": true},
+			"e":    map[string]any{},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		eprov := map[string]int{
+			"db.host": 1, "db.port": 0, "db.user": 2,
+			"tags.0": 0, "tags.1": 2,
+			"opt.on": 1,
+			"e":      2,
+		}
+		if !reflect.DeepEqual(eprov, prov) {
+			t.Errorf("Expected: %v, Got: %v", eprov, prov)
+		}
+
+		// Resolved collisions keep the provenance of the chosen value.
+		prov = map[string]int{}
+		voxgigstruct.MergeWith([]any{
+			map[string]any{"a": 5, "b": 1},
+			map[string]any{"a": 2, "b": 3},
+		}, voxgigstruct.MergeOptions{
+			Provenance: prov,
+			Resolver: func(path []string, a any, b any) any {
+				if a.(int) < b.(int) {
+					return b
+				}
+				return a
+			},
+		})
+		if !reflect.DeepEqual(map[string]int{"a": 0, "b": 1}, prov) {
+			t.Errorf("Unexpected: %v", prov)
+		}
+
+		// Keys are escaped, so keys containing dots do not collide.
+		prov = map[string]int{"x.y": 9}
+		voxgigstruct.MergeWith([]any{
+			map[string]any{"a.b": 1, "a": map[string]any{"b": 2}, "x": 0},
+			map[string]any{"a": map[string]any{"b": 3}},
+		}, voxgigstruct.MergeOptions{Provenance: prov})
+		eprov = map[string]int{`a\.b`: 0, "a.b": 1, "x": 0}
+		if !reflect.DeepEqual(eprov, prov) {
+			t.Errorf("Expected: %v, Got: %v", eprov, prov)
+		}
+	})
+
+	t.Run("merge-with-changes", func(t *testing.T) {
+		changes := []string{}
+		result := voxgigstruct.MergeWith([]any{
+			map[string]any{"db": map[string]any{"host": "a", "port": 1}, "tags": []any{"x"}},
+			map[string]any{"db": map[string]any{"host": "b", "port": 1}, "tags": []any{"y"}},
+			map[string]any{"db": map[string]any{"user": "u"}, "opt": map[string]any{"on": true}},
+		}, voxgigstruct.MergeOptions{
+			ListPaths: map[string]voxgigstruct.ListMerge{"tags": voxgigstruct.ListConcat},
+			OnChange: func(c voxgigstruct.MergeChange) {
+				changes = append(changes, fmt.Sprintf("%d %s %v %v",
+					c.Source, strings.Join(c.Path, "."), c.Old, c.New))
+			},
+		})
+
+		expected := []string{
+			"1 db.host a b",
+			"1 tags.1 <nil> y",
+			"2 db.user <nil> u",
+			"2 opt.on <nil> true",
+		}
+		if !reflect.DeepEqual(expected, changes) {
+			t.Errorf("Expected: %q, Got: %q", expected, changes)
+		}
+		if "b" != voxgigstruct.GetPath("db.host", result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+
+		// Replaced values are a single change.
+		changes = changes[:0]
+		voxgigstruct.MergeWith([]any{
+			map[string]any{"a": []any{1}, "b": 1},
+			map[string]any{"a": []any{2, 3}, "b": map[string]any{"c": 1}},
+			"s",
+		}, voxgigstruct.MergeOptions{
+			Lists: voxgigstruct.ListReplace,
+			OnChange: func(c voxgigstruct.MergeChange) {
+				changes = append(changes, fmt.Sprintf("%d %s %v %v",
+					c.Source, strings.Join(c.Path, "."), c.Old, c.New))
+			},
+		})
+		expected = []string{
+			"1 a [1] [2 3]",
+			"1 b 1 map[]",
+			"1 b.c <nil> 1",
+			"2  map[a:[2 3] b:map[c:1]] s",
+		}
+		if !reflect.DeepEqual(expected, changes) {
+			t.Errorf("Expected: %q, Got: %q", expected, changes)
+		}
+	})
+}
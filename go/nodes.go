@@ -0,0 +1,44 @@
+//go:build go1.23
+
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"iter"
+)
+
+// Iterate over all the values of a data structure, depth first, as
+// (path, value) pairs. Nodes are yielded before their children, map
+// keys are in sorted order, and the root has an empty path. Each path
+// is a new slice. Values are produced lazily, so breaking out of the
+// loop stops the traversal:
+//
+//	for path, val := range Nodes(doc) { ... }
+func Nodes(val any) iter.Seq2[[]string, any] {
+	return func(yield func([]string, any) bool) {
+		type entry struct {
+			path []string
+			val  any
+		}
+
+		stack := []entry{{path: []string{}, val: val}}
+		for 0 < len(stack) {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if !yield(top.path, top.val) {
+				return
+			}
+
+			// Push children in reverse, so they are yielded in order.
+			keys := KeysOf(top.val)
+			for kI := len(keys) - 1; -1 < kI; kI-- {
+				stack = append(stack, entry{
+					path: _childPath(top.path, keys[kI]),
+					val:  GetProp(top.val, keys[kI]),
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestNodes(t *testing.T) {
+
+	doc := map[string]any{"b": []any{1, map[string]any{"c": 2}}, "a": nil}
+
+	t.Run("nodes-all", func(t *testing.T) {
+		paths := []string{}
+		vals := []any{}
+		for path, val := range voxgigstruct.Nodes(doc) {
+			paths = append(paths, strings.Join(path, "."))
+			if !voxgigstruct.IsNode(val) {
+				vals = append(vals, val)
+			}
+		}
+		if !reflect.DeepEqual([]string{"", "a", "b", "b.0", "b.1", "b.1.c"}, paths) {
+			t.Errorf("Unexpected: %v", paths)
+		}
+		if !reflect.DeepEqual([]any{nil, 1, 2}, vals) {
+			t.Errorf("Unexpected: %v", vals)
+		}
+	})
+
+	t.Run("nodes-break", func(t *testing.T) {
+		var found []string
+		for path, val := range voxgigstruct.Nodes(doc) {
+			if 1 == val {
+				found = path
+				break
+			}
+		}
+		if !reflect.DeepEqual([]string{"b", "0"}, found) {
+			t.Errorf("Unexpected: %v", found)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+	"strings"
+)
+
+// Special keys of entity reference placeholders.
+const (
+	S_DENTITY = "`$ENTITY`"
+	S_DID     = "`$ID`"
+)
+
+// Normalize a nested document into flat entity tables. The entity
+// specification is a map of entity names to entity definitions of the
+// form { path: 'path-to-entity', id: 'id-key-path' }, where the path
+// can contain wildcards (for example `posts.*.author`), and the id
+// key path defaults to `id`. Each entity found is replaced by a
+// reference placeholder { '`$ENTITY`': name, '`$ID`': id }, and
+// stored in its entity table, keyed by the stringified id. Deeper
+// entity paths are normalized first, so that entities can contain
+// references to other entities. The document is not modified.
+// Returns { result: normalized-doc, entities: { name: { id: entity } } }.
+func Normalize(doc any, entitySpecs any) map[string]any {
+	result := Clone(doc)
+	entities := map[string]any{}
+
+	type entitySpec struct {
+		name  string
+		parts []string
+		wild  []bool
+		id    any
+	}
+
+	specs := []entitySpec{}
+	for _, name := range KeysOf(entitySpecs) {
+		espec := GetProp(entitySpecs, name)
+		parts, ok := _pathParts(GetProp(espec, "path"))
+		if !ok {
+			continue
+		}
+		specs = append(specs, entitySpec{
+			name:  name,
+			parts: parts,
+			wild:  _pathWildcards(GetProp(espec, "path")),
+			id:    GetProp(espec, "id", "id"),
+		})
+		entities[name] = map[string]any{}
+	}
+
+	// Deepest paths first.
+	sort.SliceStable(specs, func(i, j int) bool {
+		return len(specs[i].parts) > len(specs[j].parts)
+	})
+
+	for _, spec := range specs {
+		table := entities[spec.name].(map[string]any)
+
+		for _, match := range _getPathMatches(result, spec.parts, spec.wild) {
+			entity := GetPath(match, result)
+			id := GetPath(spec.id, entity)
+			if !IsMap(entity) || nil == id || IsNode(id) {
+				continue
+			}
+
+			idstr := Stringify(id)
+			if existing, has := table[idstr]; has {
+				entity = Merge([]any{existing, entity})
+			}
+			table[idstr] = entity
+
+			ref := map[string]any{
+				S_DENTITY: spec.name,
+				S_DID:     id,
+			}
+
+			lenmatch := len(match)
+			if 0 == lenmatch {
+				result = ref
+			} else {
+				parent := GetPath(match[:lenmatch-1], result)
+				SetProp(parent, match[lenmatch-1], ref)
+			}
+		}
+	}
+
+	return map[string]any{
+		"result":   result,
+		"entities": entities,
+	}
+}
+
+// Reassemble a document normalized by Normalize, replacing reference
+// placeholders with (copies of) their entities. References to missing
+// entities, and cyclic references, are left as placeholders.
+func Denormalize(normalized any) any {
+	entities := GetProp(normalized, "entities")
+	return _denormalize(Clone(GetProp(normalized, "result")), entities, map[string]bool{})
+}
+
+func _denormalize(val any, entities any, active map[string]bool) any {
+	return Walk(val, func(key *string, val any, parent any, path []string) any {
+		name, isref := GetProp(val, S_DENTITY).(string)
+		if !isref || !IsMap(val) {
+			return val
+		}
+
+		idstr := Stringify(GetProp(val, S_DID))
+		entity := GetProp(GetProp(entities, name), idstr)
+		refkey := strings.Join([]string{name, idstr}, S_CN)
+
+		if nil == entity || active[refkey] {
+			return val
+		}
+
+		active[refkey] = true
+		entity = _denormalize(Clone(entity), entities, active)
+		delete(active, refkey)
+
+		return entity
+	})
+}
@@ -0,0 +1,83 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestNormalize(t *testing.T) {
+
+	doc := map[string]any{
+		"posts": []any{
+			map[string]any{"id": 1, "title": "A", "author": map[string]any{"id": "u1", "name": "Alice"}},
+			map[string]any{"id": 2, "title": "B", "author": map[string]any{"id": "u1", "name": "Alice"}},
+			map[string]any{"id": 3, "title": "C", "author": map[string]any{"id": "u2", "name": "Bob"}},
+		},
+	}
+
+	specs := map[string]any{
+		"posts": map[string]any{"path": "posts.*"},
+		"users": map[string]any{"path": "posts.*.author", "id": "id"},
+	}
+
+	t.Run("normalize-basic", func(t *testing.T) {
+		normalized := voxgigstruct.Normalize(doc, specs)
+
+		ref := func(name string, id any) map[string]any {
+			return map[string]any{"`$ENTITY`": name, "`$ID`": id}
+		}
+
+		expected := map[string]any{
+			"result": map[string]any{
+				"posts": []any{ref("posts", 1), ref("posts", 2), ref("posts", 3)},
+			},
+			"entities": map[string]any{
+				"posts": map[string]any{
+					"1": map[string]any{"id": 1, "title": "A", "author": ref("users", "u1")},
+					"2": map[string]any{"id": 2, "title": "B", "author": ref("users", "u1")},
+					"3": map[string]any{"id": 3, "title": "C", "author": ref("users", "u2")},
+				},
+				"users": map[string]any{
+					"u1": map[string]any{"id": "u1", "name": "Alice"},
+					"u2": map[string]any{"id": "u2", "name": "Bob"},
+				},
+			},
+		}
+
+		if !reflect.DeepEqual(expected, normalized) {
+			t.Errorf("Expected: %v, Got: %v", expected, normalized)
+		}
+
+		// Original is unchanged.
+		if "Alice" != voxgigstruct.GetPath("posts.0.author.name", doc) {
+			t.Errorf("Document was modified: %v", doc)
+		}
+	})
+
+	t.Run("normalize-denormalize", func(t *testing.T) {
+		normalized := voxgigstruct.Normalize(doc, specs)
+		result := voxgigstruct.Denormalize(normalized)
+		if !reflect.DeepEqual(doc, result) {
+			t.Errorf("Expected: %v, Got: %v", doc, result)
+		}
+	})
+
+	t.Run("normalize-cycle", func(t *testing.T) {
+		normalized := map[string]any{
+			"result": map[string]any{"`$ENTITY`": "n", "`$ID`": "a"},
+			"entities": map[string]any{
+				"n": map[string]any{
+					"a": map[string]any{"id": "a", "next": map[string]any{"`$ENTITY`": "n", "`$ID`": "a"}},
+				},
+			},
+		}
+
+		expected := map[string]any{"id": "a", "next": map[string]any{"`$ENTITY`": "n", "`$ID`": "a"}}
+		result := voxgigstruct.Denormalize(normalized)
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+	})
+}
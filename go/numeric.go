@@ -0,0 +1,281 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Rounding modes for `$ROUND`.
+const (
+	RoundHalfUp   = "half-up"   // Halves round away from zero (the default).
+	RoundHalfEven = "half-even" // Halves round to the even digit (banker's rounding).
+)
+
+// Numeric transforms, for post-processing values. Each is a list,
+// whose arguments are injected (so may be paths), and which is
+// replaced by the result:
+//
+//	['`$ROUND`', value, places?, mode?]
+//	['`$FLOOR`', value, places?]
+//	['`$CEIL`', value, places?]
+//	['`$ABS`', value]
+//	['`$CLAMP`', value, min?, max?]
+//
+// The number of decimal places defaults to zero, and may be negative
+// (-2 rounds to hundreds). Rounding is decimal, so that 1.005 rounds
+// to 1.01. A missing value gives no result, and a value that is not a
+// number gives no result, and a warning.
+var Transform_ROUND Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _numericTransform(state, current, store, "$ROUND",
+		func(n float64, args []any) (float64, bool) {
+			places, ok := _numArg(args, 0, 0)
+			mode, _ := GetProp(args, 1, RoundHalfUp).(string)
+			if !ok || (RoundHalfUp != mode && RoundHalfEven != mode) {
+				return 0, false
+			}
+			return _roundDecimal(n, int(places), mode), true
+		})
+}
+
+var Transform_FLOOR Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _numericTransform(state, current, store, "$FLOOR",
+		func(n float64, args []any) (float64, bool) {
+			places, ok := _numArg(args, 0, 0)
+			return _roundDecimal(n, int(places), "floor"), ok
+		})
+}
+
+var Transform_CEIL Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _numericTransform(state, current, store, "$CEIL",
+		func(n float64, args []any) (float64, bool) {
+			places, ok := _numArg(args, 0, 0)
+			return _roundDecimal(n, int(places), "ceil"), ok
+		})
+}
+
+var Transform_ABS Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _numericTransform(state, current, store, "$ABS",
+		func(n float64, args []any) (float64, bool) {
+			return math.Abs(n), true
+		})
+}
+
+var Transform_CLAMP Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	return _numericTransform(state, current, store, "$CLAMP",
+		func(n float64, args []any) (float64, bool) {
+			min, minok := _numArg(args, 0, math.Inf(-1))
+			max, maxok := _numArg(args, 1, math.Inf(1))
+			if !minok || !maxok || max < min {
+				return 0, false
+			}
+			return math.Max(min, math.Min(max, n)), true
+		})
+}
+
+// Apply a numeric function to the injected arguments of a numeric
+// transform, and replace the transform list with the result.
+func _numericTransform(
+	state *Injection,
+	current any,
+	store any,
+	name string,
+	apply func(n float64, args []any) (float64, bool),
+) any {
+	args, ok := _listTransformArgs(state, name, 1)
+	if !ok {
+		return nil
+	}
+	injected := _injectArgs(args, store, current, state)
+
+	var out any
+	if nil != injected[0] {
+		n, ok := _numValue(injected[0])
+		if !ok {
+			state.Warn("numeric-value", "Value for "+name+" is not a number: "+
+				Typify(injected[0]))
+		} else if res, ok := apply(n, injected[1:]); !ok {
+			state.Warn("numeric-args", "Invalid arguments for "+name+": "+
+				Stringify(injected[1:]))
+		} else {
+			out = res
+		}
+	}
+
+	return _replaceTransform(state, out)
+}
+
+// The arguments of a list transform, such as `$ROUND`, which must be
+// the first element of a list, with at least min arguments. The
+// arguments are not injected by the list injection, so the transform
+// can inject them as needed.
+func _listTransformArgs(state *Injection, name string, min int) ([]any, bool) {
+	args := _listify(state.Parent)
+	if nil != state.Keys {
+		state.Keys = state.Keys[:1]
+	}
+
+	if S_MVAL != state.Mode {
+		return nil, false
+	}
+
+	if 0 != state.KeyI || len(args) < min+1 {
+		state.Errs.Append("The " + name + " transform at field " + Pathify(state.Path, 1, 1) +
+			" must be the first element of an array, followed by its arguments.")
+		return nil, false
+	}
+
+	return args[1:], true
+}
+
+func _injectArgs(args []any, store any, current any, state *Injection) []any {
+	out := make([]any, len(args))
+	for aI, arg := range args {
+		out[aI] = _injectArg(arg, store, current, state)
+	}
+	return out
+}
+
+// Replace the list of a list transform (such as `$ROUND`) with its
+// output.
+func _replaceTransform(state *Injection, out any) any {
+	grandparent := GetProp(state.Nodes, len(state.Nodes)-2)
+	grandkey := GetProp(state.Path, len(state.Path)-2)
+	SetProp(grandparent, grandkey, out)
+
+	// Detach the transform list, so the output is not changed when the
+	// transform reference is replaced.
+	state.Parent = map[string]any{}
+
+	return nil
+}
+
+// Inject a transform argument, as a child of the current node.
+func _injectArg(arg any, store any, current any, state *Injection) any {
+	if !IsNode(arg) {
+		if s, ok := arg.(string); !ok || !reInjectPart.MatchString(s) {
+			return arg
+		}
+	}
+
+	// The state must refer to the clone, as node arguments are
+	// injected in place.
+	arg = Clone(arg)
+	astate := _injectState(arg, store, nil)
+	astate.Base = state.Base
+	astate.NoBase = state.NoBase
+	astate.Errs = state.Errs
+	astate.Warns = state.Warns
+	astate.Log = state.Log
+
+	return InjectDescend(arg, store, nil, current, astate)
+}
+
+// An optional numeric argument.
+func _numArg(args []any, index int, dflt float64) (float64, bool) {
+	arg := GetProp(args, index)
+	if nil == arg {
+		return dflt, true
+	}
+	return _numValue(arg)
+}
+
+func _numValue(val any) (float64, bool) {
+	return ToNum(val)
+}
+
+// Round to decimal places, using the shortest decimal form of the
+// number, so that binary representation errors do not affect halves.
+func _roundDecimal(n float64, places int, mode string) float64 {
+	if math.IsInf(n, 0) {
+		return n
+	}
+
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(n, 'f', -1, 64))
+	if !ok {
+		return n
+	}
+
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(_absInt(places))), nil))
+	if 0 <= places {
+		r.Mul(r, scale)
+	} else {
+		r.Quo(r, scale)
+	}
+
+	// Integer part (truncated toward zero), and remainder.
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if 0 != rem.Sign() {
+		neg := r.Sign() < 0
+		switch mode {
+		case "floor":
+			if neg {
+				quo.Sub(quo, big.NewInt(1))
+			}
+		case "ceil":
+			if !neg {
+				quo.Add(quo, big.NewInt(1))
+			}
+		default:
+			// Compare twice the remainder with the denominator.
+			cmp := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(r.Denom())
+			odd := 1 == quo.Bit(0)
+			if 0 < cmp || (0 == cmp && (RoundHalfUp == mode || odd)) {
+				if neg {
+					quo.Sub(quo, big.NewInt(1))
+				} else {
+					quo.Add(quo, big.NewInt(1))
+				}
+			}
+		}
+	}
+
+	out := new(big.Rat).SetInt(quo)
+	if 0 <= places {
+		out.Quo(out, scale)
+	} else {
+		out.Mul(out, scale)
+	}
+
+	f, _ := out.Float64()
+	return f
+}
+
+func _absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
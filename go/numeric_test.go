@@ -0,0 +1,111 @@
+package voxgigstruct_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestNumeric(t *testing.T) {
+
+	data := map[string]any{
+		"price": 1.005,
+		"half":  2.5,
+		"neg":   -2.5,
+		"big":   1234.5,
+		"qty":   7,
+		"exact": json.Number("19.995"),
+		"name":  "x",
+		"lo":    1,
+	}
+
+	round := func(args ...any) any {
+		return voxgigstruct.Transform(data, map[string]any{"v": append([]any{"`$ROUND`"}, args...)})
+	}
+
+	t.Run("numeric-round", func(t *testing.T) {
+		cases := []struct {
+			args     []any
+			expected any
+		}{
+			{[]any{"`price`", 2}, 1.01},
+			{[]any{"`half`"}, 3.0},
+			{[]any{"`neg`"}, -3.0},
+			{[]any{"`half`", 0, "half-even"}, 2.0},
+			{[]any{3.5, 0, "half-even"}, 4.0},
+			{[]any{"`neg`", 0, "half-even"}, -2.0},
+			{[]any{"`big`", -2}, 1200.0},
+			{[]any{"`exact`", 2}, 20.0},
+			{[]any{"`qty`"}, 7.0},
+		}
+		for _, c := range cases {
+			out := round(c.args...)
+			expected := map[string]any{"v": c.expected}
+			if !reflect.DeepEqual(expected, out) {
+				t.Errorf("Expected: %v, Got: %v (%v)", expected, out, c.args)
+			}
+		}
+	})
+
+	t.Run("numeric-floor-ceil-abs-clamp", func(t *testing.T) {
+		spec := map[string]any{
+			"f":  []any{"`$FLOOR`", "`price`", 2},
+			"fn": []any{"`$FLOOR`", "`neg`"},
+			"c":  []any{"`$CEIL`", "`price`", 2},
+			"cn": []any{"`$CEIL`", "`neg`"},
+			"a":  []any{"`$ABS`", "`neg`"},
+			"k":  []any{"`$CLAMP`", "`qty`", "`lo`", 5},
+			"kl": []any{"`$CLAMP`", "`neg`", 0},
+			"kn": []any{"`$CLAMP`", "`big`"},
+		}
+		out := voxgigstruct.Transform(data, spec)
+		expected := map[string]any{
+			"f": 1.0, "fn": -3.0, "c": 1.01, "cn": -2.0,
+			"a": 2.5, "k": 5.0, "kl": 0.0, "kn": 1234.5,
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("numeric-nested", func(t *testing.T) {
+		// In lists, in nested maps, and as the whole spec.
+		out := voxgigstruct.Transform(map[string]any{"a": map[string]any{"p": 1.25}}, map[string]any{
+			"l": []any{0, []any{"`$ROUND`", 1.4}},
+			"m": map[string]any{"r": []any{"`$ROUND`", "`a.p`", 1}},
+		})
+		expected := map[string]any{
+			"l": []any{0, 1.0},
+			"m": map[string]any{"r": 1.3},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		out = voxgigstruct.Transform(data, []any{"`$ABS`", "`neg`"})
+		if 2.5 != out {
+			t.Errorf("Expected: %v, Got: %v", 2.5, out)
+		}
+	})
+
+	t.Run("numeric-invalid", func(t *testing.T) {
+		warns := voxgigstruct.ListRefCreate[any]()
+		out := voxgigstruct.TransformWith(data, map[string]any{
+			"a": []any{"`$ROUND`", "`name`"},
+			"b": []any{"`$ROUND`", "`missing`"},
+			"c": []any{"`$ROUND`", "`qty`", 0, "sideways"},
+			"d": 1,
+		}, &voxgigstruct.TransformOptions{Extra: map[string]any{"$WARNS": warns}})
+
+		if !reflect.DeepEqual(map[string]any{"d": 1}, out) {
+			t.Errorf("Expected: %v, Got: %v", map[string]any{"d": 1}, out)
+		}
+		if 2 != len(warns.List) ||
+			"numeric-value" != warns.List[0].(voxgigstruct.Warning).Code ||
+			"numeric-args" != warns.List[1].(voxgigstruct.Warning).Code {
+			t.Errorf("Expected: %v, Got: %v", "numeric-value, numeric-args", warns.List)
+		}
+	})
+}
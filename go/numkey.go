@@ -0,0 +1,142 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format a number as a stable map key. StrKey truncates float keys to
+// integers (as they are also list indexes), so use NumKey for keys
+// derived from fractional values. The number is rounded to the given
+// decimal places, and formatted without an exponent or trailing
+// zeros, independent of locale, so that values from accumulated
+// arithmetic give the same key: NumKey(0.1+0.2, 6) == "0.3". Negative
+// places give the shortest exact representation. NaN and infinite
+// values are formatted as "NaN", "+Inf" and "-Inf".
+func NumKey(n float64, places int) string {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	}
+
+	if places < 0 {
+		places = -1
+	}
+
+	out := strconv.FormatFloat(n, 'f', places, 64)
+	if strings.Contains(out, S_DT) {
+		out = strings.TrimRight(strings.TrimRight(out, "0"), S_DT)
+	}
+
+	// Negative zero, also from rounding.
+	if "-0" == out {
+		out = "0"
+	}
+
+	return out
+}
+
+// Convert a numeric value to a float64, using the same rules as the
+// transforms. All Go integer and float kinds are numbers, as is
+// json.Number (see the "number" tag). NaN is not a number. Strings,
+// booleans and nil are not converted, so "1" is not a number. Large
+// 64-bit integers may lose precision.
+func ToNum(val any) (float64, bool) {
+	if n, ok := val.(json.Number); ok {
+		f, err := n.Float64()
+		return f, nil == err && !math.IsNaN(f)
+	}
+	f, err := _toFloat64(val)
+	return f, nil == err && !math.IsNaN(f)
+}
+
+// Convert a key to the string used for map properties, and path
+// parts. Strings (and non-nil string pointers) are returned as is.
+// Integers of any kind are formatted exactly in base 10. Floats are truncated
+// toward zero, as they are also list indexes, so 1.9 and -1.9 give
+// "1" and "-1" (use NumKey to keep the fraction). NaN, infinite
+// values, booleans, nil, and other types give the empty string.
+func ToKeyString(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case *string:
+		if nil != k {
+			return *k
+		}
+		return S_MT
+	case float32:
+		return _floatKey(float64(k))
+	case float64:
+		return _floatKey(k)
+	case json.Number:
+		if i, err := k.Int64(); nil == err {
+			return strconv.FormatInt(i, 10)
+		}
+	}
+
+	// Integers are formatted directly, as float64 is not exact
+	// above 2^53.
+	if nil != key {
+		v := reflect.ValueOf(key)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(v.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Uintptr:
+			return strconv.FormatUint(v.Uint(), 10)
+		}
+	}
+
+	if n, ok := ToNum(key); ok {
+		return strconv.FormatInt(int64(n), 10)
+	}
+	return S_MT
+}
+
+func _floatKey(n float64) string {
+	n = math.Trunc(n)
+	if math.IsNaN(n) || math.IsInf(n, 0) || n < math.MinInt64 || math.MaxInt64 <= n {
+		return S_MT
+	}
+	return strconv.FormatInt(int64(n), 10)
+}
+
+// Resolve a key into an index for a list of length size, as used by
+// GetProp and the path functions. Integer keys, and strings of
+// base 10 integers ("2", "-1"), are indexes. Floats are truncated
+// toward zero, so 1.9 is 1, but a fractional string, such as "1.5",
+// is not an index. Negative indexes count back from the end of the
+// list, so -1 is the last element. Returns false if the key is not
+// an index, or is out of range. NaN and infinite values are never
+// indexes.
+func ToIndex(key any, size int) (int, bool) {
+	var ki int
+
+	switch k := key.(type) {
+	case int:
+		ki = k
+	case string:
+		ski, err := strconv.Atoi(k)
+		if nil != err {
+			return 0, false
+		}
+		ki = ski
+	default:
+		fk, ok := ToNum(key)
+		if !ok || fk < math.MinInt || math.MaxInt <= fk {
+			return 0, false
+		}
+		ki = int(fk)
+	}
+
+	if ki < 0 {
+		ki = size + ki
+	}
+
+	return ki, 0 <= ki && ki < size
+}
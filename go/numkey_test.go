@@ -0,0 +1,153 @@
+package voxgigstruct_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestNumKey(t *testing.T) {
+
+	t.Run("numkey-basic", func(t *testing.T) {
+		a, b := 0.1, 0.2
+		cases := []struct {
+			n      float64
+			places int
+			out    string
+		}{
+			{a + b, 6, "0.3"},
+			{a + b, -1, "0.30000000000000004"},
+			{1.0000000000000002, 9, "1"},
+			{2.5, 0, "2"},
+			{1.25, 1, "1.2"},
+			{100, 2, "100"},
+			{1e21, 2, "1000000000000000000000"},
+			{-0.0000001, 3, "0"},
+			{-1.5, 3, "-1.5"},
+			{math.NaN(), 2, "NaN"},
+			{math.Inf(-1), 2, "-Inf"},
+		}
+		for _, c := range cases {
+			if out := voxgigstruct.NumKey(c.n, c.places); c.out != out {
+				t.Errorf("NumKey(%v, %d): expected %q, got %q", c.n, c.places, c.out, out)
+			}
+		}
+
+		// Derived keys are stable.
+		m := map[string]any{}
+		sum := 0.0
+		for i := 0; i < 10; i++ {
+			sum += 0.1
+		}
+		voxgigstruct.SetProp(m, voxgigstruct.NumKey(sum, 9), "a")
+		voxgigstruct.SetProp(m, voxgigstruct.NumKey(1.0, 9), "b")
+		if 1 != len(voxgigstruct.KeysOf(m)) || "b" != m["1"] {
+			t.Errorf("Unexpected keys: %v", m)
+		}
+	})
+
+	t.Run("tonum", func(t *testing.T) {
+		cases := []struct {
+			val any
+			out float64
+			ok  bool
+		}{
+			{1, 1, true},
+			{int8(-2), -2, true},
+			{uint16(3), 3, true},
+			{float32(1.5), 1.5, true},
+			{json.Number("2.25"), 2.25, true},
+			{math.Inf(1), math.Inf(1), true},
+			{math.NaN(), 0, false},
+			{json.Number("x"), 0, false},
+			{"1", 0, false},
+			{true, 0, false},
+			{nil, 0, false},
+		}
+		for _, c := range cases {
+			out, ok := voxgigstruct.ToNum(c.val)
+			if c.ok != ok || (ok && c.out != out) {
+				t.Errorf("ToNum(%v): Expected: %v %v, Got: %v %v", c.val, c.out, c.ok, out, ok)
+			}
+		}
+	})
+
+	t.Run("tokeystring", func(t *testing.T) {
+		s := "s"
+		var nilstr *string
+		cases := []struct {
+			key any
+			out string
+		}{
+			{"a.b", "a.b"},
+			{&s, "s"},
+			{nilstr, ""},
+			{uint8(7), "7"},
+			{int64(-3), "-3"},
+			{uint64(math.MaxUint64), "18446744073709551615"},
+			{int64(9007199254740993), "9007199254740993"},
+			{uint64(9007199254740993), "9007199254740993"},
+			{int64(math.MaxInt64), "9223372036854775807"},
+			{int(math.MaxInt64), "9223372036854775807"},
+			{int64(math.MinInt64), "-9223372036854775808"},
+			{json.Number("9007199254740993"), "9007199254740993"},
+			{1.9, "1"},
+			{-1.9, "-1"},
+			{float32(2.5), "2"},
+			{math.NaN(), ""},
+			{math.Inf(-1), ""},
+			{1e300, ""},
+			{json.Number("4"), "4"},
+			{true, ""},
+			{nil, ""},
+			{[]any{1}, ""},
+		}
+		for _, c := range cases {
+			if out := voxgigstruct.ToKeyString(c.key); c.out != out {
+				t.Errorf("ToKeyString(%v): Expected: %q, Got: %q", c.key, c.out, out)
+			}
+			if out := voxgigstruct.StrKey(c.key); c.out != out {
+				t.Errorf("StrKey(%v): Expected: %q, Got: %q", c.key, c.out, out)
+			}
+		}
+	})
+
+	t.Run("toindex", func(t *testing.T) {
+		cases := []struct {
+			key any
+			out int
+			ok  bool
+		}{
+			{0, 0, true},
+			{2, 2, true},
+			{3, 3, false},
+			{-1, 2, true},
+			{-4, -1, false},
+			{"1", 1, true},
+			{"-1", 2, true},
+			{"1.5", 0, false},
+			{"a", 0, false},
+			{1.9, 1, true},
+			{int64(2), 2, true},
+			{json.Number("1"), 1, true},
+			{math.NaN(), 0, false},
+			{math.Inf(1), 0, false},
+			{true, 0, false},
+			{nil, 0, false},
+		}
+		for _, c := range cases {
+			out, ok := voxgigstruct.ToIndex(c.key, 3)
+			if c.ok != ok || (ok && c.out != out) {
+				t.Errorf("ToIndex(%v, 3): Expected: %v %v, Got: %v %v", c.key, c.out, c.ok, out, ok)
+			}
+		}
+
+		// The same rules as the engine.
+		list := []any{"a", "b", "c"}
+		if "c" != voxgigstruct.GetProp(list, "-1") || "b" != voxgigstruct.GetProp(list, 1.9) {
+			t.Errorf("Unexpected list props")
+		}
+	})
+}
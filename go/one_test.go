@@ -0,0 +1,102 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestValidateOne(t *testing.T) {
+
+	owner := func() any {
+		return map[string]any{
+			"owner": []any{"`$ONE`", "`$STRING`", map[string]any{
+				"name": "`$STRING`",
+				"id":   "`$NUMBER`",
+			}},
+		}
+	}
+
+	t.Run("one-union", func(t *testing.T) {
+		for _, data := range []any{
+			map[string]any{"owner": "ann"},
+			map[string]any{"owner": map[string]any{"name": "ann", "id": 1}},
+		} {
+			out, err := voxgigstruct.Validate(data, owner())
+			if nil != err || !reflect.DeepEqual(data, out) {
+				t.Errorf("Expected: %v, Got: %v %v", data, out, err)
+			}
+		}
+	})
+
+	t.Run("one-closest", func(t *testing.T) {
+		data := map[string]any{"owner": map[string]any{"name": "ann", "id": "x1"}}
+		out, verrs := voxgigstruct.ValidateAll(data, owner())
+
+		expected := []string{"Expected field owner.id to be number, but found string: x1."}
+		if !reflect.DeepEqual(expected, verrs.Strings()) {
+			t.Errorf("Expected: %v, Got: %v", expected, verrs.Strings())
+		}
+		if 1 != len(verrs) || "owner.id" != verrs[0].Field() || voxgigstruct.VE_TYPE != verrs[0].Code {
+			t.Errorf("Unexpected errors: %v", verrs)
+		}
+		if !reflect.DeepEqual(data, out) {
+			t.Errorf("Expected: %v, Got: %v", data, out)
+		}
+	})
+
+	t.Run("one-closest-fewest", func(t *testing.T) {
+		spec := []any{"`$ONE`",
+			map[string]any{"a": "`$STRING`", "b": "`$STRING`"},
+			map[string]any{"a": "`$NUMBER`", "b": "`$STRING`"},
+			"`$NUMBER`",
+		}
+		_, err := voxgigstruct.Validate(map[string]any{"a": true, "b": "x"}, spec)
+		if nil == err || 1 != strings.Count(err.Error(), "Expected") ||
+			!strings.Contains(err.Error(), "field a to be string") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		// Nested paths.
+		_, err = voxgigstruct.Validate(map[string]any{"x": map[string]any{"k": map[string]any{"a": 1}}},
+			map[string]any{"x": map[string]any{"`$CHILD`": []any{"`$ONE`", "`$STRING`",
+				map[string]any{"a": "`$STRING`"}}}})
+		if nil == err || !strings.Contains(err.Error(), "field x.k.a to be string") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("one-none-close", func(t *testing.T) {
+		_, verrs := voxgigstruct.ValidateAll(map[string]any{"owner": true}, owner())
+		if 1 != len(verrs) || voxgigstruct.VE_ONE != verrs[0].Code ||
+			!strings.Contains(verrs[0].Msg, "one of string, {id:number,name:string}") {
+			t.Errorf("Unexpected errors: %v", verrs)
+		}
+	})
+
+	t.Run("one-concurrent", func(t *testing.T) {
+		data := map[string]any{"owner": map[string]any{"name": "ann", "id": 1}}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := voxgigstruct.Validate(data, owner())
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if nil != err {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,269 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+// Helpers to derive validation shapes and skeleton transform
+// specifications from an OpenAPI (3.x) document.
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	vs "github.com/voxgig/struct"
+)
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// A loaded OpenAPI document, with internal references resolved.
+type Doc struct {
+	Root any
+}
+
+// An operation of the document.
+type Operation struct {
+	ID     string         // The operationId.
+	Method string         // HTTP method (lower case).
+	Path   string         // Path template.
+	Node   map[string]any // The operation object.
+	Params []any          // Path-level and operation-level parameters.
+}
+
+// Load an OpenAPI document from JSON. YAML documents should be
+// decoded by the caller and passed to New.
+func Load(data []byte) (*Doc, error) {
+	var root any
+	if err := json.Unmarshal(data, &root); nil != err {
+		return nil, err
+	}
+	return New(root)
+}
+
+// Create a document from a decoded OpenAPI node, resolving internal
+// references. Recursive schemas are left as references.
+func New(root any) (*Doc, error) {
+	if !vs.IsMap(root) {
+		return nil, vs.NewPathError(vs.ErrType, nil, nil, "Invalid OpenAPI document: not an object")
+	}
+
+	resolved, err := vs.ResolveRefs(root, &vs.RefOptions{KeepCycles: true})
+	if nil != err {
+		return nil, err
+	}
+
+	return &Doc{Root: resolved}, nil
+}
+
+// Sorted operationIds of all operations.
+func (d *Doc) OperationIDs() []string {
+	ids := []string{}
+	d.eachOperation(func(op *Operation) bool {
+		if "" != op.ID {
+			ids = append(ids, op.ID)
+		}
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// Find an operation by operationId.
+func (d *Doc) Operation(id string) (*Operation, error) {
+	var found *Operation
+	d.eachOperation(func(op *Operation) bool {
+		if id == op.ID {
+			found = op
+			return false
+		}
+		return true
+	})
+
+	if nil == found {
+		return nil, vs.NewPathError(vs.ErrNotFound, nil, nil, "Unknown operationId: %s", id)
+	}
+	return found, nil
+}
+
+// Validation shape for the JSON request body of an operation. Returns
+// nil if the operation has no JSON request body.
+func (d *Doc) RequestShape(id string) (any, error) {
+	op, err := d.Operation(id)
+	if nil != err {
+		return nil, err
+	}
+
+	schema := jsonSchema(vs.GetProp(op.Node, "requestBody"))
+	if nil == schema {
+		return nil, nil
+	}
+	return SchemaShape(schema)
+}
+
+// Validation shape for the JSON response body of an operation with
+// the given status (for example "200" or "default"). Returns nil if
+// there is no JSON response body.
+func (d *Doc) ResponseShape(id string, status string) (any, error) {
+	op, err := d.Operation(id)
+	if nil != err {
+		return nil, err
+	}
+
+	response := vs.GetPath([]string{"responses", status}, op.Node)
+	schema := jsonSchema(response)
+	if nil == schema {
+		return nil, nil
+	}
+	return SchemaShape(schema)
+}
+
+// Validation shape for the parameters of an operation, grouped by
+// location: { path: {...}, query: {...}, header: {...}, cookie: {...} }.
+func (d *Doc) ParamsShape(id string) (any, error) {
+	op, err := d.Operation(id)
+	if nil != err {
+		return nil, err
+	}
+
+	// Each location is an object schema of its parameters.
+	locations := map[string]any{}
+	for _, param := range op.Params {
+		in, _ := vs.GetProp(param, "in").(string)
+		name, _ := vs.GetProp(param, "name").(string)
+		if "" == in || "" == name {
+			continue
+		}
+
+		location, ok := locations[in].(map[string]any)
+		if !ok {
+			location = map[string]any{"type": "object", "properties": map[string]any{}, "required": []any{}}
+			locations[in] = location
+		}
+
+		var pschema any = true
+		if nil != vs.GetProp(param, "schema") {
+			pschema = vs.GetProp(param, "schema")
+		}
+		vs.SetProp(location["properties"], name, pschema)
+		if true == vs.GetProp(param, "required") {
+			location["required"] = append(location["required"].([]any), name)
+		}
+	}
+
+	shape := map[string]any{}
+	for _, in := range vs.KeysOf(locations) {
+		lshape, err := SchemaShape(locations[in])
+		if nil != err {
+			return nil, err
+		}
+		shape[in] = lshape
+	}
+
+	return shape, nil
+}
+
+// Skeleton transform specification for the JSON response body of an
+// operation with the given status. The specification copies each
+// property from a source with the same structure, and is intended as
+// a starting point for editing.
+func (d *Doc) ResponseSkeleton(id string, status string) (any, error) {
+	op, err := d.Operation(id)
+	if nil != err {
+		return nil, err
+	}
+
+	response := vs.GetPath([]string{"responses", status}, op.Node)
+	schema := jsonSchema(response)
+	if nil == schema {
+		return nil, nil
+	}
+	return SchemaSkeleton(schema, []string{}), nil
+}
+
+// Convert a JSON Schema of the document into a validation shape, as
+// for voxgigstruct.JSONSchemaToShape. Recursive references (which are
+// left in place by New) accept any value.
+func SchemaShape(schema any) (any, error) {
+	schema = vs.Walk(vs.Clone(schema), func(key *string, val any, parent any, path []string) any {
+		if vs.IsMap(val) && nil != vs.GetProp(val, "$ref") {
+			return true
+		}
+		return val
+	})
+	return vs.JSONSchemaToShape(schema)
+}
+
+// Convert a JSON Schema into a skeleton transform specification,
+// where path is the source path of the schema value.
+func SchemaSkeleton(schema any, path []string) any {
+	switch schemaType(schema) {
+	case "array":
+		items := vs.GetProp(schema, "items")
+		if "object" == schemaType(items) || "array" == schemaType(items) {
+			return []any{"`$EACH`", strings.Join(path, "."), SchemaSkeleton(items, []string{})}
+		}
+
+	case "object":
+		props := vs.GetProp(schema, "properties")
+		if vs.IsMap(props) {
+			spec := map[string]any{}
+			for _, name := range vs.KeysOf(props) {
+				spec[name] = SchemaSkeleton(vs.GetProp(props, name), append(append([]string{}, path...), name))
+			}
+			return spec
+		}
+	}
+
+	return "`$COPY`"
+}
+
+func schemaType(schema any) string {
+	if t, ok := vs.GetProp(schema, "type").(string); ok {
+		return t
+	}
+	if nil != vs.GetProp(schema, "properties") {
+		return "object"
+	}
+	if nil != vs.GetProp(schema, "items") {
+		return "array"
+	}
+	return ""
+}
+
+// The JSON schema of a request body or response object.
+func jsonSchema(body any) any {
+	content := vs.GetProp(body, "content")
+	for _, mediatype := range vs.KeysOf(content) {
+		if "application/json" == mediatype || strings.HasSuffix(mediatype, "+json") {
+			return vs.GetPath([]string{mediatype, "schema"}, content)
+		}
+	}
+	return nil
+}
+
+func (d *Doc) eachOperation(fn func(op *Operation) bool) {
+	paths := vs.GetProp(d.Root, "paths")
+	for _, path := range vs.KeysOf(paths) {
+		item := vs.GetProp(paths, path)
+		pathParams := vs.Items(vs.GetProp(item, "parameters"))
+
+		for _, method := range methods {
+			node, ok := vs.GetProp(item, method).(map[string]any)
+			if !ok {
+				continue
+			}
+
+			id, _ := node["operationId"].(string)
+
+			params := []any{}
+			for _, p := range pathParams {
+				params = append(params, p[1])
+			}
+			for _, p := range vs.Items(node["parameters"]) {
+				params = append(params, p[1])
+			}
+
+			if !fn(&Operation{ID: id, Method: method, Path: path, Node: node, Params: params}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package openapi_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	vs "github.com/voxgig/struct"
+	"github.com/voxgig/struct/openapi"
+)
+
+const petstore = `{
+  "openapi": "3.0.0",
+  "paths": {
+    "/pets/{petId}": {
+      "parameters": [
+        { "name": "petId", "in": "path", "required": true, "schema": { "type": "string" } }
+      ],
+      "get": {
+        "operationId": "getPet",
+        "parameters": [
+          { "name": "verbose", "in": "query", "schema": { "type": "boolean", "default": false } }
+        ],
+        "responses": {
+          "200": {
+            "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Pet" } } }
+          }
+        }
+      },
+      "put": {
+        "operationId": "updatePet",
+        "requestBody": {
+          "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Pet" } } }
+        },
+        "responses": { "204": {} }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["id", "name"],
+        "additionalProperties": false,
+        "properties": {
+          "id": { "type": "integer" },
+          "name": { "type": "string" },
+          "kind": { "type": "string", "default": "dog" },
+          "tags": { "type": "array", "items": { "$ref": "#/components/schemas/Tag" } }
+        }
+      },
+      "Tag": {
+        "type": "object",
+        "properties": { "label": { "type": "string" } }
+      }
+    }
+  }
+}`
+
+func TestOpenAPI(t *testing.T) {
+	doc, err := openapi.Load([]byte(petstore))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	t.Run("openapi-operations", func(t *testing.T) {
+		expected := []string{"getPet", "updatePet"}
+		if !reflect.DeepEqual(expected, doc.OperationIDs()) {
+			t.Errorf("Expected: %v, Got: %v", expected, doc.OperationIDs())
+		}
+
+		op, err := doc.Operation("updatePet")
+		if nil != err || "put" != op.Method || "/pets/{petId}" != op.Path {
+			t.Errorf("Unexpected operation: %v %v", op, err)
+		}
+
+		if _, err := doc.Operation("nope"); nil == err {
+			t.Errorf("Expected error for unknown operation")
+		}
+	})
+
+	t.Run("openapi-shapes", func(t *testing.T) {
+		shape, err := doc.RequestShape("updatePet")
+		expected := map[string]any{
+			"`$REQUIRED`": []any{"id", "name"},
+			"id":          "`$NUMBER`",
+			"name":        "`$STRING`",
+			"kind":        "dog",
+			"tags": []any{"`$CHILD`", map[string]any{
+				"`$REQUIRED`": []any{},
+				"label":       "`$STRING`",
+				"`$OPEN`":     true,
+			}},
+		}
+		if nil != err || !reflect.DeepEqual(expected, shape) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, shape, err)
+		}
+
+		out, err := vs.Validate(map[string]any{"id": 1, "name": "rex"}, shape)
+		if nil != err || "dog" != vs.GetProp(out, "kind") {
+			t.Errorf("Unexpected validation: %v %v", out, err)
+		}
+
+		for _, data := range []any{
+			map[string]any{"id": "x", "name": "rex"},
+			map[string]any{"id": 1, "name": "rex", "tags": []any{
+				map[string]any{"label": "a"}, map[string]any{"label": 2},
+			}},
+		} {
+			if _, err = vs.Validate(data, shape); nil == err {
+				t.Errorf("Expected validation error: %v", data)
+			}
+		}
+
+		params, err := doc.ParamsShape("getPet")
+		expectedParams := map[string]any{
+			"path":  map[string]any{"`$REQUIRED`": []any{"petId"}, "petId": "`$STRING`", "`$OPEN`": true},
+			"query": map[string]any{"`$REQUIRED`": []any{}, "verbose": false, "`$OPEN`": true},
+		}
+		if nil != err || !reflect.DeepEqual(expectedParams, params) {
+			t.Errorf("Expected: %v, Got: %v %v", expectedParams, params, err)
+		}
+
+		none, err := doc.RequestShape("getPet")
+		if nil != err || nil != none {
+			t.Errorf("Expected no request shape: %v %v", none, err)
+		}
+	})
+
+	t.Run("openapi-schema-parity", func(t *testing.T) {
+		for _, schema := range []any{
+			vs.GetPath("components.schemas.Pet", doc.Root),
+			map[string]any{"type": "array", "items": map[string]any{"type": "string", "pattern": "^a"}},
+			map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}},
+			map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"enum": []any{1, 2}}}},
+			map[string]any{"type": []any{"string", "null"}},
+			true,
+		} {
+			expected, eerr := vs.JSONSchemaToShape(schema)
+			shape, err := openapi.SchemaShape(schema)
+			if nil != eerr || nil != err || !reflect.DeepEqual(expected, shape) {
+				t.Errorf("Expected: %v, Got: %v %v %v", expected, shape, eerr, err)
+			}
+		}
+
+		// Recursive references accept any value.
+		rdoc, err := openapi.New(map[string]any{
+			"components": map[string]any{"schemas": map[string]any{"Node": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"next": map[string]any{"$ref": "#/components/schemas/Node"},
+				},
+			}}},
+		})
+		if nil != err {
+			t.Fatal(err)
+		}
+		shape, err := openapi.SchemaShape(vs.GetPath("components.schemas.Node", rdoc.Root))
+		if nil != err || !vs.IsMap(vs.GetProp(shape, "next")) {
+			t.Errorf("Unexpected recursive shape: %v %v", shape, err)
+		}
+
+		if _, err := openapi.SchemaShape(map[string]any{"type": 1}); !errors.Is(err, vs.ErrSpec) {
+			t.Errorf("Expected ErrSpec, Got: %v", err)
+		}
+	})
+
+	t.Run("openapi-skeleton", func(t *testing.T) {
+		spec, err := doc.ResponseSkeleton("getPet", "200")
+		expected := map[string]any{
+			"id":   "`$COPY`",
+			"name": "`$COPY`",
+			"kind": "`$COPY`",
+			"tags": []any{"`$EACH`", "tags", map[string]any{"label": "`$COPY`"}},
+		}
+		if nil != err || !reflect.DeepEqual(expected, spec) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, spec, err)
+		}
+
+		out := vs.Transform(map[string]any{
+			"id": 1, "name": "rex", "kind": "cat", "extra": true,
+			"tags": []any{map[string]any{"label": "a"}},
+		}, spec)
+		expectedOut := map[string]any{
+			"id": 1, "name": "rex", "kind": "cat",
+			"tags": []any{map[string]any{"label": "a"}},
+		}
+		if !reflect.DeepEqual(expectedOut, out) {
+			t.Errorf("Expected: %v, Got: %v", expectedOut, out)
+		}
+	})
+}
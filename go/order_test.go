@@ -0,0 +1,49 @@
+package voxgigstruct_test
+
+import (
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestOrder(t *testing.T) {
+
+	t.Run("order-pack", func(t *testing.T) {
+		// Source entries with the same key name: the last in key order wins.
+		data := map[string]any{
+			"src": map[string]any{
+				"a": map[string]any{"id": "x", "v": 1},
+				"b": map[string]any{"id": "x", "v": 2},
+				"c": map[string]any{"id": "x", "v": 3},
+				"d": map[string]any{"id": "y", "v": 4},
+			},
+		}
+		spec := map[string]any{
+			"out": map[string]any{
+				"`$PACK`": []any{"src", map[string]any{"`$KEY`": "id", "v": "`.v`"}},
+			},
+		}
+
+		first := voxgigstruct.Stringify(voxgigstruct.Transform(voxgigstruct.Clone(data), spec))
+		if "{out:{x:{v:3},y:{v:4}}}" != first {
+			t.Errorf("Unexpected output: %v", first)
+		}
+		for i := 0; i < 50; i++ {
+			out := voxgigstruct.Stringify(voxgigstruct.Transform(voxgigstruct.Clone(data), spec))
+			if first != out {
+				t.Fatalf("Expected: %v, Got: %v", first, out)
+			}
+		}
+	})
+
+	t.Run("order-items", func(t *testing.T) {
+		m := map[string]any{"c": 3, "a": 1, "b": 2, "aa": 0}
+		keys := voxgigstruct.KeysOf(m)
+		items := voxgigstruct.Items(m)
+		for i, k := range []string{"a", "aa", "b", "c"} {
+			if k != keys[i] || k != items[i][0] {
+				t.Errorf("Expected: %v, Got: %v %v", k, keys[i], items[i][0])
+			}
+		}
+	})
+}
@@ -0,0 +1,120 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Output size of a transform, stored as `$SIZE`, for
+// TransformOptions.MaxNodes, MaxBytes and Quota.
+type outputUsage struct {
+	maxNodes int
+	maxBytes int
+	nodes    int
+	bytes    int
+
+	maxAlloc int64 // Of the Quota.
+	alloc    int64
+}
+
+// Count each injected value (Modify is called after each value is
+// injected), then call the original modifier, if any.
+func (u *outputUsage) modify(modify Modify) Modify {
+	return func(val any, key any, parent any, state *Injection, current any, store any) {
+		var path []string
+		if nil != state {
+			path = _outPath(state)
+		}
+		u.add(1, _leafSize(val), path)
+		u.allocate(_allocSize(val), path)
+
+		if nil != modify {
+			modify(val, key, parent, state, current, store)
+		}
+	}
+}
+
+func (u *outputUsage) add(nodes int, bytes int, path []string) {
+	u.nodes += nodes
+	u.bytes += bytes
+	u.check(u.nodes, u.bytes, path)
+}
+
+func (u *outputUsage) allocate(alloc int64, path []string) {
+	u.alloc += alloc
+	if 0 < u.maxAlloc && u.maxAlloc < u.alloc {
+		panic(transformAbort{err: _quotaError(u.maxAlloc, path)})
+	}
+}
+
+// Stop the transform if the sizes are over the limits.
+func (u *outputUsage) check(nodes int, bytes int, path []string) {
+	if 0 < u.maxNodes && u.maxNodes < nodes {
+		panic(transformAbort{err: NewPathError(ErrLimit, append([]string{}, path...), nil,
+			"Output node limit of %d exceeded", u.maxNodes)})
+	}
+	if 0 < u.maxBytes && u.maxBytes < bytes {
+		panic(transformAbort{err: NewPathError(ErrLimit, append([]string{}, path...), nil,
+			"Output byte limit of %d exceeded", u.maxBytes)})
+	}
+}
+
+// Check that count copies of a template would fit within the limits,
+// before they are created. Injection may change the actual size, so
+// this is an estimate, and the copies are counted as injected.
+func _reserveOutput(store any, count int, template any, path []string) {
+	u, ok := GetProp(store, S_DSIZE).(*outputUsage)
+	if !ok {
+		return
+	}
+
+	nodes, bytes := 0, 0
+	_, _ = _walk(template, func(key *string, val any, parent any, path []string) (any, error) {
+		nodes++
+		bytes += _leafSize(val)
+		return val, nil
+	}, nil, nil, nil, WalkOptions{}, nil)
+
+	if 0 < len(path) {
+		path = path[1:]
+	}
+	u.check(u.nodes+count*nodes, u.bytes+count*bytes, path)
+}
+
+// Inject, converting an exceeded limit into an error.
+func _injectLimited(spec any, store any, modify Modify, current any, state *Injection) (out any, err error) {
+	defer func() {
+		if r := recover(); nil != r {
+			abort, ok := r.(transformAbort)
+			if !ok || !errors.Is(abort.err, ErrLimit) {
+				panic(r)
+			}
+			out = nil
+			err = abort.err
+		}
+	}()
+
+	return InjectDescend(spec, store, modify, current, state), nil
+}
+
+// Approximate JSON size of a value, not including children.
+func _leafSize(val any) int {
+	switch v := val.(type) {
+	case nil:
+		return 4
+	case string:
+		return len(v) + 2
+	case bool:
+		return 5
+	case int:
+		return len(strconv.Itoa(v))
+	case float64:
+		return len(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	if IsNode(val) {
+		return 2
+	}
+	return 8
+}
@@ -0,0 +1,64 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestOutput(t *testing.T) {
+
+	items := []any{}
+	for i := 0; i < 1000; i++ {
+		items = append(items, map[string]any{"v": i})
+	}
+	data := map[string]any{"items": items, "s": strings.Repeat("x", 100)}
+
+	t.Run("output-nodes", func(t *testing.T) {
+		spec := map[string]any{
+			"out": []any{"`$EACH`", "items", map[string]any{"v": "`$COPY`", "w": "W"}},
+		}
+
+		errs := voxgigstruct.ListRefCreate[any]()
+		out := voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{
+			Extra:    map[string]any{"$ERRS": errs},
+			MaxNodes: 100,
+		})
+		if nil != out || 1 != len(errs.List) || !errors.Is(errs.List[0].(error), voxgigstruct.ErrLimit) {
+			t.Errorf("Unexpected: %v %v", out, errs.List)
+		}
+
+		// Within the limit.
+		errs = voxgigstruct.ListRefCreate[any]()
+		out = voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{
+			Extra:    map[string]any{"$ERRS": errs},
+			MaxNodes: 5000,
+		})
+		if 1000 != len(out.(map[string]any)["out"].([]any)) || 0 != len(errs.List) {
+			t.Errorf("Unexpected: %v", errs.List)
+		}
+	})
+
+	t.Run("output-bytes", func(t *testing.T) {
+		spec := map[string]any{"a": "`s`", "b": "`s`", "c": "`s`"}
+
+		errs := voxgigstruct.ListRefCreate[any]()
+		out := voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{
+			Extra:    map[string]any{"$ERRS": errs},
+			MaxBytes: 250,
+		})
+		if nil != out || 1 != len(errs.List) ||
+			"Output byte limit of 250 exceeded (at c)" != errs.List[0].(error).Error() {
+			t.Errorf("Unexpected: %v %v", out, errs.List)
+		}
+
+		out = voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{MaxBytes: 400})
+		expected := map[string]any{"a": data["s"], "b": data["s"], "c": data["s"]}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Unexpected: %v", out)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+)
+
+// Function applied by WalkPair at each path of either tree, with the
+// values of both trees, and whether each tree has a value at the path
+// (a stored nil is present). Returns a control signal, as for WalkCtl.
+type WalkPairApply func(
+	path []string,
+	a any,
+	b any,
+	hasA bool,
+	hasB bool,
+) WalkControl
+
+// Walk two data structures in lockstep, depth first, applying a
+// function at each path found in either structure, before the
+// children of the path. Children are matched by key: map keys are
+// visited in sorted order, and list indexes in order. A node that is
+// in only one structure is still walked, with the other value absent.
+// Neither structure is modified.
+func WalkPair(a any, b any, apply WalkPairApply) {
+	type entry struct {
+		path []string
+		a    any
+		b    any
+		hasA bool
+		hasB bool
+	}
+
+	stack := []entry{{path: []string{}, a: a, b: b, hasA: true, hasB: true}}
+
+	for 0 < len(stack) {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		ctl := apply(top.path, top.a, top.b, top.hasA, top.hasB)
+		if WalkStop == ctl {
+			return
+		}
+		if WalkSkip == ctl {
+			continue
+		}
+
+		keys := _pairKeys(top.a, top.b)
+
+		// Push children in reverse, so they are walked in order.
+		for kI := len(keys) - 1; -1 < kI; kI-- {
+			ca, hasA := _getProp(top.a, keys[kI])
+			cb, hasB := _getProp(top.b, keys[kI])
+			stack = append(stack, entry{
+				path: _childPath(top.path, keys[kI]),
+				a:    ca,
+				b:    cb,
+				hasA: hasA,
+				hasB: hasB,
+			})
+		}
+	}
+}
+
+// The union of the keys of two values.
+func _pairKeys(a any, b any) []string {
+	if IsList(a) && IsList(b) {
+		n := NumKeys(a)
+		if n < NumKeys(b) {
+			n = NumKeys(b)
+		}
+		keys := make([]string, n)
+		for i := range keys {
+			keys[i] = StrKey(i)
+		}
+		return keys
+	}
+
+	keys := KeysOf(a)
+	if IsNode(b) {
+		seen := map[string]bool{}
+		for _, k := range keys {
+			seen[k] = true
+		}
+		for _, k := range KeysOf(b) {
+			if !seen[k] {
+				keys = append(keys, k)
+			}
+		}
+		if IsNode(a) {
+			sort.Strings(keys)
+		}
+	}
+	return keys
+}
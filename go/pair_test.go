@@ -0,0 +1,71 @@
+package voxgigstruct_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestPair(t *testing.T) {
+
+	t.Run("pair-walk", func(t *testing.T) {
+		a := map[string]any{"x": 1, "y": map[string]any{"p": 1}, "l": []any{1, 2}, "n": nil}
+		b := map[string]any{"x": 2, "z": map[string]any{"q": 2}, "l": []any{1, 2, 3}}
+
+		visits := []string{}
+		voxgigstruct.WalkPair(a, b, func(path []string, va any, vb any, hasA bool, hasB bool) voxgigstruct.WalkControl {
+			if voxgigstruct.IsNode(va) || voxgigstruct.IsNode(vb) {
+				visits = append(visits, fmt.Sprintf("%s %v %v", strings.Join(path, "."), hasA, hasB))
+			} else {
+				visits = append(visits, fmt.Sprintf("%s %v %v %v %v", strings.Join(path, "."), va, vb, hasA, hasB))
+			}
+			return voxgigstruct.WalkContinue
+		})
+
+		expected := []string{
+			" true true",
+			"l true true",
+			"l.0 1 1 true true",
+			"l.1 2 2 true true",
+			"l.2 <nil> 3 false true",
+			"n <nil> <nil> true false",
+			"x 1 2 true true",
+			"y true false",
+			"y.p 1 <nil> true false",
+			"z false true",
+			"z.q <nil> 2 false true",
+		}
+		if !reflect.DeepEqual(expected, visits) {
+			t.Errorf("Expected: %q, Got: %q", expected, visits)
+		}
+	})
+
+	t.Run("pair-equal", func(t *testing.T) {
+		// Equality with early exit.
+		equal := func(a any, b any) bool {
+			eq := true
+			voxgigstruct.WalkPair(a, b, func(path []string, va any, vb any, hasA bool, hasB bool) voxgigstruct.WalkControl {
+				if hasA != hasB || voxgigstruct.IsNode(va) != voxgigstruct.IsNode(vb) ||
+					(!voxgigstruct.IsNode(va) && va != vb) {
+					eq = false
+					return voxgigstruct.WalkStop
+				}
+				return voxgigstruct.WalkContinue
+			})
+			return eq
+		}
+
+		if !equal(map[string]any{"a": []any{1, "x"}}, map[string]any{"a": []any{1, "x"}}) {
+			t.Errorf("Expected equal")
+		}
+		if equal(map[string]any{"a": []any{1, "x"}}, map[string]any{"a": []any{1}}) {
+			t.Errorf("Expected not equal")
+		}
+		if equal(map[string]any{"a": nil}, map[string]any{}) {
+			t.Errorf("Expected not equal")
+		}
+	})
+}
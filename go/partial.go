@@ -0,0 +1,110 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Options for ClonePartial.
+type PartialOptions struct {
+	// Levels of nodes to clone (zero means all levels): 1 copies only
+	// the top node.
+	Depth int
+
+	// Clone only the subtrees at these paths. Paths are dotted (see
+	// GetPath), and a `*` part matches any key. The nodes above the
+	// subtrees are copied, so that the copy can be modified down to
+	// the subtrees.
+	Paths []string
+
+	// Omit the values that are not cloned, rather than sharing them
+	// with the original. In lists, omitted values are nil, so that
+	// indexes do not change.
+	Drop bool
+}
+
+// Clone part of a value, sharing (or dropping) the rest. Values that
+// are not cloned must not be modified in the copy, as they are the
+// values of the original. Cloning only the region that will be
+// modified avoids the cost of a full Clone of a large value.
+func ClonePartial(val any, opts PartialOptions) any {
+	p := &partialCloner{opts: opts}
+	for _, path := range opts.Paths {
+		p.patterns = append(p.patterns, _splitPath(path))
+	}
+	if 0 == len(opts.Paths) {
+		p.patterns = [][]string{{}}
+	}
+
+	out, _ := p.clone(val, []string{})
+	return out
+}
+
+type partialCloner struct {
+	opts     PartialOptions
+	patterns [][]string
+}
+
+// Clone a value at a path. Returns false if the value is dropped.
+func (p *partialCloner) clone(val any, path []string) (any, bool) {
+	inside, above := p.match(path)
+
+	if !inside && !above {
+		if p.opts.Drop {
+			return nil, false
+		}
+		return val, true
+	}
+
+	if !IsNode(val) {
+		if inside {
+			return Clone(val), true
+		}
+		// A value above a subtree is not cloned, as the subtree is
+		// missing.
+		if p.opts.Drop {
+			return nil, false
+		}
+		return val, true
+	}
+
+	// Nodes below the depth limit.
+	if 0 < p.opts.Depth && p.opts.Depth <= len(path) {
+		if p.opts.Drop {
+			return nil, false
+		}
+		return val, true
+	}
+
+	switch v := val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			if cval, keep := p.clone(child, _childPath(path, key)); keep {
+				out[key] = cval
+			}
+		}
+		return out, true
+
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			out[i], _ = p.clone(child, _childPath(path, StrKey(i)))
+		}
+		return out, true
+	}
+
+	// Other node types (such as typed lists) are cloned whole.
+	return Clone(val), true
+}
+
+// The path is inside a subtree to clone, or above one.
+func (p *partialCloner) match(path []string) (inside bool, above bool) {
+	for _, pattern := range p.patterns {
+		if len(pattern) <= len(path) {
+			if _matchPath(pattern, path[:len(pattern)]) {
+				return true, false
+			}
+		} else if _matchPath(pattern[:len(path)], path) {
+			above = true
+		}
+	}
+	return false, above
+}
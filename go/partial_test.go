@@ -0,0 +1,89 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestClonePartial(t *testing.T) {
+
+	makeVal := func() map[string]any {
+		return map[string]any{
+			"meta": map[string]any{"id": "d1", "tags": []any{"x"}},
+			"items": []any{
+				map[string]any{"sku": "a", "qty": 1},
+				map[string]any{"sku": "b", "qty": 2},
+			},
+			"big": map[string]any{"blob": []any{1, 2, 3}},
+			"n":   1,
+		}
+	}
+
+	same := func(a, b any) bool {
+		return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+	}
+
+	t.Run("partial-depth", func(t *testing.T) {
+		val := makeVal()
+		out := voxgigstruct.ClonePartial(val, voxgigstruct.PartialOptions{Depth: 1}).(map[string]any)
+
+		if !reflect.DeepEqual(val, out) {
+			t.Errorf("Expected: %v, Got: %v", val, out)
+		}
+		if same(val, out) || !same(val["meta"], out["meta"]) {
+			t.Errorf("Expected: %v, Got: %v", "copied top, shared children", out)
+		}
+
+		out = voxgigstruct.ClonePartial(val, voxgigstruct.PartialOptions{Depth: 2}).(map[string]any)
+		if same(val["meta"], out["meta"]) || !same(val["meta"].(map[string]any)["tags"],
+			out["meta"].(map[string]any)["tags"]) {
+			t.Errorf("Expected: %v, Got: %v", "copied two levels", out)
+		}
+
+		out = voxgigstruct.ClonePartial(val, voxgigstruct.PartialOptions{Depth: 1, Drop: true}).(map[string]any)
+		if !reflect.DeepEqual(map[string]any{"n": 1}, out) {
+			t.Errorf("Expected: %v, Got: %v", map[string]any{"n": 1}, out)
+		}
+	})
+
+	t.Run("partial-paths", func(t *testing.T) {
+		val := makeVal()
+		out := voxgigstruct.ClonePartial(val, voxgigstruct.PartialOptions{
+			Paths: []string{"items.*.qty", "meta"},
+		}).(map[string]any)
+
+		if !reflect.DeepEqual(val, out) {
+			t.Errorf("Expected: %v, Got: %v", val, out)
+		}
+
+		// The region can be modified without changing the original.
+		voxgigstruct.SetPath("items.0.qty", out, 9)
+		voxgigstruct.SetPath("meta.tags.0", out, "y")
+		if 1 != voxgigstruct.GetPath("items.0.qty", val) || "x" != voxgigstruct.GetPath("meta.tags.0", val) {
+			t.Errorf("Expected: %v, Got: %v", "original unchanged", val)
+		}
+		if !same(val["big"], out["big"]) {
+			t.Errorf("Expected: %v, Got: %v", "shared big", out)
+		}
+
+		out = voxgigstruct.ClonePartial(val, voxgigstruct.PartialOptions{
+			Paths: []string{"items.1.sku"},
+			Drop:  true,
+		}).(map[string]any)
+		expected := map[string]any{"items": []any{nil, map[string]any{"sku": "b"}}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("partial-scalar", func(t *testing.T) {
+		if 1 != voxgigstruct.ClonePartial(1, voxgigstruct.PartialOptions{Depth: 1}) {
+			t.Errorf("Expected: %v, Got: %v", 1, "other")
+		}
+		if nil != voxgigstruct.ClonePartial(nil, voxgigstruct.PartialOptions{}) {
+			t.Errorf("Expected: %v, Got: %v", nil, "other")
+		}
+	})
+}
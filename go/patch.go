@@ -0,0 +1,259 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Patch operation names (see also S_add, S_remove, S_replace).
+const (
+	S_move = "move"
+	S_copy = "copy"
+	S_test = "test"
+)
+
+// Apply a JSON Patch (RFC 6902) to a store. The patch is a list of
+// operations, each a map with the properties `op` (add, remove,
+// replace, move, copy or test), `path` (a JSON Pointer), and `from`
+// or `value` as required by the operation. The store is not modified:
+// the patch is applied to a clone, and either all operations are
+// applied or an error is returned (ErrNotFound for missing values,
+// ErrIndexRange for invalid list indexes, and ErrSpec for invalid
+// operations and failed tests).
+func ApplyPatch(store any, patch []any) (any, error) {
+	doc := Clone(store)
+
+	for oI, opdef := range patch {
+		if !IsMap(opdef) {
+			return store, NewPathError(ErrSpec, nil, nil,
+				"Invalid patch operation %d: not a map", oI)
+		}
+
+		op, _ := GetProp(opdef, "op").(string)
+		ptr, ok := GetProp(opdef, "path").(string)
+		if !ok {
+			return store, NewPathError(ErrSpec, nil, nil,
+				"Invalid patch operation %d: missing path", oI)
+		}
+
+		parts, err := PointerToPath(ptr)
+		if nil != err {
+			return store, err
+		}
+
+		var from []string
+		if S_move == op || S_copy == op {
+			fptr, ok := GetProp(opdef, "from").(string)
+			if !ok {
+				return store, NewPathError(ErrSpec, nil, nil,
+					"Invalid patch operation %d: missing from", oI)
+			}
+			if from, err = PointerToPath(fptr); nil != err {
+				return store, err
+			}
+		}
+
+		value, hasValue := _getProp(opdef, "value")
+		if !hasValue && (S_add == op || S_replace == op || S_test == op) {
+			return store, NewPathError(ErrSpec, nil, nil,
+				"Invalid patch operation %d: missing value", oI)
+		}
+
+		switch op {
+		case S_add:
+			doc, err = _patchAdd(doc, parts, Clone(value))
+
+		case S_remove:
+			doc, _, err = _patchRemove(doc, parts)
+
+		case S_replace:
+			if doc, _, err = _patchRemove(doc, parts); nil == err {
+				doc, err = _patchAdd(doc, parts, Clone(value))
+			}
+
+		case S_move:
+			if _isPathPrefix(from, parts) && len(from) < len(parts) {
+				err = NewPathError(ErrSpec, parts, nil,
+					"Cannot move a value into itself: %s", ptr)
+				break
+			}
+			var val any
+			if doc, val, err = _patchRemove(doc, from); nil == err {
+				doc, err = _patchAdd(doc, parts, val)
+			}
+
+		case S_copy:
+			var val any
+			if val, err = _patchGet(doc, from); nil == err {
+				doc, err = _patchAdd(doc, parts, Clone(val))
+			}
+
+		case S_test:
+			var val any
+			if val, err = _patchGet(doc, parts); nil == err && !_jsonEqual(val, value) {
+				err = NewPathError(ErrSpec, parts, nil, "Patch test failed: %s", ptr)
+			}
+
+		default:
+			err = NewPathError(ErrSpec, nil, nil,
+				"Invalid patch operation %d: unknown op: %v", oI, op)
+		}
+
+		if nil != err {
+			return store, err
+		}
+	}
+
+	return doc, nil
+}
+
+// Get an existing value. JSON Pointers do not allow negative list
+// indexes, so list keys must be valid indexes.
+func _patchGet(doc any, parts []string) (any, error) {
+	val := doc
+	for pI, part := range parts {
+		if IsList(val) && (!_isIndex(part) || NumKeys(val) <= _atoi(part)) {
+			return nil, NewPathError(ErrIndexRange, parts[:pI+1], nil, "Invalid list index")
+		}
+		var found bool
+		if val, found = _getProp(val, part); !found {
+			return nil, NewPathError(ErrNotFound, parts[:pI+1], nil, "No value at path")
+		}
+	}
+	return val, nil
+}
+
+// Add a value: list values are inserted (`-` appends), and map
+// values are set, replacing any existing value.
+func _patchAdd(doc any, parts []string, val any) (any, error) {
+	if 0 == len(parts) {
+		return val, nil
+	}
+
+	pparts := parts[:len(parts)-1]
+	key := parts[len(parts)-1]
+
+	parent, err := _patchGet(doc, pparts)
+	if nil != err {
+		return doc, err
+	}
+
+	if IsMap(parent) {
+		parent.(map[string]any)[key] = val
+
+	} else if IsList(parent) {
+		list := _listify(parent)
+		index := len(list)
+		if "-" != key {
+			if !_isIndex(key) || len(list) < _atoi(key) {
+				return doc, NewPathError(ErrIndexRange, parts, nil, "Invalid list index")
+			}
+			index = _atoi(key)
+		}
+
+		nlist := make([]any, 0, len(list)+1)
+		nlist = append(nlist, list[:index]...)
+		nlist = append(nlist, val)
+		parent = append(nlist, list[index:]...)
+
+	} else {
+		return doc, NewPathError(ErrType, pparts, nil, "Cannot add to %s", Typify(parent))
+	}
+
+	return _patchReplaceParent(doc, pparts, parent), nil
+}
+
+// Remove an existing value, returning the removed value.
+func _patchRemove(doc any, parts []string) (any, any, error) {
+	val, err := _patchGet(doc, parts)
+	if nil != err {
+		return doc, nil, err
+	}
+
+	if 0 == len(parts) {
+		return nil, val, nil
+	}
+
+	pparts := parts[:len(parts)-1]
+	key := parts[len(parts)-1]
+	parent, _ := _patchGet(doc, pparts)
+
+	if IsMap(parent) {
+		delete(parent.(map[string]any), key)
+	} else {
+		list := _listify(parent)
+		index := _atoi(key)
+		nlist := make([]any, 0, len(list)-1)
+		nlist = append(nlist, list[:index]...)
+		parent = append(nlist, list[index+1:]...)
+	}
+
+	return _patchReplaceParent(doc, pparts, parent), val, nil
+}
+
+// Put an updated parent node back into the document, as list
+// references are not stable in Go.
+func _patchReplaceParent(doc any, pparts []string, parent any) any {
+	if 0 == len(pparts) {
+		return parent
+	}
+	return SetPath(pparts, doc, parent)
+}
+
+// Equal as JSON values, so that numbers of different Go types compare
+// by value.
+func _jsonEqual(a any, b any) bool {
+	if _equal(a, b) {
+		return true
+	}
+	ab, aerr := json.Marshal(a)
+	bb, berr := json.Marshal(b)
+	return nil == aerr && nil == berr && string(ab) == string(bb)
+}
+
+func _isPathPrefix(prefix []string, path []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for pI, part := range prefix {
+		if part != path[pI] {
+			return false
+		}
+	}
+	return true
+}
+
+func _atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
+// Apply a JSON Merge Patch (RFC 7386) to a target. Map patches are
+// merged recursively, where a nil value deletes the key. Any other
+// patch value (including lists) replaces the target. A map target is
+// modified in place (as with Merge); the patch is not modified. The
+// output of TransformOptions.Previous is a merge patch against the
+// previous output.
+func MergePatch(target any, patch any) any {
+	if !IsMap(patch) {
+		return Clone(patch)
+	}
+
+	if !IsMap(target) {
+		target = map[string]any{}
+	}
+
+	tm := target.(map[string]any)
+	for _, k := range KeysOf(patch) {
+		pv := patch.(map[string]any)[k]
+		if nil == pv {
+			delete(tm, k)
+		} else {
+			tm[k] = MergePatch(tm[k], pv)
+		}
+	}
+
+	return tm
+}
@@ -0,0 +1,129 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestPatch(t *testing.T) {
+
+	t.Run("patch-apply", func(t *testing.T) {
+		store := map[string]any{
+			"a": map[string]any{"b": 1, "c": []any{1, 2, 3}},
+			"d": "x",
+		}
+
+		result, err := voxgigstruct.ApplyPatch(store, []any{
+			map[string]any{"op": "add", "path": "/a/c/1", "value": 9},
+			map[string]any{"op": "add", "path": "/a/c/-", "value": 4},
+			map[string]any{"op": "remove", "path": "/a/c/0"},
+			map[string]any{"op": "replace", "path": "/a/b", "value": nil},
+			map[string]any{"op": "move", "from": "/d", "path": "/e"},
+			map[string]any{"op": "copy", "from": "/a/c", "path": "/f"},
+			map[string]any{"op": "test", "path": "/e", "value": "x"},
+			map[string]any{"op": "test", "path": "/f/0", "value": float64(9)},
+		})
+
+		expected := map[string]any{
+			"a": map[string]any{"b": nil, "c": []any{9, 2, 3, 4}},
+			"e": "x",
+			"f": []any{9, 2, 3, 4},
+		}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result, err)
+		}
+
+		// The original store is not modified.
+		if !reflect.DeepEqual([]any{1, 2, 3}, store["a"].(map[string]any)["c"]) || "x" != store["d"] {
+			t.Errorf("Store was modified: %v", store)
+		}
+
+		result, err = voxgigstruct.ApplyPatch(store, []any{
+			map[string]any{"op": "replace", "path": "", "value": []any{1}},
+		})
+		if nil != err || !reflect.DeepEqual([]any{1}, result) {
+			t.Errorf("Unexpected: %v %v", result, err)
+		}
+	})
+
+	t.Run("patch-errors", func(t *testing.T) {
+		store := map[string]any{"a": []any{1}, "b": map[string]any{"c": 1}}
+
+		checks := []struct {
+			op   map[string]any
+			kind error
+		}{
+			{map[string]any{"op": "remove", "path": "/x"}, voxgigstruct.ErrNotFound},
+			{map[string]any{"op": "add", "path": "/x/y", "value": 1}, voxgigstruct.ErrNotFound},
+			{map[string]any{"op": "add", "path": "/a/2", "value": 1}, voxgigstruct.ErrIndexRange},
+			{map[string]any{"op": "replace", "path": "/a/-1", "value": 1}, voxgigstruct.ErrIndexRange},
+			{map[string]any{"op": "test", "path": "/b/c", "value": 2}, voxgigstruct.ErrSpec},
+			{map[string]any{"op": "move", "from": "/b", "path": "/b/d"}, voxgigstruct.ErrSpec},
+			{map[string]any{"op": "add", "path": "/a"}, voxgigstruct.ErrSpec},
+			{map[string]any{"op": "nope", "path": "/a"}, voxgigstruct.ErrSpec},
+			{map[string]any{"op": "add", "path": "a", "value": 1}, voxgigstruct.ErrSpec},
+		}
+
+		for _, check := range checks {
+			result, err := voxgigstruct.ApplyPatch(store, []any{
+				map[string]any{"op": "add", "path": "/z", "value": 1},
+				check.op,
+			})
+			if !errors.Is(err, check.kind) {
+				t.Errorf("%v: Expected: %v, Got: %v", check.op, check.kind, err)
+			}
+			if !reflect.DeepEqual(store, result) {
+				t.Errorf("%v: Expected original store, Got: %v", check.op, result)
+			}
+		}
+	})
+
+	t.Run("patch-merge", func(t *testing.T) {
+		// Examples from RFC 7386, appendix A.
+		checks := []struct {
+			target   any
+			patch    any
+			expected any
+		}{
+			{map[string]any{"a": "b"}, map[string]any{"a": "c"}, map[string]any{"a": "c"}},
+			{map[string]any{"a": "b"}, map[string]any{"b": "c"}, map[string]any{"a": "b", "b": "c"}},
+			{map[string]any{"a": "b"}, map[string]any{"a": nil}, map[string]any{}},
+			{map[string]any{"a": "b", "b": "c"}, map[string]any{"a": nil}, map[string]any{"b": "c"}},
+			{map[string]any{"a": []any{"b"}}, map[string]any{"a": "c"}, map[string]any{"a": "c"}},
+			{map[string]any{"a": "c"}, map[string]any{"a": []any{"b"}}, map[string]any{"a": []any{"b"}}},
+			{map[string]any{"a": map[string]any{"b": "c"}},
+				map[string]any{"a": map[string]any{"b": "d", "c": nil}},
+				map[string]any{"a": map[string]any{"b": "d"}}},
+			{map[string]any{"a": []any{map[string]any{"b": "c"}}},
+				map[string]any{"a": []any{1}}, map[string]any{"a": []any{1}}},
+			{[]any{"a", "b"}, []any{"c", "d"}, []any{"c", "d"}},
+			{map[string]any{"a": "b"}, []any{"c"}, []any{"c"}},
+			{map[string]any{"a": "foo"}, nil, nil},
+			{map[string]any{"a": "foo"}, "bar", "bar"},
+			{map[string]any{"e": nil}, map[string]any{"a": 1}, map[string]any{"e": nil, "a": 1}},
+			{[]any{1, 2}, map[string]any{"a": "b", "c": nil}, map[string]any{"a": "b"}},
+			{map[string]any{}, map[string]any{"a": map[string]any{"bb": map[string]any{"ccc": nil}}},
+				map[string]any{"a": map[string]any{"bb": map[string]any{}}}},
+		}
+
+		for _, check := range checks {
+			result := voxgigstruct.MergePatch(check.target, check.patch)
+			if !reflect.DeepEqual(check.expected, result) {
+				t.Errorf("%v + %v: Expected: %v, Got: %v", check.target, check.patch, check.expected, result)
+			}
+		}
+
+		// Transform output with Previous is a merge patch.
+		spec := map[string]any{"x": "`a`", "y": "`b`"}
+		prev := voxgigstruct.Transform(map[string]any{"a": 1, "b": 2}, spec)
+		patch := voxgigstruct.TransformWith(map[string]any{"a": 1, "b": 3}, spec,
+			&voxgigstruct.TransformOptions{Previous: prev})
+		result := voxgigstruct.MergePatch(voxgigstruct.Clone(prev), patch)
+		if !reflect.DeepEqual(map[string]any{"x": 1, "y": 3}, result) {
+			t.Errorf("Unexpected: %v (patch %v)", result, patch)
+		}
+	})
+}
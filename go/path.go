@@ -0,0 +1,42 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// A parsed key path, created by CompilePath. A Path is immutable, and
+// can be passed to GetPath, SetPath, DelPath, and HasPath in place of
+// a path string, so that a path used many times is only parsed once.
+type Path struct {
+	src   string
+	parts []string
+	wild  []bool // Wildcard parts (see _pathWildcards).
+}
+
+// Parse a dotted path string (see GetPath) into a Path. Paths with
+// empty keys (other than a leading dot for relative paths), such as
+// `a..b` or `a.`, are invalid.
+func CompilePath(path string) (*Path, error) {
+	if S_MT == path {
+		return &Path{src: path, parts: []string{S_MT}}, nil
+	}
+
+	parts := _splitPath(path)
+	for pI, part := range parts {
+		if S_MT == part && 0 < pI {
+			return nil, NewPathError(ErrSpec, nil, nil, "Invalid path (empty key at position %d): %s", pI, path)
+		}
+	}
+
+	return &Path{src: path, parts: parts, wild: _stringWildcards(path)}, nil
+}
+
+// The original path string.
+func (p *Path) String() string {
+	return p.src
+}
+
+// A copy of the path keys.
+func (p *Path) Parts() []string {
+	parts := make([]string, len(p.parts))
+	copy(parts, p.parts)
+	return parts
+}
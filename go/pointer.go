@@ -0,0 +1,128 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strings"
+)
+
+// JSON Pointer (RFC 6901) support. A pointer is a string of the form
+// `/a/b/0`, where `~1` escapes `/` and `~0` escapes `~` in keys. The
+// empty pointer refers to the whole document.
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Convert a JSON Pointer into a list of path keys.
+func PointerToPath(ptr string) ([]string, error) {
+	if S_MT == ptr {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, NewPathError(ErrSpec, nil, nil, "Invalid JSON Pointer (must start with /): %s", ptr)
+	}
+
+	parts := strings.Split(ptr[1:], "/")
+	for pI, part := range parts {
+		for cI := 0; cI < len(part); cI++ {
+			if '~' == part[cI] && (len(part) == cI+1 || ('0' != part[cI+1] && '1' != part[cI+1])) {
+				return nil, NewPathError(ErrSpec, nil, nil, "Invalid JSON Pointer (bad escape): %s", ptr)
+			}
+		}
+		parts[pI] = pointerUnescaper.Replace(part)
+	}
+
+	return parts, nil
+}
+
+// Convert a path (dotted string or list of keys) into a JSON Pointer.
+func PathToPointer(path any) string {
+	parts, ok := _pathParts(path)
+	if !ok || 0 == len(parts) || (1 == len(parts) && S_MT == parts[0]) {
+		return S_MT
+	}
+
+	var sb strings.Builder
+	for _, part := range parts {
+		sb.WriteString("/")
+		sb.WriteString(pointerEscaper.Replace(part))
+	}
+	return sb.String()
+}
+
+// Get the value referenced by a JSON Pointer. Missing values are nil;
+// an error is only returned for an invalid pointer.
+func GetPointer(ptr string, store any) (any, error) {
+	val, _, err := _getPointer(ptr, store)
+	return val, err
+}
+
+// Get the value referenced by a JSON Pointer, also reporting if it is
+// present, so that a null value can be distinguished from a missing
+// one.
+func _getPointer(ptr string, store any) (any, bool, error) {
+	parts, err := PointerToPath(ptr)
+	if nil != err {
+		return nil, false, err
+	}
+
+	val := store
+	found := true
+	for _, part := range parts {
+		if IsList(val) && !_isIndex(part) {
+			return nil, false, nil
+		}
+		val, found = _getProp(val, part)
+		if !found {
+			return nil, false, nil
+		}
+	}
+
+	return val, found, nil
+}
+
+// Set the value referenced by a JSON Pointer, using the rules of
+// `setpath`. The key `-` appends to a list. Returns the (possibly new)
+// store.
+func SetPointer(ptr string, store any, val any) (any, error) {
+	parts, err := PointerToPath(ptr)
+	if nil != err {
+		return store, err
+	}
+
+	if 0 == len(parts) {
+		return val, nil
+	}
+
+	// Resolve `-` list keys to the end of the list.
+	node := store
+	for pI, part := range parts {
+		if IsList(node) {
+			size := len(_listify(node))
+			if "-" == part {
+				parts[pI] = StrKey(size)
+			} else if !_isIndex(part) {
+				return store, NewPathError(ErrIndexRange, parts[:pI+1], nil, "Invalid list index in JSON Pointer: %s", ptr)
+			}
+		}
+		node = GetProp(node, parts[pI])
+	}
+
+	return SetPath(parts, store, val), nil
+}
+
+// A JSON Pointer list index: zero, or digits without a leading zero.
+func _isIndex(part string) bool {
+	if S_MT == part || (1 < len(part) && '0' == part[0]) {
+		return false
+	}
+	for _, c := range part {
+		if c < '0' || '9' < c {
+			return false
+		}
+	}
+	return true
+}
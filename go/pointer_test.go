@@ -0,0 +1,78 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestPointer(t *testing.T) {
+
+	t.Run("pointer-convert", func(t *testing.T) {
+		path, err := voxgigstruct.PointerToPath("/a~1b/c~0d/0")
+		expected := []string{"a/b", "c~d", "0"}
+		if nil != err || !reflect.DeepEqual(expected, path) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, path, err)
+		}
+
+		if ptr := voxgigstruct.PathToPointer(expected); "/a~1b/c~0d/0" != ptr {
+			t.Errorf("Expected: %v, Got: %v", "/a~1b/c~0d/0", ptr)
+		}
+
+		if ptr := voxgigstruct.PathToPointer("a.b"); "/a/b" != ptr {
+			t.Errorf("Expected: %v, Got: %v", "/a/b", ptr)
+		}
+
+		if ptr := voxgigstruct.PathToPointer(""); "" != ptr {
+			t.Errorf("Expected empty pointer, Got: %v", ptr)
+		}
+
+		for _, bad := range []string{"a", "/a~", "/a~2"} {
+			if _, err := voxgigstruct.PointerToPath(bad); nil == err {
+				t.Errorf("Expected error for: %v", bad)
+			}
+		}
+	})
+
+	t.Run("pointer-get", func(t *testing.T) {
+		store := map[string]any{"a/b": []any{1, map[string]any{"c": 2}}, "": 3}
+
+		checks := map[string]any{
+			"":          store,
+			"/a~1b/0":   1,
+			"/a~1b/1/c": 2,
+			"/":         3,
+			"/x":        nil,
+			"/a~1b/-1":  nil,
+			"/a~1b/01":  nil,
+		}
+
+		for ptr, expected := range checks {
+			result, err := voxgigstruct.GetPointer(ptr, store)
+			if nil != err || !reflect.DeepEqual(expected, result) {
+				t.Errorf("Pointer: %v, Expected: %v, Got: %v %v", ptr, expected, result, err)
+			}
+		}
+	})
+
+	t.Run("pointer-set", func(t *testing.T) {
+		store := map[string]any{"a": []any{1}}
+
+		result, err := voxgigstruct.SetPointer("/a/-", store, 2)
+		expected := map[string]any{"a": []any{1, 2}}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result, err)
+		}
+
+		result, err = voxgigstruct.SetPointer("/b/c~1d", result, true)
+		expected = map[string]any{"a": []any{1, 2}, "b": map[string]any{"c/d": true}}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result, err)
+		}
+
+		if _, err = voxgigstruct.SetPointer("/a/x", result, 1); nil == err {
+			t.Errorf("Expected error for invalid list index")
+		}
+	})
+}
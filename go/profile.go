@@ -0,0 +1,140 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+	"strings"
+)
+
+// Maximum number of distinct values tracked per path.
+const ProfileMaxDistinct = 1000
+
+// Maximum number of example values kept per path.
+const ProfileMaxExamples = 3
+
+// Aggregated statistics for a set of documents, by path. List
+// indexes are replaced by `*` so that all elements of a list are
+// profiled together.
+type Report struct {
+	Docs  int                   // Number of documents profiled.
+	Paths map[string]*PathStats // Statistics for each path.
+}
+
+// Statistics for a single path.
+type PathStats struct {
+	Path     string         // Dotted path, list indexes as `*`.
+	Count    int            // Number of occurrences (over all list elements).
+	Docs     int            // Number of documents containing the path.
+	Nulls    int            // Number of nil values.
+	Types    map[string]int // Occurrences of each type (see Typify).
+	Distinct int            // Number of distinct scalar values (capped).
+	Examples []any          // Some distinct example scalar values.
+
+	seen map[string]bool
+	doc  int
+}
+
+// Fraction of occurrences that are nil.
+func (ps *PathStats) NullRate() float64 {
+	if 0 == ps.Count {
+		return 0
+	}
+	return float64(ps.Nulls) / float64(ps.Count)
+}
+
+// Sorted paths of the report.
+func (r *Report) Keys() []string {
+	keys := make([]string, 0, len(r.Paths))
+	for k := range r.Paths {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Profile a list of documents, aggregating which paths occur, their
+// types, cardinality, null rates, and example values.
+func Profile(docs []any) *Report {
+	report := &Report{
+		Paths: map[string]*PathStats{},
+	}
+
+	for _, doc := range docs {
+		ProfileAdd(report, doc)
+	}
+
+	return report
+}
+
+// Add a single document to an existing profile report. This allows
+// documents to be profiled as they arrive.
+func ProfileAdd(report *Report, doc any) *Report {
+	if nil == report {
+		report = &Report{}
+	}
+	if nil == report.Paths {
+		report.Paths = map[string]*PathStats{}
+	}
+
+	report.Docs++
+	docnum := report.Docs
+
+	// Walk a copy, as walking may update nodes in place.
+	cdoc := Clone(doc)
+
+	Walk(cdoc, func(key *string, val any, parent any, path []string) any {
+		// The document itself has no path.
+		if nil == key {
+			return val
+		}
+
+		parts := make([]string, len(path))
+		var ancestor any = cdoc
+		for pI, part := range path {
+			if IsList(ancestor) {
+				parts[pI] = "*"
+			} else {
+				parts[pI] = part
+			}
+			ancestor = GetProp(ancestor, part)
+		}
+		pathstr := strings.Join(parts, S_DT)
+
+		ps, ok := report.Paths[pathstr]
+		if !ok {
+			ps = &PathStats{
+				Path:  pathstr,
+				Types: map[string]int{},
+				seen:  map[string]bool{},
+			}
+			report.Paths[pathstr] = ps
+		}
+
+		ps.Count++
+		if ps.doc != docnum {
+			ps.doc = docnum
+			ps.Docs++
+		}
+
+		ps.Types[Typify(val)]++
+
+		if nil == val {
+			ps.Nulls++
+
+		} else if !IsNode(val) && !IsFunc(val) {
+			vstr := Typify(val) + S_CN + Stringify(val)
+			if !ps.seen[vstr] && len(ps.seen) < ProfileMaxDistinct {
+				ps.seen[vstr] = true
+				ps.Distinct++
+				if len(ps.Examples) < ProfileMaxExamples {
+					ps.Examples = append(ps.Examples, val)
+				}
+			}
+		}
+
+		return val
+	})
+
+	return report
+}
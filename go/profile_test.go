@@ -0,0 +1,62 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestProfile(t *testing.T) {
+
+	t.Run("profile-basic", func(t *testing.T) {
+		docs := []any{
+			map[string]any{"a": 1, "b": []any{map[string]any{"c": "x"}, map[string]any{"c": "y"}}},
+			map[string]any{"a": "A", "d": nil},
+			map[string]any{"a": 1, "b": []any{}},
+		}
+
+		report := voxgigstruct.Profile(docs)
+
+		if 3 != report.Docs {
+			t.Errorf("Expected: %v, Got: %v", 3, report.Docs)
+		}
+
+		expectedKeys := []string{"a", "b", "b.*", "b.*.c", "d"}
+		if !reflect.DeepEqual(expectedKeys, report.Keys()) {
+			t.Errorf("Expected: %v, Got: %v", expectedKeys, report.Keys())
+		}
+
+		a := report.Paths["a"]
+		if 3 != a.Count || 3 != a.Docs || 2 != a.Distinct {
+			t.Errorf("Unexpected stats for a: %+v", a)
+		}
+		expectedTypes := map[string]int{"number": 2, "string": 1}
+		if !reflect.DeepEqual(expectedTypes, a.Types) {
+			t.Errorf("Expected: %v, Got: %v", expectedTypes, a.Types)
+		}
+		expectedExamples := []any{1, "A"}
+		if !reflect.DeepEqual(expectedExamples, a.Examples) {
+			t.Errorf("Expected: %v, Got: %v", expectedExamples, a.Examples)
+		}
+
+		c := report.Paths["b.*.c"]
+		if 2 != c.Count || 1 != c.Docs {
+			t.Errorf("Unexpected stats for b.*.c: %+v", c)
+		}
+
+		d := report.Paths["d"]
+		if 1 != d.Nulls || 1.0 != d.NullRate() {
+			t.Errorf("Unexpected stats for d: %+v", d)
+		}
+	})
+
+	t.Run("profile-add", func(t *testing.T) {
+		report := voxgigstruct.ProfileAdd(nil, map[string]any{"a": 1})
+		voxgigstruct.ProfileAdd(report, map[string]any{"a": 2})
+
+		if 2 != report.Docs || 2 != report.Paths["a"].Distinct {
+			t.Errorf("Unexpected report: %+v", report.Paths["a"])
+		}
+	})
+}
@@ -0,0 +1,11 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Get a property of a node, as for GetProp, also reporting if the key
+// is present. A stored nil value is returned as (nil, true), and a
+// missing key (or nil argument) as (nil, false). There is no
+// alternative value: use the found flag to choose a default.
+func GetPropOk(val any, key any) (any, bool) {
+	return _getProp(val, key)
+}
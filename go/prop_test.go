@@ -0,0 +1,42 @@
+package voxgigstruct_test
+
+import (
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestProp(t *testing.T) {
+
+	t.Run("prop-ok", func(t *testing.T) {
+		val := map[string]any{"a": 1, "n": nil, "l": []any{nil, 2}}
+
+		checks := []struct {
+			val   any
+			key   any
+			out   any
+			found bool
+		}{
+			{val, "a", 1, true},
+			{val, "n", nil, true},
+			{val, "x", nil, false},
+			{val["l"], 0, nil, true},
+			{val["l"], -1, 2, true},
+			{val["l"], 2, nil, false},
+			{nil, "a", nil, false},
+			{val, nil, nil, false},
+		}
+
+		for _, check := range checks {
+			out, found := voxgigstruct.GetPropOk(check.val, check.key)
+			if check.out != out || check.found != found {
+				t.Errorf("Expected: %v %v, Got: %v %v", check.out, check.found, out, found)
+			}
+		}
+
+		// GetProp cannot distinguish a stored nil.
+		if "alt" != voxgigstruct.GetProp(val, "n", "alt") {
+			t.Errorf("Expected alt")
+		}
+	})
+}
@@ -0,0 +1,97 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// An external source of values, such as an HTTP API or a secret
+// store. Add a provider to the injection store by name (for example,
+// `{"$SECRET": provider}` in TransformOptions.Extra), and inject its
+// values with paths: the rest of the path after the provider is the
+// key, so "`$SECRET.db.password`" fetches the key `db.password`.
+type Provider interface {
+	// Fetch the value for a key, and the number of bytes fetched.
+	Fetch(key string) (val any, size int, err error)
+}
+
+// Use a function as a Provider.
+type ProviderFunc func(key string) (any, int, error)
+
+func (f ProviderFunc) Fetch(key string) (any, int, error) {
+	return f(key)
+}
+
+// Limits on the provider lookups of a single transform, so that a
+// spec cannot fan out into an unbounded number of external calls.
+// Zero means no limit.
+type ProviderBudget struct {
+	MaxCalls int // Maximum number of lookups, over all providers.
+	MaxBytes int // Maximum total bytes fetched, over all providers.
+}
+
+// The budget used by TransformWith if none is given.
+func DefaultProviderBudget() *ProviderBudget {
+	return &ProviderBudget{
+		MaxCalls: 100,
+		MaxBytes: 10 << 20,
+	}
+}
+
+// Provider lookups of a transform, stored as `$CALL`. Stores without
+// usage (such as those passed directly to Inject) have no limits.
+type providerUsage struct {
+	budget ProviderBudget
+	calls  int
+	bytes  int
+}
+
+// Fetch a provider value, within the budget of the store. Failed
+// lookups inject nil, and the error (ErrLimit if over budget) is
+// appended to the error collector.
+func _providerFetch(provider Provider, name string, key string, store any, state *Injection) any {
+	usage, _ := GetProp(store, S_DCALL).(*providerUsage)
+
+	var path []string
+	var log Logger
+	if nil != state {
+		if 0 < len(state.Path) {
+			path = state.Path[1:]
+		}
+		log = state.Log
+	}
+
+	if nil != usage && 0 < usage.budget.MaxCalls && usage.budget.MaxCalls <= usage.calls {
+		err := NewPathError(ErrLimit, path, nil,
+			"Provider call limit of %d exceeded: %s.%s", usage.budget.MaxCalls, name, key)
+		_logWarn(log, "provider limit", "provider", name, "key", key, "calls", usage.calls)
+		_providerError(state, err)
+		return nil
+	}
+
+	val, size, err := provider.Fetch(key)
+
+	if nil != usage {
+		usage.calls++
+		usage.bytes += size
+	}
+
+	if nil != err {
+		_providerError(state, NewPathError(ErrNotFound, path, err,
+			"Provider lookup failed: %s.%s", name, key))
+		return nil
+	}
+
+	if nil != usage && 0 < usage.budget.MaxBytes && usage.budget.MaxBytes < usage.bytes {
+		err := NewPathError(ErrLimit, path, nil,
+			"Provider byte limit of %d exceeded: %s.%s", usage.budget.MaxBytes, name, key)
+		_logWarn(log, "provider limit", "provider", name, "key", key, "bytes", usage.bytes)
+		_providerError(state, err)
+		return nil
+	}
+
+	return val
+}
+
+func _providerError(state *Injection, err error) {
+	if nil != state && nil != state.Errs {
+		state.Errs.Append(err)
+	}
+}
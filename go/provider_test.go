@@ -0,0 +1,111 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestProvider(t *testing.T) {
+
+	secrets := map[string]any{"db.password": "s3cret", "api.token": "t0ken"}
+
+	secret := func(calls *int) voxgigstruct.ProviderFunc {
+		return func(key string) (any, int, error) {
+			*calls++
+			val, ok := secrets[key]
+			if !ok {
+				return nil, 0, errors.New("unknown secret")
+			}
+			return val, len(val.(string)), nil
+		}
+	}
+
+	t.Run("provider-fetch", func(t *testing.T) {
+		calls := 0
+		errs := voxgigstruct.ListRefCreate[any]()
+		result := voxgigstruct.TransformWith(
+			map[string]any{"user": "alice"},
+			map[string]any{
+				"user":     "`user`",
+				"password": "`$SECRET.db.password`",
+				"dsn":      "db://`user`:`$SECRET.db.password`@host",
+				"missing":  "`$SECRET.nope`",
+			},
+			&voxgigstruct.TransformOptions{
+				Extra: map[string]any{"$SECRET": secret(&calls), "$ERRS": errs},
+			},
+		)
+
+		expected := map[string]any{
+			"user":     "alice",
+			"password": "s3cret",
+			"dsn":      "db://alice:s3cret@host",
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+		if 3 != calls {
+			t.Errorf("Unexpected calls: %d", calls)
+		}
+		if 1 != len(errs.List) || !errors.Is(errs.List[0].(error), voxgigstruct.ErrNotFound) {
+			t.Errorf("Unexpected errors: %v", errs.List)
+		}
+	})
+
+	t.Run("provider-budget", func(t *testing.T) {
+		spec := map[string]any{
+			"a": "`$SECRET.db.password`",
+			"b": "`$SECRET.api.token`",
+			"c": "`$SECRET.db.password`",
+		}
+
+		calls := 0
+		errs := voxgigstruct.ListRefCreate[any]()
+		log := &testLogger{}
+		result := voxgigstruct.TransformWith(nil, spec, &voxgigstruct.TransformOptions{
+			Extra:  map[string]any{"$SECRET": secret(&calls), "$ERRS": errs},
+			Budget: &voxgigstruct.ProviderBudget{MaxCalls: 2},
+			Logger: log,
+		})
+
+		if !reflect.DeepEqual(map[string]any{"a": "s3cret", "b": "t0ken"}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+		if 2 != calls {
+			t.Errorf("Unexpected calls: %d", calls)
+		}
+		if 1 != len(errs.List) || !errors.Is(errs.List[0].(error), voxgigstruct.ErrLimit) ||
+			"Provider call limit of 2 exceeded: $SECRET.db.password (at c)" != errs.List[0].(error).Error() {
+			t.Errorf("Unexpected errors: %v", errs.List)
+		}
+
+		// Bytes fetched are limited too.
+		calls = 0
+		errs = voxgigstruct.ListRefCreate[any]()
+		result = voxgigstruct.TransformWith(nil, spec, &voxgigstruct.TransformOptions{
+			Extra:  map[string]any{"$SECRET": secret(&calls), "$ERRS": errs},
+			Budget: &voxgigstruct.ProviderBudget{MaxBytes: 8},
+		})
+		if !reflect.DeepEqual(map[string]any{"a": "s3cret"}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+		if 2 != len(errs.List) || !errors.Is(errs.List[1].(error), voxgigstruct.ErrLimit) {
+			t.Errorf("Unexpected errors: %v", errs.List)
+		}
+
+		// The budget is per transform.
+		calls = 0
+		tr := voxgigstruct.NewTransformer(spec, &voxgigstruct.TransformOptions{
+			Extra:  map[string]any{"$SECRET": secret(&calls)},
+			Budget: &voxgigstruct.ProviderBudget{MaxCalls: 3},
+		})
+		tr.Transform(nil)
+		tr.Transform(nil)
+		if 6 != calls {
+			t.Errorf("Unexpected calls: %d", calls)
+		}
+	})
+}
@@ -0,0 +1,619 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query a node using a practical subset of JSONPath:
+//   - `$`: the root (optional, so `a.b` is the same as `$.a.b`).
+//   - `.key`, `['key']`, `["key"]`: child by key.
+//   - `[0]`, `[-1]`: list element by index (negative from the end).
+//   - `['a','b']`, `[0,2]`: union of keys or indexes.
+//   - `[1:3]`, `[::2]`: list slice (start:end:step).
+//   - `.*`, `[*]`: all children.
+//   - `..key`, `..*`: recursive descent.
+//   - `[?(@.a.b == 1 && @.c)]`: filter children by predicate, where
+//     `@.path` is resolved with getpath, comparisons are ==, !=, <,
+//     <=, >, >=, a bare operand tests for existence, and conditions
+//     can be combined with && and || (&& binds tighter).
+//
+// Matches are returned in document order (map keys are sorted). An
+// invalid expression returns nil; no matches returns an empty list.
+func Query(expr string, store any) []any {
+	matches, err := _query(expr, store)
+	if nil != err {
+		return nil
+	}
+
+	out := make([]any, len(matches))
+	for mI, m := range matches {
+		out[mI] = m.val
+	}
+	return out
+}
+
+// A value found by GetAll, with the concrete path it was found at.
+type Match struct {
+	Path  []string
+	Value any
+}
+
+// Get all the values matching a path, with their concrete paths, so
+// that matches can be updated with SetPath or DelPath. A string path
+// that is `$`, or starts with `$.` or `$[`, is a query expression (see
+// Query), which supports recursive descent and filters. Other paths
+// (including `$TOP.a`) are resolved as for GetPath, where `*` matches
+// every child. Matches are in document order; an invalid path returns
+// nil.
+func GetAll(path any, store any) []Match {
+	if expr, ok := path.(string); ok && _isQuery(expr) {
+		matches, err := _query(expr, store)
+		if nil != err {
+			return nil
+		}
+		out := make([]Match, len(matches))
+		for mI, m := range matches {
+			out[mI] = Match{Path: m.path, Value: m.val}
+		}
+		return out
+	}
+
+	parts, ok := _pathParts(path)
+	if !ok {
+		return nil
+	}
+
+	if 0 == len(parts) || (1 == len(parts) && S_MT == parts[0]) {
+		return []Match{{Path: []string{}, Value: store}}
+	}
+
+	out := []Match{}
+	for _, mpath := range _getPathMatches(store, parts, _pathWildcards(path)) {
+		out = append(out, Match{Path: mpath, Value: GetPath(mpath, store)})
+	}
+	return out
+}
+
+// A query expression starts with the root, `$`, followed by a step.
+func _isQuery(expr string) bool {
+	return S_DS == expr || strings.HasPrefix(expr, S_DS+S_DT) || strings.HasPrefix(expr, S_DS+"[")
+}
+
+type queryMatch struct {
+	path []string
+	val  any
+}
+
+type queryStep struct {
+	kind    string // One of: key, wild, slice, filter.
+	descend bool   // Apply to all descendants (`..`).
+	keys    []string
+	slice   [3]*int
+	filter  *queryFilter
+}
+
+// Filter predicate, in disjunctive normal form: any of all.
+type queryFilter struct {
+	any [][]queryCond
+}
+
+type queryCond struct {
+	left  queryOperand
+	op    string
+	right *queryOperand
+}
+
+type queryOperand struct {
+	path  *string
+	value any
+}
+
+func _query(expr string, store any) ([]queryMatch, error) {
+	steps, err := _parseQuery(expr)
+	if nil != err {
+		return nil, err
+	}
+
+	matches := []queryMatch{{path: []string{}, val: store}}
+	for _, step := range steps {
+		next := []queryMatch{}
+		for _, m := range matches {
+			if step.descend {
+				for _, d := range _queryDescendants(m) {
+					next = _queryApply(step, d, next)
+				}
+			} else {
+				next = _queryApply(step, m, next)
+			}
+		}
+		matches = next
+	}
+
+	return matches, nil
+}
+
+// The match itself, and all its descendants, in pre-order.
+func _queryDescendants(m queryMatch) []queryMatch {
+	out := []queryMatch{m}
+	for _, c := range _queryChildren(m) {
+		out = append(out, _queryDescendants(c)...)
+	}
+	return out
+}
+
+func _queryChildren(m queryMatch) []queryMatch {
+	out := []queryMatch{}
+	if IsMap(m.val) {
+		vm := m.val.(map[string]any)
+		for _, k := range KeysOf(vm) {
+			out = append(out, queryMatch{path: _childPath(m.path, k), val: vm[k]})
+		}
+	} else if IsList(m.val) {
+		for i, v := range _listify(m.val) {
+			out = append(out, queryMatch{path: _childPath(m.path, StrKey(i)), val: v})
+		}
+	}
+	return out
+}
+
+func _queryApply(step queryStep, m queryMatch, out []queryMatch) []queryMatch {
+	switch step.kind {
+	case "wild":
+		out = append(out, _queryChildren(m)...)
+
+	case "key":
+		for _, key := range step.keys {
+			if !IsNode(m.val) {
+				continue
+			}
+			child, found := _getProp(m.val, key)
+			if !found {
+				continue
+			}
+			if IsList(m.val) {
+				ki, _ := _listIndex(key, len(_listify(m.val)))
+				key = StrKey(ki)
+			}
+			out = append(out, queryMatch{path: _childPath(m.path, key), val: child})
+		}
+
+	case "slice":
+		if IsList(m.val) {
+			list := _listify(m.val)
+			size := len(list)
+			start, end, stride := 0, size, 1
+			if nil != step.slice[2] {
+				stride = *step.slice[2]
+			}
+			if stride < 0 {
+				start, end = size-1, -1
+			}
+			if nil != step.slice[0] {
+				start = _sliceBound(*step.slice[0], size, stride)
+			}
+			if nil != step.slice[1] {
+				end = _sliceBound(*step.slice[1], size, stride)
+			}
+			for i := start; (0 < stride && i < end) || (stride < 0 && end < i); i += stride {
+				if 0 <= i && i < size {
+					out = append(out, queryMatch{path: _childPath(m.path, StrKey(i)), val: list[i]})
+				}
+			}
+		}
+
+	case "filter":
+		for _, c := range _queryChildren(m) {
+			if step.filter.test(c.val) {
+				out = append(out, c)
+			}
+		}
+	}
+
+	return out
+}
+
+func _sliceBound(bound int, size int, stride int) int {
+	if bound < 0 {
+		bound = size + bound
+	}
+	if 0 < stride {
+		if bound < 0 {
+			return 0
+		}
+		if size < bound {
+			return size
+		}
+	} else {
+		if bound < -1 {
+			return -1
+		}
+		if size <= bound {
+			return size - 1
+		}
+	}
+	return bound
+}
+
+func (f *queryFilter) test(val any) bool {
+	for _, all := range f.any {
+		pass := true
+		for _, cond := range all {
+			if !cond.test(val) {
+				pass = false
+				break
+			}
+		}
+		if pass {
+			return true
+		}
+	}
+	return false
+}
+
+func (c queryCond) test(val any) bool {
+	left, lfound := c.left.resolve(val)
+	if nil == c.right {
+		return lfound && nil != left
+	}
+
+	right, rfound := c.right.resolve(val)
+
+	switch c.op {
+	case "==":
+		return lfound == rfound && _queryCompare(left, right) == 0
+	case "!=":
+		return lfound != rfound || _queryCompare(left, right) != 0
+	}
+
+	if !lfound || !rfound {
+		return false
+	}
+
+	cmp := _queryCompare(left, right)
+	if -2 == cmp {
+		return false
+	}
+
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return 0 < cmp
+	case ">=":
+		return 0 <= cmp
+	}
+
+	return false
+}
+
+func (o queryOperand) resolve(val any) (any, bool) {
+	if nil == o.path {
+		return o.value, true
+	}
+	if S_MT == *o.path {
+		return val, true
+	}
+	parts, _ := _pathParts(*o.path)
+	if !HasPath(parts, val) {
+		return nil, false
+	}
+	return GetPath(parts, val), true
+}
+
+// Compare values: numbers numerically, strings lexically, and other
+// values by equality. Returns -1, 0, 1, or -2 if not comparable.
+func _queryCompare(a any, b any) int {
+	af, aerr := _toFloat64(a)
+	bf, berr := _toFloat64(b)
+	if nil == aerr && nil == berr {
+		if af < bf {
+			return -1
+		} else if af > bf {
+			return 1
+		}
+		return 0
+	}
+
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		return strings.Compare(as, bs)
+	}
+
+	if _equal(a, b) {
+		return 0
+	}
+
+	return -2
+}
+
+func _parseQuery(expr string) ([]queryStep, error) {
+	steps := []queryStep{}
+	src := strings.TrimSpace(expr)
+
+	if strings.HasPrefix(src, S_DS) {
+		src = src[1:]
+	} else if S_MT != src && !strings.HasPrefix(src, S_DT) && !strings.HasPrefix(src, "[") {
+		src = S_DT + src
+	}
+
+	for S_MT != src {
+		descend := false
+
+		if strings.HasPrefix(src, "..") {
+			descend = true
+			src = src[2:]
+			if !strings.HasPrefix(src, "[") {
+				src = S_DT + src
+			}
+		}
+
+		if strings.HasPrefix(src, S_DT) {
+			src = src[1:]
+			end := strings.IndexAny(src, ".[")
+			if -1 == end {
+				end = len(src)
+			}
+			name := src[:end]
+			src = src[end:]
+
+			if S_MT == name {
+				return nil, NewPathError(ErrSpec, nil, nil, "Invalid query (empty key): %s", expr)
+			}
+
+			if S_ST == name {
+				steps = append(steps, queryStep{kind: "wild", descend: descend})
+			} else {
+				steps = append(steps, queryStep{kind: "key", descend: descend, keys: []string{name}})
+			}
+
+		} else if strings.HasPrefix(src, "[") {
+			end := _queryBracketEnd(src)
+			if -1 == end {
+				return nil, NewPathError(ErrSpec, nil, nil, "Invalid query (unclosed bracket): %s", expr)
+			}
+			inner := strings.TrimSpace(src[1:end])
+			src = src[end+1:]
+
+			step, err := _parseQueryBracket(inner)
+			if nil != err {
+				return nil, NewPathError(ErrSpec, nil, nil, "Invalid query (%s): %s", err.Error(), expr)
+			}
+			step.descend = descend
+			steps = append(steps, step)
+
+		} else {
+			return nil, NewPathError(ErrSpec, nil, nil, "Invalid query (unexpected %q): %s", src[:1], expr)
+		}
+	}
+
+	return steps, nil
+}
+
+// Index of the bracket closing the bracket at the start of src,
+// skipping quoted strings and nested brackets.
+func _queryBracketEnd(src string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if 0 != quote {
+			if '\\' == c {
+				i++
+			} else if quote == c {
+				quote = 0
+			}
+		} else if '\'' == c || '"' == c {
+			quote = c
+		} else if '[' == c || '(' == c {
+			depth++
+		} else if ']' == c || ')' == c {
+			depth--
+			if 0 == depth {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func _parseQueryBracket(inner string) (queryStep, error) {
+	if S_ST == inner {
+		return queryStep{kind: "wild"}, nil
+	}
+
+	if strings.HasPrefix(inner, "?") {
+		filter, err := _parseQueryFilter(_unparen(inner[1:]))
+		if nil != err {
+			return queryStep{}, err
+		}
+		return queryStep{kind: "filter", filter: filter}, nil
+	}
+
+	parts := _querySplit(inner, ",")
+
+	if 1 == len(parts) && strings.Contains(inner, S_CN) && !_isQuoted(inner) {
+		bounds := strings.Split(inner, S_CN)
+		if 3 < len(bounds) {
+			return queryStep{}, fmt.Errorf("bad slice")
+		}
+		step := queryStep{kind: "slice"}
+		for bI, bound := range bounds {
+			bound = strings.TrimSpace(bound)
+			if S_MT == bound {
+				continue
+			}
+			n, err := strconv.Atoi(bound)
+			if nil != err || (2 == bI && 0 == n) {
+				return queryStep{}, fmt.Errorf("bad slice")
+			}
+			step.slice[bI] = &n
+		}
+		return step, nil
+	}
+
+	keys := []string{}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if _isQuoted(part) {
+			keys = append(keys, _unquote(part))
+		} else if _, err := strconv.Atoi(part); nil == err {
+			keys = append(keys, part)
+		} else {
+			return queryStep{}, fmt.Errorf("bad key %s", part)
+		}
+	}
+
+	return queryStep{kind: "key", keys: keys}, nil
+}
+
+func _parseQueryFilter(src string) (*queryFilter, error) {
+	filter := &queryFilter{}
+
+	for _, anysrc := range _querySplit(src, "||") {
+		all := []queryCond{}
+		for _, condsrc := range _querySplit(anysrc, "&&") {
+			cond, err := _parseQueryCond(_unparen(condsrc))
+			if nil != err {
+				return nil, err
+			}
+			all = append(all, cond)
+		}
+		filter.any = append(filter.any, all)
+	}
+
+	return filter, nil
+}
+
+func _parseQueryCond(src string) (queryCond, error) {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		parts := _querySplit(src, op)
+		if 2 == len(parts) {
+			left, err := _parseQueryOperand(strings.TrimSpace(parts[0]))
+			if nil != err {
+				return queryCond{}, err
+			}
+			right, err := _parseQueryOperand(strings.TrimSpace(parts[1]))
+			if nil != err {
+				return queryCond{}, err
+			}
+			return queryCond{left: left, op: op, right: &right}, nil
+		}
+	}
+
+	left, err := _parseQueryOperand(src)
+	if nil != err {
+		return queryCond{}, err
+	}
+	if nil == left.path {
+		return queryCond{}, fmt.Errorf("bad filter %s", src)
+	}
+	return queryCond{left: left}, nil
+}
+
+func _parseQueryOperand(src string) (queryOperand, error) {
+	if "@" == src {
+		path := S_MT
+		return queryOperand{path: &path}, nil
+	}
+
+	if strings.HasPrefix(src, "@.") {
+		path := src[2:]
+		return queryOperand{path: &path}, nil
+	}
+
+	if _isQuoted(src) {
+		return queryOperand{value: _unquote(src)}, nil
+	}
+
+	switch src {
+	case "true":
+		return queryOperand{value: true}, nil
+	case "false":
+		return queryOperand{value: false}, nil
+	case "null":
+		return queryOperand{value: nil}, nil
+	}
+
+	if n, err := strconv.ParseFloat(src, 64); nil == err {
+		return queryOperand{value: n}, nil
+	}
+
+	return queryOperand{}, fmt.Errorf("bad operand %s", src)
+}
+
+// Split on a separator, outside of quoted strings.
+func _querySplit(src string, sep string) []string {
+	parts := []string{}
+	var quote byte
+	start := 0
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if 0 != quote {
+			if '\\' == c {
+				i++
+			} else if quote == c {
+				quote = 0
+			}
+		} else if '\'' == c || '"' == c {
+			quote = c
+		} else if strings.HasPrefix(src[i:], sep) {
+			parts = append(parts, src[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		}
+	}
+	return append(parts, src[start:])
+}
+
+// Remove the parentheses around a filter or condition, if the
+// opening one is closed at the end (outside of quoted strings).
+func _unparen(src string) string {
+	src = strings.TrimSpace(src)
+	for strings.HasPrefix(src, "(") {
+		depth := 0
+		var quote byte
+		end := -1
+		for i := 0; i < len(src) && -1 == end; i++ {
+			c := src[i]
+			if 0 != quote {
+				if '\\' == c {
+					i++
+				} else if quote == c {
+					quote = 0
+				}
+			} else if '\'' == c || '"' == c {
+				quote = c
+			} else if '(' == c {
+				depth++
+			} else if ')' == c {
+				depth--
+				if 0 == depth {
+					end = i
+				}
+			}
+		}
+		if len(src)-1 != end {
+			break
+		}
+		src = strings.TrimSpace(src[1:end])
+	}
+	return src
+}
+
+func _isQuoted(s string) bool {
+	return 2 <= len(s) &&
+		(('\'' == s[0] && '\'' == s[len(s)-1]) || ('"' == s[0] && '"' == s[len(s)-1]))
+}
+
+func _unquote(s string) string {
+	inner := s[1 : len(s)-1]
+	inner = strings.ReplaceAll(inner, "\\"+s[:1], s[:1])
+	return strings.ReplaceAll(inner, "\\\\", "\\")
+}
@@ -0,0 +1,127 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestQuery(t *testing.T) {
+
+	store := map[string]any{
+		"store": map[string]any{
+			"book": []any{
+				map[string]any{"title": "A", "price": 8.95, "tags": []any{"x"}},
+				map[string]any{"title": "B", "price": 12.99},
+				map[string]any{"title": "C", "price": 8.99, "isbn": "0-553"},
+				map[string]any{"title": "D", "price": 22.99, "isbn": "0-395"},
+			},
+			"bicycle": map[string]any{"color": "red", "price": 19.95},
+		},
+		"a.b": 1,
+	}
+
+	t.Run("query-basic", func(t *testing.T) {
+		checks := []struct {
+			expr     string
+			expected []any
+		}{
+			{"$.store.bicycle.color", []any{"red"}},
+			{"store.bicycle.color", []any{"red"}},
+			{"$.store.book[0].title", []any{"A"}},
+			{"$.store.book[-1].title", []any{"D"}},
+			{"$.store.book[*].title", []any{"A", "B", "C", "D"}},
+			{"$.store.book.*.title", []any{"A", "B", "C", "D"}},
+			{"$.store.book[0,2].title", []any{"A", "C"}},
+			{"$.store.book[1:3].title", []any{"B", "C"}},
+			{"$.store.book[::2].title", []any{"A", "C"}},
+			{"$.store.book[::-1].title", []any{"D", "C", "B", "A"}},
+			{"$.store.book[-2:].title", []any{"C", "D"}},
+			{"$['a.b']", []any{1}},
+			{"$.store['bicycle'].color", []any{"red"}},
+			{"$..price", []any{19.95, 8.95, 12.99, 8.99, 22.99}},
+			{"$..tags[0]", []any{"x"}},
+			{"$.store.book[?(@.isbn)].title", []any{"C", "D"}},
+			{"$.store.book[?(@.price < 10)].title", []any{"A", "C"}},
+			{"$.store.book[?(@.price >= 12.99 && @.isbn)].title", []any{"D"}},
+			{"$.store.book[?(@.title == 'A' || @.title == \"B\")].price", []any{8.95, 12.99}},
+			{"$.store.book[?(@.isbn != '0-553')].title", []any{"A", "B", "D"}},
+			{"$..[?(@.color == 'red')].price", []any{19.95}},
+			{"$.store.book[?(@.isbn) && (@.price < 10)].title", []any{"C"}},
+			{"$.store.book[?((@.isbn) && (@.price < 10))].title", []any{"C"}},
+			{"$.store.book[?@.title == '(A)' || @.price < 9].title", []any{"A", "C"}},
+			{"$.x.y", []any{}},
+			{"$", []any{store}},
+		}
+
+		for _, check := range checks {
+			result := voxgigstruct.Query(check.expr, store)
+			if !reflect.DeepEqual(check.expected, result) {
+				t.Errorf("Query: %v, Expected: %v, Got: %v", check.expr, check.expected, result)
+			}
+		}
+	})
+
+	t.Run("query-invalid", func(t *testing.T) {
+		for _, expr := range []string{"$.a[", "$.a[x]", "$.a[?(@.b ==)]", "$.a[1:2:0]", "$a"} {
+			if nil != voxgigstruct.Query(expr, store) {
+				t.Errorf("Expected nil for invalid query: %v", expr)
+			}
+		}
+	})
+
+	t.Run("query-getall", func(t *testing.T) {
+		result := voxgigstruct.GetAll("store.book.*.isbn", store)
+		expected := []voxgigstruct.Match{
+			{Path: []string{"store", "book", "2", "isbn"}, Value: "0-553"},
+			{Path: []string{"store", "book", "3", "isbn"}, Value: "0-395"},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.GetAll("$..book[?(@.price < 9)].title", store)
+		expected = []voxgigstruct.Match{
+			{Path: []string{"store", "book", "0", "title"}, Value: "A"},
+			{Path: []string{"store", "book", "2", "title"}, Value: "C"},
+		}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		// Concrete paths can be used to edit the matches.
+		var edited any = voxgigstruct.Clone(store)
+		for _, m := range result {
+			edited = voxgigstruct.SetPath(m.Path, edited, "cheap")
+		}
+		if titles := voxgigstruct.Query("$.store.book[*].title", edited); !reflect.DeepEqual(
+			[]any{"cheap", "B", "cheap", "D"}, titles) {
+			t.Errorf("Unexpected: %v", titles)
+		}
+
+		if result := voxgigstruct.GetAll("", 1); !reflect.DeepEqual(
+			[]voxgigstruct.Match{{Path: []string{}, Value: 1}}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+
+		if result := voxgigstruct.GetAll("$.a[", store); nil != result {
+			t.Errorf("Expected nil for invalid query: %v", result)
+		}
+
+		if result := voxgigstruct.GetAll("x.*", store); 0 != len(result) {
+			t.Errorf("Expected no matches: %v", result)
+		}
+
+		// Only `$`, `$.` and `$[` start a query, so `$TOP` is a key, as for GetPath.
+		istore := map[string]any{"$TOP": map[string]any{"x": 1}}
+		if result := voxgigstruct.GetAll("$TOP.x", istore); !reflect.DeepEqual(
+			[]voxgigstruct.Match{{Path: []string{"$TOP", "x"}, Value: 1}}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+		if result := voxgigstruct.GetAll("$", 1); !reflect.DeepEqual(
+			[]voxgigstruct.Match{{Path: []string{}, Value: 1}}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+	})
+}
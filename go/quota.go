@@ -0,0 +1,106 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sync/atomic"
+)
+
+// A memory budget, such as for each tenant of a shared service. Each
+// call (CloneQuota, or a transform with TransformOptions.Quota) counts
+// the approximate bytes allocated for new nodes, and fails with an
+// ErrLimit *PathError if the count exceeds MaxBytes. The bytes
+// allocated by all calls are added to the total Used, so a Quota is
+// also a usage meter. A Quota is safe for concurrent use.
+type Quota struct {
+	MaxBytes int64 // Maximum bytes allocated by each call (zero means no limit).
+
+	used atomic.Int64
+}
+
+// Total bytes allocated by the calls that used the quota (including
+// calls that failed).
+func (q *Quota) Used() int64 {
+	return q.used.Load()
+}
+
+// Reset the total bytes used, returning the previous total.
+func (q *Quota) Reset() int64 {
+	return q.used.Swap(0)
+}
+
+// Clone a value, as for Clone, if the new nodes fit within the quota.
+// A nil Quota allows any value.
+func CloneQuota(val any, quota *Quota) (any, error) {
+	if nil == quota {
+		return Clone(val), nil
+	}
+
+	alloc, err := _allocCheck(val, 0, quota.MaxBytes)
+	quota.used.Add(alloc)
+	if nil != err {
+		return nil, err
+	}
+	return Clone(val), nil
+}
+
+// Count the bytes allocated to clone a value, starting from an
+// initial count, and stop with an error if the count exceeds max
+// (zero means no limit). As for Limits.Check, the value is walked
+// with an explicit stack, so a value that contains itself exceeds any
+// limit.
+func _allocCheck(val any, alloc int64, max int64) (int64, error) {
+	type entry struct {
+		val  any
+		path []string
+	}
+
+	stack := []entry{{val: val, path: []string{}}}
+
+	for 0 < len(stack) {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		alloc += _allocSize(top.val)
+		if 0 < max && max < alloc {
+			return alloc, _quotaError(max, top.path)
+		}
+
+		if IsNode(top.val) {
+			items := Items(top.val)
+			for iI := len(items) - 1; -1 < iI; iI-- {
+				stack = append(stack,
+					entry{val: items[iI][1], path: _childPath(top.path, StrKey(items[iI][0]))})
+			}
+		}
+	}
+
+	return alloc, nil
+}
+
+func _quotaError(max int64, path []string) error {
+	return NewPathError(ErrLimit, append([]string{}, path...), nil,
+		"Memory quota of %d bytes exceeded", max)
+}
+
+// Approximate bytes allocated for a value, not including children:
+// the map or slice header and entries (with map keys), the string
+// content, or the interface box of a scalar.
+func _allocSize(val any) int64 {
+	switch v := val.(type) {
+	case map[string]any:
+		size := int64(48 + 32*len(v))
+		for key := range v {
+			size += int64(len(key))
+		}
+		return size
+	case []any:
+		return int64(24 + 16*len(v))
+	case string:
+		return int64(16 + len(v))
+	}
+	if IsNode(val) {
+		return int64(48 + 32*NumKeys(val))
+	}
+	return 16
+}
@@ -0,0 +1,109 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestQuota(t *testing.T) {
+
+	small := map[string]any{"a": 1, "b": []any{"x", "y"}}
+	large := map[string]any{"a": strings.Repeat("x", 1000)}
+
+	t.Run("quota-clone", func(t *testing.T) {
+		quota := &voxgigstruct.Quota{MaxBytes: 500}
+
+		out, err := voxgigstruct.CloneQuota(small, quota)
+		if nil != err || !reflect.DeepEqual(small, out) {
+			t.Errorf("Expected: %v, Got: %v %v", small, out, err)
+		}
+		used := quota.Used()
+		if used <= 0 {
+			t.Errorf("Expected usage: %v", used)
+		}
+
+		out, err = voxgigstruct.CloneQuota(large, quota)
+		if nil != out || !errors.Is(err, voxgigstruct.ErrLimit) {
+			t.Errorf("Expected ErrLimit: %v %v", out, err)
+		}
+		var perr *voxgigstruct.PathError
+		if !errors.As(err, &perr) || !reflect.DeepEqual([]string{"a"}, perr.Path) {
+			t.Errorf("Expected path error at a: %v", err)
+		}
+
+		// Failed calls are also counted.
+		if quota.Used() <= used {
+			t.Errorf("Expected more usage: %v", quota.Used())
+		}
+		if total := quota.Reset(); total <= used || 0 != quota.Used() {
+			t.Errorf("Unexpected reset: %v %v", total, quota.Used())
+		}
+
+		// A nil quota allows any value.
+		out, err = voxgigstruct.CloneQuota(large, nil)
+		if nil != err || !reflect.DeepEqual(large, out) {
+			t.Errorf("Expected: %v, Got: %v %v", large, out, err)
+		}
+	})
+
+	t.Run("quota-transform", func(t *testing.T) {
+		quota := &voxgigstruct.Quota{MaxBytes: 2000}
+		spec := map[string]any{"b": "`b`"}
+
+		result := voxgigstruct.TransformCollect(small, spec, &voxgigstruct.TransformOptions{Quota: quota})
+		expected := map[string]any{"b": []any{"x", "y"}}
+		if 0 < len(result.Errs) || !reflect.DeepEqual(expected, result.Out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result.Out, result.Errs)
+		}
+		if quota.Used() <= 0 {
+			t.Errorf("Expected usage: %v", quota.Used())
+		}
+
+		// Input over the quota.
+		result = voxgigstruct.TransformCollect(
+			map[string]any{"a": strings.Repeat("x", 3000)}, spec,
+			&voxgigstruct.TransformOptions{Quota: quota})
+		if nil != result.Out || 1 != len(result.Errs) {
+			t.Fatalf("Expected an error: %v %v", result.Out, result.Errs)
+		}
+		if err, _ := result.Errs[0].(error); !errors.Is(err, voxgigstruct.ErrLimit) {
+			t.Errorf("Expected ErrLimit: %v", result.Errs[0])
+		}
+
+		// Output over the quota.
+		result = voxgigstruct.TransformCollect(map[string]any{"n": 100}, map[string]any{
+			"r": []any{"`$RANGE`", 1, "`n`"},
+		}, &voxgigstruct.TransformOptions{Quota: quota})
+		if nil != result.Out || 1 != len(result.Errs) {
+			t.Fatalf("Expected an error: %v %v", result.Out, result.Errs)
+		}
+		if err, _ := result.Errs[0].(error); !errors.Is(err, voxgigstruct.ErrLimit) {
+			t.Errorf("Expected ErrLimit: %v", result.Errs[0])
+		}
+	})
+
+	t.Run("quota-concurrent", func(t *testing.T) {
+		quota := &voxgigstruct.Quota{}
+		single, _ := voxgigstruct.CloneQuota(small, quota)
+		per := quota.Reset()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = voxgigstruct.CloneQuota(single, quota)
+			}()
+		}
+		wg.Wait()
+
+		if 8*per != quota.Used() {
+			t.Errorf("Expected: %v, Got: %v", 8*per, quota.Used())
+		}
+	})
+}
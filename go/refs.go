@@ -0,0 +1,191 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reference key, as used by JSON Reference, JSON Schema and OpenAPI.
+const S_ref = "$ref"
+
+// Options for ResolveRefs.
+type RefOptions struct {
+	// Load an external document, given its (resolved) URI. If nil,
+	// external references are an error.
+	Loader func(uri string) (any, error)
+
+	// Leave cyclic references in place, rather than returning an error.
+	KeepCycles bool
+}
+
+// Resolve references of the form { "$ref": "uri#/json/pointer" } by
+// replacing them with (copies of) the referenced subtrees. An empty
+// URI refers to the containing document. Other URIs are loaded using
+// opts.Loader, relative to the URI of the containing document, and
+// each document is only loaded once. Sibling keys of "$ref" are
+// ignored. The node is not modified. An error is returned for
+// unresolvable and cyclic references.
+func ResolveRefs(node any, opts *RefOptions) (any, error) {
+	if nil == opts {
+		opts = &RefOptions{}
+	}
+
+	r := &refResolver{
+		opts: opts,
+		docs: map[string]any{},
+		path: []string{},
+	}
+
+	return r.resolve(node, node, S_MT)
+}
+
+// Load JSON documents from files, for use as RefOptions.Loader.
+// Relative URIs are resolved against dir.
+func FileRefLoader(dir string) func(uri string) (any, error) {
+	return func(uri string) (any, error) {
+		fpath := uri
+		if !filepath.IsAbs(fpath) {
+			fpath = filepath.Join(dir, fpath)
+		}
+
+		data, err := os.ReadFile(fpath)
+		if nil != err {
+			return nil, err
+		}
+
+		var doc any
+		err = json.Unmarshal(data, &doc)
+		return doc, err
+	}
+}
+
+type refResolver struct {
+	opts *RefOptions
+	docs map[string]any
+	path []string // Active references, for cycle detection.
+}
+
+func (r *refResolver) resolve(node any, root any, docuri string) (any, error) {
+	if IsMap(node) {
+		m := node.(map[string]any)
+
+		if ref, isref := m[S_ref].(string); isref {
+			return r.follow(node, ref, root, docuri)
+		}
+
+		out := make(map[string]any, len(m))
+		for _, k := range KeysOf(m) {
+			child, err := r.resolve(m[k], root, docuri)
+			if nil != err {
+				return nil, err
+			}
+			out[k] = child
+		}
+		return out, nil
+
+	} else if IsList(node) {
+		list := _listify(node)
+		out := make([]any, len(list))
+		for i, v := range list {
+			child, err := r.resolve(v, root, docuri)
+			if nil != err {
+				return nil, err
+			}
+			out[i] = child
+		}
+		return out, nil
+	}
+
+	return node, nil
+}
+
+// Resolve a reference URI against the URI of the containing document.
+// Document URLs are resolved as URLs (RFC 3986), and file paths as
+// file paths.
+func _refURI(docuri string, uri string) string {
+	if S_MT == docuri || strings.Contains(uri, "://") {
+		return uri
+	}
+
+	if strings.Contains(docuri, "://") {
+		base, err := url.Parse(docuri)
+		if nil != err {
+			return uri
+		}
+		rel, err := url.Parse(uri)
+		if nil != err {
+			return uri
+		}
+		return base.ResolveReference(rel).String()
+	}
+
+	if filepath.IsAbs(uri) {
+		return uri
+	}
+	return filepath.Join(filepath.Dir(docuri), uri)
+}
+
+func (r *refResolver) follow(node any, ref string, root any, docuri string) (any, error) {
+	uri := ref
+	fragment := S_MT
+	if hI := strings.Index(ref, "#"); -1 < hI {
+		uri = ref[:hI]
+		fragment = ref[hI+1:]
+	}
+
+	target := root
+	if S_MT != uri {
+		uri = _refURI(docuri, uri)
+
+		doc, loaded := r.docs[uri]
+		if !loaded {
+			if nil == r.opts.Loader {
+				return nil, NewPathError(ErrNotFound, nil, nil, "Cannot load external reference: %s", ref)
+			}
+
+			var err error
+			doc, err = r.opts.Loader(uri)
+			if nil != err {
+				return nil, NewPathError(ErrNotFound, nil, err, "Cannot load external reference: %s", ref)
+			}
+			r.docs[uri] = doc
+		}
+
+		target = doc
+		docuri = uri
+	}
+
+	ptr, err := url.PathUnescape(fragment)
+	if nil != err {
+		return nil, NewPathError(ErrSpec, nil, nil, "Invalid reference: %s", ref)
+	}
+
+	val, found, err := _getPointer(ptr, target)
+	if nil != err {
+		return nil, NewPathError(ErrSpec, nil, err, "Invalid reference: %s", ref)
+	}
+	if !found {
+		return nil, NewPathError(ErrNotFound, nil, nil, "Unresolved reference: %s", ref)
+	}
+
+	key := docuri + "#" + ptr
+	for _, active := range r.path {
+		if active == key {
+			if r.opts.KeepCycles {
+				return Clone(node), nil
+			}
+			return nil, NewPathError(ErrSpec, nil, nil, "Cyclic reference: %s (via %s)", ref, strings.Join(r.path, " -> "))
+		}
+	}
+
+	r.path = append(r.path, key)
+	out, err := r.resolve(val, target, docuri)
+	r.path = r.path[:len(r.path)-1]
+
+	return out, err
+}
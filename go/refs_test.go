@@ -0,0 +1,140 @@
+package voxgigstruct_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestResolveRefs(t *testing.T) {
+
+	t.Run("refs-internal", func(t *testing.T) {
+		doc := map[string]any{
+			"defs": map[string]any{
+				"id":   map[string]any{"type": "string"},
+				"user": map[string]any{"id": map[string]any{"$ref": "#/defs/id"}},
+				"a/b":  1,
+			},
+			"user":  map[string]any{"$ref": "#/defs/user"},
+			"users": []any{map[string]any{"$ref": "#/defs/user"}},
+			"ab":    map[string]any{"$ref": "#/defs/a~1b"},
+		}
+
+		result, err := voxgigstruct.ResolveRefs(doc, nil)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		user := map[string]any{"id": map[string]any{"type": "string"}}
+		if !reflect.DeepEqual(user, voxgigstruct.GetPath("user", result)) {
+			t.Errorf("Expected: %v, Got: %v", user, voxgigstruct.GetPath("user", result))
+		}
+		if !reflect.DeepEqual(user, voxgigstruct.GetPath("users.0", result)) {
+			t.Errorf("Expected: %v, Got: %v", user, voxgigstruct.GetPath("users.0", result))
+		}
+		if 1 != voxgigstruct.GetPath("ab", result) {
+			t.Errorf("Expected escaped pointer to resolve")
+		}
+
+		// A null target is resolved, and is not missing.
+		result, err = voxgigstruct.ResolveRefs(map[string]any{
+			"x": nil, "y": map[string]any{"$ref": "#/x"},
+		}, nil)
+		if nil != err || !reflect.DeepEqual(map[string]any{"x": nil, "y": nil}, result) {
+			t.Errorf("Expected null reference: %v %v", result, err)
+		}
+
+		// Original is unchanged.
+		if "#/defs/user" != voxgigstruct.GetPath("user.$ref", doc) {
+			t.Errorf("Document was modified: %v", doc)
+		}
+	})
+
+	t.Run("refs-errors", func(t *testing.T) {
+		_, err := voxgigstruct.ResolveRefs(map[string]any{"a": map[string]any{"$ref": "#/x"}}, nil)
+		if nil == err || !strings.Contains(err.Error(), "Unresolved") {
+			t.Errorf("Expected unresolved error, Got: %v", err)
+		}
+
+		cyclic := map[string]any{
+			"node": map[string]any{"next": map[string]any{"$ref": "#/node"}},
+		}
+		_, err = voxgigstruct.ResolveRefs(cyclic, nil)
+		if nil == err || !strings.Contains(err.Error(), "Cyclic") {
+			t.Errorf("Expected cycle error, Got: %v", err)
+		}
+
+		result, err := voxgigstruct.ResolveRefs(cyclic, &voxgigstruct.RefOptions{KeepCycles: true})
+		expected := map[string]any{
+			"node": map[string]any{"next": map[string]any{"next": map[string]any{"$ref": "#/node"}}},
+		}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result, err)
+		}
+
+		_, err = voxgigstruct.ResolveRefs(map[string]any{"$ref": "other.json#/a"}, nil)
+		if nil == err {
+			t.Errorf("Expected error for external reference without loader")
+		}
+	})
+
+	t.Run("refs-file", func(t *testing.T) {
+		dir := t.TempDir()
+		os.MkdirAll(filepath.Join(dir, "sub"), 0o755)
+		os.WriteFile(filepath.Join(dir, "common.json"),
+			[]byte(`{"name":{"type":"string"},"nested":{"$ref":"sub/more.json#/x"}}`), 0o644)
+		os.WriteFile(filepath.Join(dir, "sub", "more.json"), []byte(`{"x":true}`), 0o644)
+
+		doc := map[string]any{
+			"name":   map[string]any{"$ref": "common.json#/name"},
+			"nested": map[string]any{"$ref": "common.json#/nested"},
+		}
+
+		result, err := voxgigstruct.ResolveRefs(doc, &voxgigstruct.RefOptions{
+			Loader: voxgigstruct.FileRefLoader(dir),
+		})
+
+		expected := map[string]any{
+			"name":   map[string]any{"type": "string"},
+			"nested": true,
+		}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, result, err)
+		}
+	})
+
+	t.Run("refs-url", func(t *testing.T) {
+		docs := map[string]any{
+			"https://example.com/schemas/a/common.json": map[string]any{
+				"more": map[string]any{"$ref": "../b/more.json#/x"},
+				"top":  map[string]any{"$ref": "/top.json?v=1#/y"},
+			},
+			"https://example.com/schemas/b/more.json": map[string]any{"x": 1},
+			"https://example.com/top.json?v=1":        map[string]any{"y": 2},
+		}
+		loaded := []string{}
+
+		doc := map[string]any{
+			"more": map[string]any{"$ref": "https://example.com/schemas/a/common.json#/more"},
+			"top":  map[string]any{"$ref": "https://example.com/schemas/a/common.json#/top"},
+		}
+		result, err := voxgigstruct.ResolveRefs(doc, &voxgigstruct.RefOptions{
+			Loader: func(uri string) (any, error) {
+				loaded = append(loaded, uri)
+				if d, ok := docs[uri]; ok {
+					return d, nil
+				}
+				return nil, os.ErrNotExist
+			},
+		})
+
+		expected := map[string]any{"more": 1, "top": 2}
+		if nil != err || !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v %v %v", expected, result, err, loaded)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"regexp"
+	"strings"
+)
+
+const S_DREGEX = "$REGEX"
+
+// Require a string field to match a regular expression (Go syntax).
+// The pattern is given after a colon, `$REGEX:^[a-z-]+$`, or, for
+// patterns that contain backticks, as a list argument:
+// ['`$REGEX`', '^[a-z-]+$']. Each pattern is compiled once per
+// validation.
+var validate_REGEX Injector = func(
+	state *Injection,
+	_val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	if S_MVAL != state.Mode {
+		return nil
+	}
+
+	path := state.Path
+	var pattern string
+
+	if nil != ref && strings.HasPrefix(*ref, S_DREGEX+":") {
+		pattern = (*ref)[len(S_DREGEX)+1:]
+		current = GetProp(current, state.Key)
+
+	} else {
+		if !IsList(state.Parent) || 0 != state.KeyI {
+			_invalidSpec(state, store, "The $REGEX validator at field "+Pathify(state.Path, 1, 1)+
+				" must have a pattern.")
+			return nil
+		}
+
+		// Skip the pattern, and replace the list with the value.
+		args := _listify(state.Parent)
+		state.KeyI = len(state.Keys)
+		pattern, _ = GetProp(args, 1).(string)
+
+		path = path[:len(path)-1]
+		grandparent := GetProp(state.Nodes, len(state.Nodes)-2)
+		SetProp(grandparent, path[len(path)-1], current)
+		state.Parent = map[string]any{}
+	}
+
+	re, err := _validateRegex(state, pattern)
+	if nil != err {
+		_invalid(state, store, path, VE_SPEC, pattern, nil,
+			"Invalid pattern for field "+Pathify(path, 1)+": "+err.Error())
+		return nil
+	}
+
+	if nil == current && _validateOptional(state, store) {
+		return nil
+	}
+
+	s, ok := current.(string)
+	if !ok {
+		_invalidType(state, store, path, S_string, Typify(current), current)
+		return nil
+	}
+
+	if !re.MatchString(s) {
+		_invalid(state, store, path, VE_PATTERN, pattern, s,
+			_invalidTypeMsg(path, "string matching "+pattern, S_string, s))
+		return nil
+	}
+
+	return s
+}
+
+// The compiled pattern, cached in the injection meta data.
+func _validateRegex(state *Injection, pattern string) (*regexp.Regexp, error) {
+	cache, ok := state.Meta[S_DREGEX].(map[string]*regexp.Regexp)
+	if !ok {
+		cache = map[string]*regexp.Regexp{}
+		state.Meta[S_DREGEX] = cache
+	}
+
+	if re, ok := cache[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if nil != err {
+		return nil, err
+	}
+	cache[pattern] = re
+	return re, nil
+}
@@ -0,0 +1,73 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestRegex(t *testing.T) {
+
+	spec := func() any {
+		return map[string]any{
+			"slug": "`$REGEX:^[a-z-]+$`",
+			"code": []any{"`$REGEX`", "^[A-Z]{2}\\.[0-9]+$"},
+		}
+	}
+
+	t.Run("regex-match", func(t *testing.T) {
+		data := map[string]any{"slug": "a-b", "code": "AB.12"}
+		out, err := voxgigstruct.Validate(data, spec())
+		if nil != err || !reflect.DeepEqual(data, out) {
+			t.Errorf("Expected: %v, Got: %v %v", data, out, err)
+		}
+	})
+
+	t.Run("regex-mismatch", func(t *testing.T) {
+		errs := voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(
+			map[string]any{"slug": "A B", "code": 12}, spec(), nil, errs)
+
+		expected := []any{
+			"Expected field code to be string, but found number: 12.",
+			"Expected field slug to be string matching ^[a-z-]+$, but found string: A B.",
+		}
+		if !reflect.DeepEqual(expected, errs.List) {
+			t.Errorf("Expected: %v, Got: %v", expected, errs.List)
+		}
+
+		// Missing values are required, unless optional.
+		errs = voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{}, spec(), nil, errs)
+		if 2 != len(errs.List) {
+			t.Errorf("Expected: %v, Got: %v", 2, errs.List)
+		}
+
+		optional := spec().(map[string]any)
+		optional["`$REQUIRED`"] = []any{}
+		errs = voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{}, optional, nil, errs)
+		if 0 != len(errs.List) {
+			t.Errorf("Expected: %v, Got: %v", 0, errs.List)
+		}
+	})
+
+	t.Run("regex-invalid", func(t *testing.T) {
+		_, err := voxgigstruct.Validate(map[string]any{"a": "x"},
+			map[string]any{"a": "`$REGEX:[a-`"})
+		expected := "Invalid data: Invalid pattern for field a: error parsing regexp: " +
+			"missing closing ]: `[a-`"
+		if nil == err || expected != err.Error() {
+			t.Errorf("Expected: %v, Got: %v", expected, err)
+		}
+	})
+
+	t.Run("regex-parse", func(t *testing.T) {
+		segs, _ := voxgigstruct.ParseInjection("`$REGEX:^a.b$`")
+		if 1 != len(segs) || "$REGEX" != segs[0].Text || "^a.b$" != segs[0].Arg ||
+			!segs[0].Transform {
+			t.Errorf("Expected: %v, Got: %v", "$REGEX ^a.b$", segs)
+		}
+	})
+}
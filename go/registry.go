@@ -0,0 +1,202 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Load all the Transformers of a Registry. Each Transformer must have
+// a Name, and may have a Version.
+type RegistryLoader func() ([]*Transformer, error)
+
+// A set of named, versioned Transformers, for lookup at request time.
+// The set is replaced atomically on reload, so lookups never see a
+// partially loaded set, and a failed reload keeps the previous set.
+type Registry struct {
+	loader  RegistryLoader
+	entries atomic.Pointer[map[string][]*Transformer]
+
+	reloadMutex sync.Mutex
+}
+
+// Create a registry, loading the Transformers with the loader.
+func NewRegistry(loader RegistryLoader) (*Registry, error) {
+	r := &Registry{loader: loader}
+	empty := map[string][]*Transformer{}
+	r.entries.Store(&empty)
+
+	if err := r.Reload(); nil != err {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load the Transformers again, and replace the current set if the
+// load succeeds.
+func (r *Registry) Reload() error {
+	r.reloadMutex.Lock()
+	defer r.reloadMutex.Unlock()
+
+	transformers, err := r.loader()
+	if nil != err {
+		return err
+	}
+
+	entries := map[string][]*Transformer{}
+	for _, t := range transformers {
+		if nil == t || S_MT == t.Name {
+			return NewPathError(ErrSpec, nil, nil, "Registry transformer has no name")
+		}
+		entries[t.Name] = append(entries[t.Name], t)
+	}
+
+	// Latest version first.
+	for _, versions := range entries {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return 0 < _compareVersions(versions[i].Version, versions[j].Version)
+		})
+	}
+
+	r.entries.Store(&entries)
+	return nil
+}
+
+// Reload periodically, until stop is called. Reload errors are passed
+// to onError, if defined.
+func (r *Registry) Watch(interval time.Duration, onError func(error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := r.Reload(); nil != err && nil != onError {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+// The latest version of a named Transformer, or nil if not found.
+func (r *Registry) Get(name string) *Transformer {
+	versions := (*r.entries.Load())[name]
+	if 0 == len(versions) {
+		return nil
+	}
+	return versions[0]
+}
+
+// A specific version of a named Transformer, or nil if not found.
+func (r *Registry) GetVersion(name string, version string) *Transformer {
+	for _, t := range (*r.entries.Load())[name] {
+		if version == t.Version {
+			return t
+		}
+	}
+	return nil
+}
+
+// Sorted names of the Transformers.
+func (r *Registry) Names() []string {
+	entries := *r.entries.Load()
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// A loader for the JSON spec files in a directory (see LoadSpec). The
+// file name gives the name and version of the Transformer: for
+// example `orders@1.2.0.json` has the name `orders` and version
+// `1.2.0`, and `orders.json` has no version.
+func DirLoader(dir string, opts *LoadOptions) RegistryLoader {
+	return func() ([]*Transformer, error) {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if nil != err {
+			return nil, NewPathError(ErrSpec, nil, err, "Invalid registry directory: %s", dir)
+		}
+
+		// Each load reads the files again.
+		var fileopts LoadOptions
+		if nil != opts {
+			fileopts = *opts
+		}
+		fileopts.Cache = nil
+
+		transformers := []*Transformer{}
+		for _, file := range files {
+			if info, err := os.Stat(file); nil != err || info.IsDir() {
+				continue
+			}
+
+			t, err := LoadSpec(file, &fileopts)
+			if nil != err {
+				return nil, err
+			}
+
+			base := strings.TrimSuffix(filepath.Base(file), ".json")
+			t.Name = base
+			if aI := strings.LastIndex(base, "@"); -1 < aI {
+				t.Name = base[:aI]
+				t.Version = base[aI+1:]
+			}
+			transformers = append(transformers, t)
+		}
+
+		return transformers, nil
+	}
+}
+
+// Compare dotted versions, numerically where possible. Returns -1, 0
+// or 1.
+func _compareVersions(a string, b string) int {
+	ap := strings.Split(a, S_DT)
+	bp := strings.Split(b, S_DT)
+
+	for i := 0; i < len(ap) || i < len(bp); i++ {
+		var as, bs string
+		if i < len(ap) {
+			as = ap[i]
+		}
+		if i < len(bp) {
+			bs = bp[i]
+		}
+
+		an, aerr := strconv.Atoi(as)
+		bn, berr := strconv.Atoi(bs)
+		if nil == aerr && nil == berr {
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		} else if c := strings.Compare(as, bs); 0 != c {
+			return c
+		}
+	}
+
+	return 0
+}
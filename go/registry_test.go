@@ -0,0 +1,110 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/voxgig/struct"
+)
+
+func TestRegistry(t *testing.T) {
+
+	t.Run("registry-dir", func(t *testing.T) {
+		dir := t.TempDir()
+		write := func(name string, src string) {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); nil != err {
+				t.Fatal(err)
+			}
+		}
+
+		write("orders@1.2.0.json", `{"v":"1.2"}`)
+		write("orders@1.10.0.json", `{"v":"1.10"}`)
+		write("users.json", `{"u":"`+"`a`"+`"}`)
+		write("notes.txt", `ignored`)
+
+		reg, err := voxgigstruct.NewRegistry(voxgigstruct.DirLoader(dir, nil))
+		if nil != err {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual([]string{"orders", "users"}, reg.Names()) {
+			t.Errorf("Unexpected names: %v", reg.Names())
+		}
+
+		if tr := reg.Get("orders"); nil == tr || "1.10.0" != tr.Version {
+			t.Errorf("Expected latest version: %v", tr)
+		}
+
+		if tr := reg.GetVersion("orders", "1.2.0"); nil == tr ||
+			!reflect.DeepEqual(map[string]any{"v": "1.2"}, tr.Transform(nil)) {
+			t.Errorf("Unexpected: %v", tr)
+		}
+
+		if result := reg.Get("users").Transform(map[string]any{"a": 1}); !reflect.DeepEqual(
+			map[string]any{"u": 1}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+
+		if nil != reg.Get("none") || nil != reg.GetVersion("orders", "9") {
+			t.Errorf("Expected not found")
+		}
+
+		// A failed reload keeps the current set.
+		write("bad.json", `{`)
+		if err := reg.Reload(); !errors.Is(err, voxgigstruct.ErrSpec) {
+			t.Errorf("Expected ErrSpec, Got: %v", err)
+		}
+		if nil == reg.Get("users") {
+			t.Errorf("Expected previous set after failed reload")
+		}
+
+		os.Remove(filepath.Join(dir, "bad.json"))
+		os.Remove(filepath.Join(dir, "users.json"))
+		if err := reg.Reload(); nil != err || nil != reg.Get("users") {
+			t.Errorf("Expected reload to remove users: %v", err)
+		}
+	})
+
+	t.Run("registry-loader", func(t *testing.T) {
+		var version atomic.Value
+		version.Store("1")
+		reg, err := voxgigstruct.NewRegistry(func() ([]*voxgigstruct.Transformer, error) {
+			tr := voxgigstruct.NewTransformer(nil, nil)
+			tr.Name = "x"
+			tr.Version = version.Load().(string)
+			return []*voxgigstruct.Transformer{tr}, nil
+		})
+		if nil != err || "1" != reg.Get("x").Version {
+			t.Fatalf("Unexpected: %v", err)
+		}
+
+		version.Store("2")
+		reg.Reload()
+		if "2" != reg.Get("x").Version {
+			t.Errorf("Expected reloaded version")
+		}
+
+		stop := reg.Watch(time.Millisecond, nil)
+		version.Store("3")
+		for start := time.Now(); "3" != reg.Get("x").Version && time.Since(start) < time.Second; {
+			time.Sleep(time.Millisecond)
+		}
+		stop()
+		stop()
+		if "3" != reg.Get("x").Version {
+			t.Errorf("Expected watched reload")
+		}
+
+		_, err = voxgigstruct.NewRegistry(func() ([]*voxgigstruct.Transformer, error) {
+			return []*voxgigstruct.Transformer{voxgigstruct.NewTransformer(nil, nil)}, nil
+		})
+		if !errors.Is(err, voxgigstruct.ErrSpec) {
+			t.Errorf("Expected ErrSpec for unnamed transformer, Got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+const S_DREQUIRED = "$REQUIRED"
+
+// Required and optional fields in validation shapes.
+//
+// As a value, `$REQUIRED` requires the field to be present, with
+// any value:
+//
+//	{ "id": "`$REQUIRED`" }
+//
+// As a key, `$REQUIRED` lists the fields of an object that must be
+// present:
+//
+//	{ "`$REQUIRED`": ["id"], "id": "`$STRING`", "note": "`$STRING`" }
+//
+// The other fields of the object are then optional: a missing field
+// is not an error, even if its shape has a type validator (such as
+// `$STRING`). Fields inside a missing optional field are also
+// optional, and a missing optional object is not created in the
+// output. A present field must still match its shape.
+var validate_REQUIRED Injector = func(
+	state *Injection,
+	_val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	if S_MKEYPRE == state.Mode {
+		required := GetProp(state.Parent, state.Key)
+		SetProp(state.Parent, state.Key, nil)
+
+		if !IsList(required) {
+			_invalidSpec(state, store, "The $REQUIRED validator at field "+
+				Pathify(state.Path, 1, 1)+" must list the required fields.")
+			return nil
+		}
+
+		pkey := GetProp(state.Path, len(state.Path)-2)
+		tval := GetProp(current, pkey)
+
+		// The fields of a missing optional object are not required.
+		if nil == tval {
+			ostate := *state
+			ostate.Path = state.Path[:len(state.Path)-1]
+			if _validateOptional(&ostate, store) {
+				return nil
+			}
+		}
+
+		for _, rkey := range _listify(required) {
+			if nil == GetProp(tval, rkey) {
+				path := append(append([]string{}, state.Path[:len(state.Path)-1]...), StrKey(rkey))
+				_invalid(state, store, path, VE_REQUIRED, required, nil, _missingMsg(path))
+			}
+		}
+
+		return nil
+	}
+
+	if S_MVAL == state.Mode {
+		out := GetProp(current, state.Key)
+		if nil == out {
+			_invalid(state, store, state.Path, VE_REQUIRED, nil, nil, _missingMsg(state.Path))
+		}
+		return out
+	}
+
+	return nil
+}
+
+func _missingMsg(path []string) string {
+	if len(path) <= 1 {
+		return "Missing required value."
+	}
+	return "Missing required field " + Pathify(path, 1) + "."
+}
+
+// A missing field is not checked by its type validator if it, or a
+// missing ancestor, is in an object shape with a `$REQUIRED` key: the
+// field is either optional, or reported as missing by `$REQUIRED`.
+func _validateOptional(state *Injection, store any) bool {
+	top := GetProp(store, S_DTOP)
+
+	for nI := len(state.Path) - 1; 0 < nI && nI < len(state.Nodes); nI-- {
+		// Stop at the first ancestor that is present.
+		if nil != GetPath(state.Path[1:nI+1], top) {
+			return false
+		}
+
+		if nil != GetProp(state.Nodes[nI], S_BT+S_DREQUIRED+S_BT) {
+			return true
+		}
+	}
+
+	return false
+}
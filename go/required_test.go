@@ -0,0 +1,109 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestValidateRequired(t *testing.T) {
+	t.Run("validate-required-value", func(t *testing.T) {
+		spec := map[string]any{"id": "`$REQUIRED`", "x": 1}
+
+		out, err := voxgigstruct.Validate(map[string]any{"id": true}, spec)
+		if nil != err || !reflect.DeepEqual(map[string]any{"id": true, "x": 1}, out) {
+			t.Errorf("Expected: %v, Got: %v %v", map[string]any{"id": true, "x": 1}, out, err)
+		}
+
+		_, err = voxgigstruct.Validate(map[string]any{}, spec)
+		if nil == err || !strings.Contains(err.Error(), "Missing required field id.") {
+			t.Errorf("Expected: %v, Got: %v", "Missing required field id.", err)
+		}
+	})
+
+	t.Run("validate-required-key", func(t *testing.T) {
+		spec := func() any {
+			return map[string]any{
+				"a": map[string]any{
+					"`$REQUIRED`": []any{"id", "kind"},
+					"id":          "`$STRING`",
+					"kind":        "x",
+					"note":        "`$STRING`",
+					"size":        "`$NUMBER`",
+				},
+			}
+		}
+
+		// Optional fields may be missing.
+		out, err := voxgigstruct.Validate(map[string]any{"a": map[string]any{"id": "i1", "kind": "k"}}, spec())
+		expected := map[string]any{"a": map[string]any{"id": "i1", "kind": "k"}}
+		if nil != err || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, err)
+		}
+
+		// But must match their shape if present.
+		_, err = voxgigstruct.Validate(map[string]any{"a": map[string]any{"id": "i1", "kind": "k", "size": "L"}}, spec())
+		if nil == err || !strings.Contains(err.Error(), "Expected field a.size to be number") {
+			t.Errorf("Expected: %v, Got: %v", "a.size", err)
+		}
+
+		// Required fields are reported once, with their path.
+		errs := voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{"a": map[string]any{}}, spec(), nil, errs)
+		expectedErrs := []any{"Missing required field a.id.", "Missing required field a.kind."}
+		if !reflect.DeepEqual(expectedErrs, errs.List) {
+			t.Errorf("Expected: %v, Got: %v", expectedErrs, errs.List)
+		}
+	})
+
+	t.Run("validate-required-nested", func(t *testing.T) {
+		spec := func() any {
+			return map[string]any{
+				"`$REQUIRED`": []any{"b"},
+				"a":           map[string]any{"c": "`$STRING`"},
+				"b":           map[string]any{"c": "`$STRING`"},
+			}
+		}
+
+		// Missing optional object a: its fields are not checked.
+		errs := voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{"b": map[string]any{"c": "x"}}, spec(), nil, errs)
+		if 0 != len(errs.List) {
+			t.Errorf("Expected: %v, Got: %v", 0, errs.List)
+		}
+
+		// Present object a: its fields are checked.
+		errs = voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{"a": map[string]any{}, "b": map[string]any{"c": "x"}}, spec(), nil, errs)
+		if 1 != len(errs.List) || !strings.Contains(errs.List[0].(string), "field a.c to be string") {
+			t.Errorf("Expected: %v, Got: %v", "a.c", errs.List)
+		}
+
+		// Missing optional object a, with its own required fields: it is
+		// not checked, and not created.
+		nspec := spec().(map[string]any)
+		nspec["a"] = map[string]any{"`$REQUIRED`": []any{"c"}, "c": "`$STRING`"}
+		errs = voxgigstruct.ListRefCreate[any]()
+		out, _ := voxgigstruct.ValidateCollect(map[string]any{"b": map[string]any{"c": "x"}}, nspec, nil, errs)
+		expected := map[string]any{"b": map[string]any{"c": "x"}}
+		if 0 != len(errs.List) || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, errs.List)
+		}
+
+		// Missing required object b.
+		errs = voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{}, spec(), nil, errs)
+		if !reflect.DeepEqual([]any{"Missing required field b."}, errs.List) {
+			t.Errorf("Expected: %v, Got: %v", "Missing required field b.", errs.List)
+		}
+	})
+
+	t.Run("validate-required-invalid", func(t *testing.T) {
+		_, err := voxgigstruct.Validate(map[string]any{}, map[string]any{"`$REQUIRED`": "id"})
+		if nil == err || !strings.Contains(err.Error(), "must list the required fields") {
+			t.Errorf("Expected: %v, Got: %v", "must list", err)
+		}
+	})
+}
@@ -0,0 +1,299 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strings"
+)
+
+// Convert a JSON Schema into a validation shape, for use with
+// Validate. The supported subset is:
+//
+//   - type: string, number, integer (validated as a number), boolean,
+//     object, array, and null (in a list of types, only). A list of
+//     types is a `$ONE` of the types.
+//   - properties, required (as a `$REQUIRED` key, so other properties
+//     are optional), and additionalProperties (false closes the
+//     object, and a schema validates the values of a map).
+//   - items (a single schema, for all elements).
+//   - enum and const (as `$EXACT`), and pattern (as `$REGEX`).
+//   - anyOf and oneOf (as `$ONE`), and allOf (the shapes are merged).
+//   - default, used as the shape of an optional property, so that
+//     the default is filled in.
+//   - $ref, resolved by ResolveRefs, so only references within the
+//     schema are supported.
+//
+// Other keywords (such as format, minimum and maxLength) are ignored,
+// and accept any matching value. The schema is not modified. An error
+// (ErrSpec) is returned for malformed schemas and unresolvable
+// references.
+func JSONSchemaToShape(schema any) (any, error) {
+	resolved, err := ResolveRefs(schema, nil)
+	if nil != err {
+		return nil, err
+	}
+	return _schemaShape(resolved, []string{})
+}
+
+func _schemaShape(schema any, path []string) (any, error) {
+	if b, ok := schema.(bool); ok {
+		if !b {
+			return nil, NewPathError(ErrSpec, path, nil, "The false schema is not supported.")
+		}
+		return "`$ANY`", nil
+	}
+	if !IsMap(schema) {
+		return nil, NewPathError(ErrSpec, path, nil, "Schema is not an object: %s", Typify(schema))
+	}
+
+	if c, has := _getProp(schema, "const"); has {
+		return []any{"`$EXACT`", Clone(c)}, nil
+	}
+
+	if enum := GetProp(schema, "enum"); nil != enum {
+		if !IsList(enum) || 0 == NumKeys(enum) {
+			return nil, NewPathError(ErrSpec, _childPath(path, "enum"), nil,
+				"Schema enum must be a non-empty array.")
+		}
+		return append([]any{"`$EXACT`"}, Clone(enum).([]any)...), nil
+	}
+
+	for _, kw := range []string{"anyOf", "oneOf"} {
+		if alts := GetProp(schema, kw); nil != alts {
+			shapes, err := _schemaShapes(alts, _childPath(path, kw))
+			if nil != err {
+				return nil, err
+			}
+			return append([]any{"`$ONE`"}, shapes...), nil
+		}
+	}
+
+	if all := GetProp(schema, "allOf"); nil != all {
+		shapes, err := _schemaShapes(all, _childPath(path, "allOf"))
+		if nil != err {
+			return nil, err
+		}
+
+		// The schema itself may also have constraints.
+		rest := map[string]any{}
+		for _, key := range KeysOf(schema) {
+			if "allOf" != key {
+				rest[key] = GetProp(schema, key)
+			}
+		}
+		if 0 < len(rest) {
+			shape, err := _schemaShape(rest, path)
+			if nil != err {
+				return nil, err
+			}
+			shapes = append(shapes, shape)
+		}
+		return _mergeShapes(shapes), nil
+	}
+
+	switch t := GetProp(schema, "type").(type) {
+	case nil:
+		return _schemaTypeShape(schema, _schemaImpliedType(schema), path)
+
+	case string:
+		return _schemaTypeShape(schema, t, path)
+
+	case []any:
+		shapes := []any{}
+		for _, tn := range t {
+			tname, ok := tn.(string)
+			if !ok {
+				return nil, NewPathError(ErrSpec, _childPath(path, "type"), nil,
+					"Schema type is not a string: %s", Typify(tn))
+			}
+			if "null" == tname {
+				continue
+			}
+			shape, err := _schemaTypeShape(schema, tname, path)
+			if nil != err {
+				return nil, err
+			}
+			shapes = append(shapes, shape)
+		}
+		if 0 == len(shapes) {
+			return "`$ANY`", nil
+		}
+		if 1 == len(shapes) {
+			return shapes[0], nil
+		}
+		return append([]any{"`$ONE`"}, shapes...), nil
+
+	default:
+		return nil, NewPathError(ErrSpec, _childPath(path, "type"), nil,
+			"Schema type must be a string or an array: %s", Typify(t))
+	}
+}
+
+// The shape of a schema, with the given type.
+func _schemaTypeShape(schema any, tname string, path []string) (any, error) {
+	switch tname {
+	case "":
+		return "`$ANY`", nil
+
+	case "string":
+		if pattern, ok := GetProp(schema, "pattern").(string); ok {
+			if strings.Contains(pattern, S_BT) {
+				return []any{S_BT + S_DREGEX + S_BT, pattern}, nil
+			}
+			return S_BT + S_DREGEX + ":" + pattern + S_BT, nil
+		}
+		return "`$STRING`", nil
+
+	case "number", "integer":
+		return "`$NUMBER`", nil
+
+	case "boolean":
+		return "`$BOOLEAN`", nil
+
+	case "null":
+		return "`$ANY`", nil
+
+	case "array":
+		items := GetProp(schema, "items")
+		if nil == items {
+			return "`$ARRAY`", nil
+		}
+		shape, err := _schemaShape(items, _childPath(path, "items"))
+		if nil != err {
+			return nil, err
+		}
+		return []any{"`$CHILD`", shape}, nil
+
+	case "object":
+		return _schemaObjectShape(schema, path)
+	}
+
+	return nil, NewPathError(ErrSpec, _childPath(path, "type"), nil, "Unknown schema type: %s", tname)
+}
+
+func _schemaObjectShape(schema any, path []string) (any, error) {
+	props := GetProp(schema, "properties")
+	required := GetProp(schema, "required")
+	additional := GetProp(schema, "additionalProperties")
+
+	// A map, with values of the same shape.
+	if nil == props && nil == required && IsMap(additional) {
+		shape, err := _schemaShape(additional, _childPath(path, "additionalProperties"))
+		if nil != err {
+			return nil, err
+		}
+		return map[string]any{"`$CHILD`": shape}, nil
+	}
+
+	if nil == props && nil == required {
+		if false == additional {
+			return map[string]any{}, nil
+		}
+		return "`$OBJECT`", nil
+	}
+
+	if nil != required && !IsList(required) {
+		return nil, NewPathError(ErrSpec, _childPath(path, "required"), nil,
+			"Schema required must be an array.")
+	}
+
+	reqset := map[string]bool{}
+	reqlist := []any{}
+	for _, r := range _listify(required) {
+		if rs, ok := r.(string); ok {
+			reqset[rs] = true
+			reqlist = append(reqlist, rs)
+		}
+	}
+
+	shape := map[string]any{S_BT + S_DREQUIRED + S_BT: reqlist}
+
+	ppath := _childPath(path, "properties")
+	for _, name := range KeysOf(props) {
+		pschema := GetProp(props, name)
+		if def := GetProp(pschema, "default"); nil != def && !reqset[name] {
+			shape[name] = Clone(def)
+			continue
+		}
+		pshape, err := _schemaShape(pschema, _childPath(ppath, name))
+		if nil != err {
+			return nil, err
+		}
+		shape[name] = pshape
+	}
+
+	// Required properties without a schema may have any value.
+	for _, name := range reqlist {
+		if _, has := shape[name.(string)]; !has {
+			shape[name.(string)] = "`$ANY`"
+		}
+	}
+
+	if false != additional {
+		shape["`$OPEN`"] = true
+	}
+
+	return shape, nil
+}
+
+func _schemaShapes(schemas any, path []string) ([]any, error) {
+	if !IsList(schemas) || 0 == NumKeys(schemas) {
+		return nil, NewPathError(ErrSpec, path, nil, "Schema %s must be a non-empty array.",
+			path[len(path)-1])
+	}
+
+	shapes := []any{}
+	for i, s := range _listify(schemas) {
+		shape, err := _schemaShape(s, _childPath(path, StrKey(i)))
+		if nil != err {
+			return nil, err
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes, nil
+}
+
+// Merge the shapes of allOf. Object shapes are merged, including the
+// required fields, and otherwise the most specific (last) shape is
+// used.
+func _mergeShapes(shapes []any) any {
+	req := S_BT + S_DREQUIRED + S_BT
+	reqlist := []any{}
+	objects := []any{}
+	var out any = "`$ANY`"
+
+	for _, shape := range shapes {
+		if IsMap(shape) {
+			reqlist = append(reqlist, _listify(GetProp(shape, req))...)
+			objects = append(objects, shape)
+		} else if "`$ANY`" != shape && "`$OBJECT`" != shape {
+			out = shape
+		}
+	}
+
+	if 0 == len(objects) {
+		return out
+	}
+
+	merged := Merge(objects)
+	if 0 < len(reqlist) {
+		SetProp(merged, req, reqlist)
+	}
+	return merged
+}
+
+// The type implied by the keywords of a schema without a type.
+func _schemaImpliedType(schema any) string {
+	if nil != GetProp(schema, "properties") ||
+		nil != GetProp(schema, "required") ||
+		nil != GetProp(schema, "additionalProperties") {
+		return "object"
+	}
+	if nil != GetProp(schema, "items") {
+		return "array"
+	}
+	if nil != GetProp(schema, "pattern") {
+		return "string"
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestJSONSchemaToShape(t *testing.T) {
+
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"id", "kind"},
+		"properties": map[string]any{
+			"id":    map[string]any{"type": "string", "pattern": "^[a-z]+[0-9]*$"},
+			"kind":  map[string]any{"enum": []any{"user", "admin"}},
+			"age":   map[string]any{"type": "integer"},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"size":  map[string]any{"type": "number", "default": 10},
+			"owner": map[string]any{"$ref": "#/$defs/owner"},
+			"note":  map[string]any{"type": []any{"string", "null"}},
+		},
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"owner": map[string]any{
+				"type":       "object",
+				"required":   []any{"name"},
+				"properties": map[string]any{"name": map[string]any{"type": "string"}},
+			},
+		},
+	}
+
+	t.Run("schema-shape", func(t *testing.T) {
+		shape, err := voxgigstruct.JSONSchemaToShape(schema)
+		if nil != err {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := map[string]any{
+			"`$REQUIRED`": []any{"id", "kind"},
+			"id":          "`$REGEX:^[a-z]+[0-9]*$`",
+			"kind":        []any{"`$EXACT`", "user", "admin"},
+			"age":         "`$NUMBER`",
+			"tags":        []any{"`$CHILD`", "`$STRING`"},
+			"size":        10,
+			"owner": map[string]any{
+				"`$REQUIRED`": []any{"name"},
+				"name":        "`$STRING`",
+				"`$OPEN`":     true,
+			},
+			"note": "`$STRING`",
+		}
+		if !reflect.DeepEqual(expected, shape) {
+			t.Errorf("Expected: %v, Got: %v", expected, shape)
+		}
+
+		// The schema is not modified.
+		if nil == voxgigstruct.GetPath("properties.owner.$ref", schema) {
+			t.Errorf("Schema was modified: %v", schema)
+		}
+	})
+
+	t.Run("schema-validate", func(t *testing.T) {
+		shape, _ := voxgigstruct.JSONSchemaToShape(schema)
+
+		out, err := voxgigstruct.Validate(map[string]any{
+			"id":   "ann1",
+			"kind": "admin",
+			"tags": []any{"a"},
+		}, voxgigstruct.Clone(shape))
+		if nil != err {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		expected := map[string]any{"id": "ann1", "kind": "admin", "tags": []any{"a"}, "size": 10}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		_, verrs := voxgigstruct.ValidateAll(map[string]any{
+			"id":    "Ann",
+			"age":   "old",
+			"owner": map[string]any{},
+			"extra": true,
+		}, voxgigstruct.Clone(shape))
+		fields := verrs.ByField()
+		for _, field := range []string{"id", "kind", "age", "owner.name", ""} {
+			if 0 == len(fields[field]) {
+				t.Errorf("Expected an error for %s, Got: %v", field, verrs)
+			}
+		}
+	})
+
+	t.Run("schema-combine", func(t *testing.T) {
+		shape, err := voxgigstruct.JSONSchemaToShape(map[string]any{
+			"properties": map[string]any{
+				"v": map[string]any{"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "number"},
+				}},
+				"c": map[string]any{"const": "x"},
+				"m": map[string]any{"additionalProperties": map[string]any{"type": "boolean"}},
+			},
+			"allOf": []any{
+				map[string]any{"required": []any{"a"}, "properties": map[string]any{"a": true}},
+				map[string]any{"required": []any{"b"}},
+			},
+		})
+		if nil != err {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := map[string]any{
+			"`$REQUIRED`": []any{"a", "b"},
+			"`$OPEN`":     true,
+			"a":           "`$ANY`",
+			"b":           "`$ANY`",
+			"v":           []any{"`$ONE`", "`$STRING`", "`$NUMBER`"},
+			"c":           []any{"`$EXACT`", "x"},
+			"m":           map[string]any{"`$CHILD`": "`$BOOLEAN`"},
+		}
+		if !reflect.DeepEqual(expected, shape) {
+			t.Errorf("Expected: %v, Got: %v", expected, shape)
+		}
+
+		_, err = voxgigstruct.Validate(map[string]any{
+			"a": 1, "b": 2, "v": "s", "m": map[string]any{"x": true},
+		}, shape)
+		if nil != err {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("schema-error", func(t *testing.T) {
+		for _, bad := range []any{
+			"string",
+			map[string]any{"type": "date"},
+			map[string]any{"enum": []any{}},
+			map[string]any{"properties": map[string]any{"a": map[string]any{"$ref": "#/missing"}}},
+		} {
+			_, err := voxgigstruct.JSONSchemaToShape(bad)
+			if nil == err {
+				t.Errorf("Expected an error for %v", bad)
+			}
+		}
+
+		_, err := voxgigstruct.JSONSchemaToShape(map[string]any{
+			"properties": map[string]any{"a": map[string]any{"type": 1}},
+		})
+		if !errors.Is(err, voxgigstruct.ErrSpec) || !strings.Contains(err.Error(), "properties.a.type") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
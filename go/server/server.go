@@ -0,0 +1,205 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+// An HTTP+JSON service for the struct utilities, so that services in
+// other languages can use the Go implementation. The operations are
+// those of the ffi package, and requests and responses have the same
+// form:
+//
+//	POST /v1/ops/transform   {"data": ..., "spec": ...}
+//	  => 200 {"out": ...}
+//	POST /v1/ops/validate    {"data": ..., "spec": ...}
+//	  => 422 {"out": null, "err": "Invalid data: ...", "errs": [...]}
+//
+// Named specs of a Registry are listed and applied with:
+//
+//	GET  /v1/specs                         => 200 {"out": ["orders", ...]}
+//	POST /v1/specs/orders/transform        (the body is the data)
+//	POST /v1/specs/orders@1.2.0/transform  (a specific version)
+//
+// Each transform is within the limits of the Options, and a transform
+// that fails (such as by exceeding a limit) is a 400 response.
+//
+// Only HTTP is provided, so that this module has no third party
+// dependencies; a gRPC service can be built on the ffi package in the
+// same way.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	vs "github.com/voxgig/struct"
+	"github.com/voxgig/struct/ffi"
+)
+
+// Default maximum size of a request body.
+const DefaultMaxBody = 10 << 20
+
+// Options for New.
+type Options struct {
+	Registry *vs.Registry // Named specs, if any.
+	MaxBody  int64        // Maximum size of a request body (default: DefaultMaxBody).
+
+	// Limits of each transform, as the data and specs of requests are
+	// untrusted (default: ffi.DefaultLimits).
+	Limits *vs.Limits
+
+	// Memory budget of each transform, if any.
+	Quota *vs.Quota
+}
+
+type server struct {
+	opts Options
+}
+
+// Create the HTTP handler of the service.
+func New(opts *Options) http.Handler {
+	s := &server{}
+	if nil != opts {
+		s.opts = *opts
+	}
+	if s.opts.MaxBody <= 0 {
+		s.opts.MaxBody = DefaultMaxBody
+	}
+	if nil == s.opts.Limits {
+		limits := ffi.DefaultLimits
+		s.opts.Limits = &limits
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.health)
+	mux.HandleFunc("/v1/ops/", s.op)
+	mux.HandleFunc("/v1/specs", s.specs)
+	mux.HandleFunc("/v1/specs/", s.spec)
+	return mux
+}
+
+func (s *server) health(w http.ResponseWriter, r *http.Request) {
+	reply(w, http.StatusOK, &ffi.Response{Out: "ok"})
+}
+
+// Apply an ffi operation.
+func (s *server) op(w http.ResponseWriter, r *http.Request) {
+	if http.MethodPost != r.Method {
+		reply(w, http.StatusMethodNotAllowed, &ffi.Response{Err: "Method not allowed: " + r.Method})
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/v1/ops/")
+	if !hasOp(name) {
+		reply(w, http.StatusNotFound, &ffi.Response{Err: "Unknown operation: " + name})
+		return
+	}
+
+	body, ok := s.body(w, r)
+	if !ok {
+		return
+	}
+
+	out := ffi.CallWith(name, body, &ffi.Options{Limits: s.opts.Limits, Quota: s.opts.Quota})
+
+	var res ffi.Response
+	_ = json.Unmarshal(out, &res)
+	status := http.StatusOK
+	if 0 < len(res.Errs) {
+		status = http.StatusUnprocessableEntity
+	} else if "" != res.Err {
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", vs.CT_JSON)
+	w.WriteHeader(status)
+	_, _ = w.Write(out)
+}
+
+// List the names of the registry specs.
+func (s *server) specs(w http.ResponseWriter, r *http.Request) {
+	names := []string{}
+	if nil != s.opts.Registry {
+		names = s.opts.Registry.Names()
+	}
+	reply(w, http.StatusOK, &ffi.Response{Out: names})
+}
+
+// Transform with a registry spec: /v1/specs/{name}[@version]/transform.
+func (s *server) spec(w http.ResponseWriter, r *http.Request) {
+	ref, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/specs/"), "/")
+	if "transform" != action {
+		reply(w, http.StatusNotFound, &ffi.Response{Err: "Unknown spec action: " + action})
+		return
+	}
+	if http.MethodPost != r.Method {
+		reply(w, http.StatusMethodNotAllowed, &ffi.Response{Err: "Method not allowed: " + r.Method})
+		return
+	}
+
+	var t *vs.Transformer
+	if nil != s.opts.Registry {
+		if name, version, found := strings.Cut(ref, "@"); found {
+			t = s.opts.Registry.GetVersion(name, version)
+		} else {
+			t = s.opts.Registry.Get(name)
+		}
+	}
+	if nil == t {
+		reply(w, http.StatusNotFound, &ffi.Response{Err: "Unknown spec: " + ref})
+		return
+	}
+
+	body, ok := s.body(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := vs.FromJSON(body)
+	if nil != err {
+		reply(w, http.StatusBadRequest, &ffi.Response{Err: err.Error()})
+		return
+	}
+
+	tres := t.TransformCollect(data, s.opts.Limits, s.opts.Quota)
+	if 0 < len(tres.Errs) {
+		reply(w, http.StatusBadRequest, &ffi.Response{Err: fmt.Sprintf("%v", tres.Errs[0])})
+		return
+	}
+
+	reply(w, http.StatusOK, &ffi.Response{Out: tres.Out})
+}
+
+// Read the request body, replying with an error if it is too large.
+func (s *server) body(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.opts.MaxBody))
+	if nil != err {
+		reply(w, http.StatusRequestEntityTooLarge, &ffi.Response{Err: "Invalid request body: " + err.Error()})
+		return nil, false
+	}
+	return body, true
+}
+
+func reply(w http.ResponseWriter, status int, res *ffi.Response) {
+	if nil != res.Out {
+		tagged, err := vs.ToJSON(res.Out)
+		if nil != err {
+			status = http.StatusInternalServerError
+			res = &ffi.Response{Err: "Invalid output: " + err.Error()}
+			tagged = []byte("null")
+		}
+		res.Out = json.RawMessage(tagged)
+	}
+
+	w.Header().Set("Content-Type", vs.CT_JSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func hasOp(name string) bool {
+	for _, op := range ffi.Ops() {
+		if name == op {
+			return true
+		}
+	}
+	return false
+}
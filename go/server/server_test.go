@@ -0,0 +1,175 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	vs "github.com/voxgig/struct"
+	"github.com/voxgig/struct/server"
+)
+
+func request(t *testing.T, h http.Handler, method string, path string, body string) (int, map[string]any) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	res := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); nil != err {
+		t.Fatalf("Invalid response: %s", rec.Body.String())
+	}
+	if vs.CT_JSON != rec.Header().Get("Content-Type") {
+		t.Errorf("Unexpected content type: %s", rec.Header().Get("Content-Type"))
+	}
+	return rec.Code, res
+}
+
+func TestServer(t *testing.T) {
+
+	t.Run("server-ops", func(t *testing.T) {
+		h := server.New(nil)
+
+		cases := []struct {
+			method string
+			path   string
+			body   string
+			status int
+			out    any
+		}{
+			{"GET", "/health", ``, 200, "ok"},
+			{"POST", "/v1/ops/transform", `{"data":{"a":1},"spec":{"b":"` + "`a`" + `"}}`, 200,
+				map[string]any{"b": float64(1)}},
+			{"POST", "/v1/ops/merge", `{"val":[{"a":1},{"b":2}]}`, 200,
+				map[string]any{"a": float64(1), "b": float64(2)}},
+			{"POST", "/v1/ops/diff", `{"a":{"x":1},"b":{"x":2}}`, 200, nil},
+			{"POST", "/v1/ops/validate", `{"data":{"a":1},"spec":{"a":"` + "`$NUMBER`" + `"}}`, 200,
+				map[string]any{"a": float64(1)}},
+		}
+
+		for _, c := range cases {
+			status, res := request(t, h, c.method, c.path, c.body)
+			if c.status != status {
+				t.Errorf("%s: Expected: %v, Got: %v (%v)", c.path, c.status, status, res)
+			}
+			if nil != c.out && !reflect.DeepEqual(c.out, res["out"]) {
+				t.Errorf("%s: Expected: %v, Got: %v", c.path, c.out, res["out"])
+			}
+		}
+	})
+
+	t.Run("server-errors", func(t *testing.T) {
+		h := server.New(&server.Options{MaxBody: 64})
+
+		status, res := request(t, h, "POST", "/v1/ops/validate",
+			`{"data":{"a":"x"},"spec":{"a":"`+"`$NUMBER`"+`"}}`)
+		if 422 != status || nil == res["errs"] {
+			t.Errorf("Expected validation errors: %v %v", status, res)
+		}
+
+		status, res = request(t, h, "POST", "/v1/ops/merge", `[`)
+		if 400 != status || nil == res["err"] {
+			t.Errorf("Expected bad request: %v %v", status, res)
+		}
+
+		status, _ = request(t, h, "POST", "/v1/ops/nope", `{}`)
+		if 404 != status {
+			t.Errorf("Expected: %v, Got: %v", 404, status)
+		}
+
+		status, _ = request(t, h, "GET", "/v1/ops/merge", ``)
+		if 405 != status {
+			t.Errorf("Expected: %v, Got: %v", 405, status)
+		}
+
+		status, _ = request(t, h, "POST", "/v1/ops/merge", `{"val":"`+strings.Repeat("x", 100)+`"}`)
+		if 413 != status {
+			t.Errorf("Expected: %v, Got: %v", 413, status)
+		}
+	})
+
+	t.Run("server-specs", func(t *testing.T) {
+		dir := t.TempDir()
+		write := func(name string, src string) {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); nil != err {
+				t.Fatal(err)
+			}
+		}
+		write("orders@1.0.0.json", `{"v":1,"id":"`+"`id`"+`"}`)
+		write("orders@2.0.0.json", `{"v":2,"id":"`+"`id`"+`"}`)
+
+		reg, err := vs.NewRegistry(vs.DirLoader(dir, nil))
+		if nil != err {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		h := server.New(&server.Options{Registry: reg})
+
+		_, res := request(t, h, "GET", "/v1/specs", ``)
+		if !reflect.DeepEqual([]any{"orders"}, res["out"]) {
+			t.Errorf("Unexpected names: %v", res["out"])
+		}
+
+		status, res := request(t, h, "POST", "/v1/specs/orders/transform", `{"id":"a"}`)
+		expected := map[string]any{"v": float64(2), "id": "a"}
+		if 200 != status || !reflect.DeepEqual(expected, res["out"]) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, status, res)
+		}
+
+		_, res = request(t, h, "POST", "/v1/specs/orders@1.0.0/transform", `{"id":"b"}`)
+		expected = map[string]any{"v": float64(1), "id": "b"}
+		if !reflect.DeepEqual(expected, res["out"]) {
+			t.Errorf("Expected: %v, Got: %v", expected, res["out"])
+		}
+
+		status, _ = request(t, h, "POST", "/v1/specs/users/transform", `{}`)
+		if 404 != status {
+			t.Errorf("Expected: %v, Got: %v", 404, status)
+		}
+
+		status, _ = request(t, h, "POST", "/v1/specs/orders/validate", `{}`)
+		if 404 != status {
+			t.Errorf("Expected: %v, Got: %v", 404, status)
+		}
+	})
+
+	t.Run("server-limits", func(t *testing.T) {
+		dir := t.TempDir()
+		src := `{"n":["` + "`$RANGE`" + `",1,"` + "`count`" + `"]}`
+		if err := os.WriteFile(filepath.Join(dir, "range@1.0.0.json"), []byte(src), 0o644); nil != err {
+			t.Fatal(err)
+		}
+		reg, err := vs.NewRegistry(vs.DirLoader(dir, nil))
+		if nil != err {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// Untrusted specs are limited by default.
+		status, res := request(t, server.New(nil), "POST", "/v1/ops/transform",
+			`{"data":{},"spec":["`+"`$RANGE`"+`",0,2000000000]}`)
+		if 400 != status || !strings.Contains(res["err"].(string), "limit") {
+			t.Errorf("Expected a limit error: %v %v", status, res)
+		}
+
+		h := server.New(&server.Options{Registry: reg, Limits: &vs.Limits{MaxNodes: 10}})
+
+		status, res = request(t, h, "POST", "/v1/ops/transform",
+			`{"data":{},"spec":["`+"`$RANGE`"+`",1,20]}`)
+		if 400 != status || nil != res["out"] {
+			t.Errorf("Expected a limit error: %v %v", status, res)
+		}
+
+		status, res = request(t, h, "POST", "/v1/specs/range/transform", `{"count":3}`)
+		expected := map[string]any{"n": []any{float64(1), float64(2), float64(3)}}
+		if 200 != status || !reflect.DeepEqual(expected, res["out"]) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, status, res)
+		}
+
+		status, res = request(t, h, "POST", "/v1/specs/range/transform", `{"count":20}`)
+		if 400 != status || !strings.Contains(res["err"].(string), "limit") {
+			t.Errorf("Expected a limit error: %v %v", status, res)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Options for Stage.
+type StageOptions struct {
+	Transform *TransformOptions // Options for each record transform.
+	Policy    FailurePolicy     // As for StreamOptions.
+	Fallback  any               // As for StreamOptions.
+
+	Workers int // Number of concurrent transforms (default 1).
+	Buffer  int // Size of the output buffer (default 0, unbuffered).
+}
+
+// The result of transforming a record in a Stage.
+type Result struct {
+	Index int   // Index of the record in the input.
+	Out   any   // Output record (or fallback record).
+	Err   error // A *RecordError, if the record failed.
+}
+
+// Create a pipeline stage that transforms each record from an input
+// channel with the same spec. Records are transformed by a fixed
+// number of workers, and the output channel is closed when the input
+// channel is closed and all records are transformed. A slow reader
+// blocks the workers, which then stop reading the input, so memory
+// use is bounded by the workers and the buffer.
+//
+// With one worker, results are in input order; otherwise use the
+// result Index to restore the order. Failed records are sent with an
+// error (and the fallback record, if the policy is UseFallback). With
+// the FailFast policy, no further records are transformed after a
+// failure, and the rest of the input is discarded.
+func Stage(spec any, opts *StageOptions) func(<-chan any) <-chan Result {
+	var sopts StageOptions
+	if nil != opts {
+		sopts = *opts
+	}
+	if sopts.Workers < 1 {
+		sopts.Workers = 1
+	}
+	if sopts.Buffer < 0 {
+		sopts.Buffer = 0
+	}
+
+	s := _newStreamer(spec, &StreamOptions{
+		Transform: sopts.Transform,
+		Policy:    sopts.Policy,
+		Fallback:  sopts.Fallback,
+	})
+
+	return func(in <-chan any) <-chan Result {
+		type job struct {
+			index  int
+			record any
+		}
+
+		out := make(chan Result, sopts.Buffer)
+		jobs := make(chan job)
+		var stopped atomic.Bool
+
+		go func() {
+			defer close(jobs)
+			index := 0
+			for record := range in {
+				// Discard the input after a fail-fast failure.
+				if !stopped.Load() {
+					jobs <- job{index: index, record: record}
+				}
+				index++
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for wI := 0; wI < sopts.Workers; wI++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range jobs {
+					if stopped.Load() {
+						continue
+					}
+					rec, rerr := s.transform(j.index, j.record)
+					res := Result{Index: j.index, Out: rec}
+					if nil != rerr {
+						res.Err = rerr
+						if s.stop(rerr) {
+							stopped.Store(true)
+						}
+					}
+					out <- res
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		return out
+	}
+}
@@ -0,0 +1,87 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestStage(t *testing.T) {
+
+	spec := map[string]any{
+		"id":        "`id`",
+		"`$ASSERT`": map[string]any{"ok": true},
+	}
+
+	source := func(n int, bad int) <-chan any {
+		in := make(chan any)
+		go func() {
+			defer close(in)
+			for i := 0; i < n; i++ {
+				in <- map[string]any{"id": i, "ok": i != bad}
+			}
+		}()
+		return in
+	}
+
+	t.Run("stage-workers", func(t *testing.T) {
+		stage := voxgigstruct.Stage(spec, &voxgigstruct.StageOptions{
+			Policy:  voxgigstruct.SkipCollect,
+			Workers: 4,
+			Buffer:  2,
+		})
+
+		results := []voxgigstruct.Result{}
+		for res := range stage(source(20, 7)) {
+			results = append(results, res)
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+		if 20 != len(results) {
+			t.Fatalf("Unexpected results: %d", len(results))
+		}
+		for i, res := range results {
+			var rerr *voxgigstruct.RecordError
+			if 7 == i {
+				if !errors.As(res.Err, &rerr) || 7 != rerr.Index || nil != res.Out {
+					t.Errorf("Unexpected failure: %v", res)
+				}
+			} else if nil != res.Err || i != res.Out.(map[string]any)["id"] {
+				t.Errorf("Unexpected result: %v", res)
+			}
+		}
+	})
+
+	t.Run("stage-fail-fast", func(t *testing.T) {
+		stage := voxgigstruct.Stage(spec, nil)
+
+		results := []voxgigstruct.Result{}
+		for res := range stage(source(10, 2)) {
+			results = append(results, res)
+		}
+
+		if 3 != len(results) || nil != results[1].Err || nil == results[2].Err ||
+			2 != results[2].Index {
+			t.Errorf("Unexpected results: %v", results)
+		}
+	})
+
+	t.Run("stage-fallback", func(t *testing.T) {
+		stage := voxgigstruct.Stage(spec, &voxgigstruct.StageOptions{
+			Policy:   voxgigstruct.UseFallback,
+			Fallback: map[string]any{"id": "`id`", "failed": true},
+		})
+
+		results := []voxgigstruct.Result{}
+		for res := range stage(source(3, 1)) {
+			results = append(results, res)
+		}
+
+		if 3 != len(results) || nil == results[1].Err ||
+			true != results[1].Out.(map[string]any)["failed"] {
+			t.Errorf("Unexpected results: %v", results)
+		}
+	})
+}
@@ -0,0 +1,264 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// What to do when a record of a bulk transform fails. A record fails
+// if the transform panics, or collects errors (such as provider
+// lookup or `$ASSERT` failures).
+type FailurePolicy string
+
+const (
+	FailFast    FailurePolicy = "fail-fast" // Stop at the first failed record (the default).
+	SkipCollect FailurePolicy = "skip"      // Omit failed records, and continue.
+	UseFallback FailurePolicy = "fallback"  // Replace failed records with the fallback.
+)
+
+// Options for bulk transforms.
+type StreamOptions struct {
+	// Options for each record transform. The `$ERRS` store entry is
+	// replaced, as errors are reported per record.
+	Transform *TransformOptions
+
+	Policy FailurePolicy
+
+	// Template for fallback records. The template is transformed with
+	// the failed record as data, and the error message as `$ERROR`.
+	Fallback any
+
+	// Resume from a checkpoint: records before the checkpoint are
+	// skipped, and record indexes continue from the checkpoint count.
+	Resume *Checkpoint
+
+	// Called after each record is processed, including failed records
+	// that are skipped or replaced (but not a record that stops the
+	// transform). Save the checkpoint to resume after this record.
+	OnCheckpoint func(Checkpoint)
+}
+
+// Position in a bulk transform, after a processed record.
+type Checkpoint struct {
+	Count  int   // Number of records processed.
+	Offset int64 // Input byte offset (TransformNDJSON only).
+}
+
+// A failed record of a bulk transform.
+type RecordError struct {
+	Index  int // Index of the record in the input.
+	Record any // The input record (or source line, if not valid JSON).
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("Record %d failed: %v", e.Index, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Transform each record of a list with the same spec, applying the
+// failure policy. Returns the output records, and the errors of the
+// failed records, in order.
+func TransformList(records any, spec any, opts *StreamOptions) ([]any, []*RecordError) {
+	s := _newStreamer(spec, opts)
+
+	out := []any{}
+	errs := []*RecordError{}
+
+	list := _listify(records)
+	rI := s.start().Count
+
+	for ; rI < len(list); rI++ {
+		record := list[rI]
+		rec, rerr := s.transform(rI, record)
+		if nil != rerr {
+			errs = append(errs, rerr)
+		}
+		if s.stop(rerr) {
+			break
+		}
+		if s.keep(rerr) {
+			out = append(out, rec)
+		}
+		s.checkpoint(rI+1, 0)
+	}
+
+	return out, errs
+}
+
+// Transform newline delimited JSON records from r, writing the output
+// records to w as newline delimited JSON, and applying the failure
+// policy. Blank lines are ignored, and invalid lines are failed
+// records. Returns the errors of the failed records, and any read or
+// write error. To resume, r must be positioned at the start of the
+// input: it is moved to the checkpoint offset (by seeking, if
+// possible).
+func TransformNDJSON(r io.Reader, w io.Writer, spec any, opts *StreamOptions) ([]*RecordError, error) {
+	s := _newStreamer(spec, opts)
+	errs := []*RecordError{}
+
+	start := s.start()
+	rI := start.Count
+	offset := start.Offset
+
+	if 0 < offset {
+		if seeker, ok := r.(io.Seeker); ok {
+			if _, err := seeker.Seek(offset, io.SeekStart); nil != err {
+				return errs, err
+			}
+		} else if _, err := io.CopyN(io.Discard, r, offset); nil != err {
+			return errs, err
+		}
+	}
+
+	reader := bufio.NewReader(r)
+
+	for {
+		line, rderr := reader.ReadBytes('\n')
+		if nil != rderr && io.EOF != rderr {
+			return errs, rderr
+		}
+		offset += int64(len(line))
+
+		if 0 < len(bytes.TrimSpace(line)) {
+			var rec any
+			var rerr *RecordError
+
+			var record any
+			if jerr := json.Unmarshal(line, &record); nil != jerr {
+				rerr = &RecordError{Index: rI, Record: string(bytes.TrimSpace(line)),
+					Err: NewPathError(ErrSpec, nil, jerr, "Invalid JSON record")}
+				rec = s.fallback(record, rerr)
+			} else {
+				rec, rerr = s.transform(rI, record)
+			}
+
+			if nil != rerr {
+				errs = append(errs, rerr)
+			}
+			if s.stop(rerr) {
+				return errs, nil
+			}
+			if s.keep(rerr) {
+				b, err := json.Marshal(rec)
+				if nil != err {
+					return errs, err
+				}
+				if _, err := w.Write(append(b, '\n')); nil != err {
+					return errs, err
+				}
+			}
+
+			rI++
+			s.checkpoint(rI, offset)
+		}
+
+		if io.EOF == rderr {
+			return errs, nil
+		}
+	}
+}
+
+type streamer struct {
+	transformer *Transformer
+	opts        StreamOptions
+}
+
+func _newStreamer(spec any, opts *StreamOptions) *streamer {
+	s := &streamer{}
+	if nil != opts {
+		s.opts = *opts
+	}
+	if S_MT == s.opts.Policy {
+		s.opts.Policy = FailFast
+	}
+	s.transformer = NewTransformer(spec, s.opts.Transform)
+	return s
+}
+
+// Transform a record, applying the fallback on failure.
+func (s *streamer) transform(index int, record any) (any, *RecordError) {
+	out, err := _transformRecord(s.transformer.spec, record, s.transformer.opts, nil)
+	if nil == err {
+		return out, nil
+	}
+	rerr := &RecordError{Index: index, Record: record, Err: err}
+	return s.fallback(record, rerr), rerr
+}
+
+// The fallback record for a failure, if the policy uses one.
+func (s *streamer) fallback(record any, rerr *RecordError) any {
+	if UseFallback != s.opts.Policy {
+		return nil
+	}
+	out, err := _transformRecord(s.opts.Fallback, record, s.transformer.opts,
+		map[string]any{"$ERROR": rerr.Err.Error()})
+	if nil != err {
+		// A broken fallback template should not hide the record.
+		return Clone(s.opts.Fallback)
+	}
+	return out
+}
+
+func (s *streamer) start() Checkpoint {
+	if nil == s.opts.Resume {
+		return Checkpoint{}
+	}
+	return *s.opts.Resume
+}
+
+func (s *streamer) checkpoint(count int, offset int64) {
+	if nil != s.opts.OnCheckpoint {
+		s.opts.OnCheckpoint(Checkpoint{Count: count, Offset: offset})
+	}
+}
+
+func (s *streamer) stop(rerr *RecordError) bool {
+	return nil != rerr && FailFast == s.opts.Policy
+}
+
+func (s *streamer) keep(rerr *RecordError) bool {
+	return nil == rerr || UseFallback == s.opts.Policy
+}
+
+// Transform a single record, converting panics and collected errors
+// into an error.
+func _transformRecord(spec any, record any, opts TransformOptions, extra map[string]any) (out any, err error) {
+	errs := ListRefCreate[any]()
+
+	store := _extraWith(opts.Extra, extra)
+	store[S_DERRS] = errs
+	opts.Extra = store
+
+	defer func() {
+		if r := recover(); nil != r {
+			out = nil
+			err = fmt.Errorf("transform panic: %v", r)
+		}
+	}()
+
+	out = TransformWith(record, spec, &opts)
+
+	if 0 < len(errs.List) {
+		all := make([]error, len(errs.List))
+		for eI, e := range errs.List {
+			if ee, ok := e.(error); ok {
+				all[eI] = ee
+			} else {
+				all[eI] = errors.New(Stringify(e))
+			}
+		}
+		return nil, errors.Join(all...)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,135 @@
+package voxgigstruct_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestStream(t *testing.T) {
+
+	spec := map[string]any{
+		"id":        "`id`",
+		"`$ASSERT`": map[string]any{"ok": true},
+	}
+
+	records := []any{
+		map[string]any{"id": 1, "ok": true},
+		map[string]any{"id": 2, "ok": false},
+		map[string]any{"id": 3, "ok": true},
+	}
+
+	t.Run("stream-fail-fast", func(t *testing.T) {
+		out, errs := voxgigstruct.TransformList(records, spec, nil)
+		if !reflect.DeepEqual([]any{map[string]any{"id": 1}}, out) {
+			t.Errorf("Unexpected: %v", out)
+		}
+		if 1 != len(errs) || 1 != errs[0].Index ||
+			!strings.Contains(errs[0].Error(), "Assertion failed at ok") {
+			t.Errorf("Unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("stream-skip", func(t *testing.T) {
+		out, errs := voxgigstruct.TransformList(records, spec,
+			&voxgigstruct.StreamOptions{Policy: voxgigstruct.SkipCollect})
+		if !reflect.DeepEqual([]any{map[string]any{"id": 1}, map[string]any{"id": 3}}, out) {
+			t.Errorf("Unexpected: %v", out)
+		}
+		if 1 != len(errs) || 1 != errs[0].Index || 2 != errs[0].Record.(map[string]any)["id"] {
+			t.Errorf("Unexpected errors: %v", errs)
+		}
+
+		// Panics are failures too.
+		panics := map[string]any{
+			"$BAD": func() any { panic("bad") },
+		}
+		out, errs = voxgigstruct.TransformList(records, map[string]any{"x": "`$BAD`"},
+			&voxgigstruct.StreamOptions{
+				Policy:    voxgigstruct.SkipCollect,
+				Transform: &voxgigstruct.TransformOptions{Extra: panics},
+			})
+		if 0 != len(out) || 3 != len(errs) || !strings.Contains(errs[2].Error(), "bad") {
+			t.Errorf("Unexpected: %v %v", out, errs)
+		}
+	})
+
+	t.Run("stream-fallback", func(t *testing.T) {
+		out, errs := voxgigstruct.TransformList(records, spec, &voxgigstruct.StreamOptions{
+			Policy:   voxgigstruct.UseFallback,
+			Fallback: map[string]any{"id": "`id`", "error": "`$ERROR`"},
+		})
+		expected := []any{
+			map[string]any{"id": 1},
+			map[string]any{"id": 2, "error": "Assertion failed at ok: expected true, but found false."},
+			map[string]any{"id": 3},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+		if 1 != len(errs) {
+			t.Errorf("Unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("stream-ndjson", func(t *testing.T) {
+		in := strings.NewReader("{\"id\":1,\"ok\":true}\n\n{\"id\":2,\"ok\":false}\n{bad\n{\"id\":3,\"ok\":true}")
+		var w bytes.Buffer
+		errs, err := voxgigstruct.TransformNDJSON(in, &w, spec,
+			&voxgigstruct.StreamOptions{Policy: voxgigstruct.SkipCollect})
+		if nil != err {
+			t.Fatal(err)
+		}
+		if "{\"id\":1}\n{\"id\":3}\n" != w.String() {
+			t.Errorf("Unexpected: %q", w.String())
+		}
+		if 2 != len(errs) || 1 != errs[0].Index || 2 != errs[1].Index ||
+			"{bad" != errs[1].Record || !errors.Is(errs[1], voxgigstruct.ErrSpec) {
+			t.Errorf("Unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("stream-checkpoint", func(t *testing.T) {
+		src := "{\"id\":1,\"ok\":true}\n{\"id\":2,\"ok\":false}\n\n{\"id\":3,\"ok\":true}\n"
+		opts := &voxgigstruct.StreamOptions{Policy: voxgigstruct.SkipCollect}
+
+		checkpoints := []voxgigstruct.Checkpoint{}
+		opts.OnCheckpoint = func(cp voxgigstruct.Checkpoint) {
+			checkpoints = append(checkpoints, cp)
+		}
+
+		var w bytes.Buffer
+		if _, err := voxgigstruct.TransformNDJSON(strings.NewReader(src), &w, spec, opts); nil != err {
+			t.Fatal(err)
+		}
+		expected := []voxgigstruct.Checkpoint{{Count: 1, Offset: 19}, {Count: 2, Offset: 39}, {Count: 3, Offset: 59}}
+		if !reflect.DeepEqual(expected, checkpoints) {
+			t.Errorf("Expected: %v, Got: %v", expected, checkpoints)
+		}
+
+		// Resume after the first record, with and without seeking.
+		for _, r := range []io.Reader{strings.NewReader(src), io.MultiReader(strings.NewReader(src))} {
+			w.Reset()
+			opts.Resume = &checkpoints[0]
+			errs, err := voxgigstruct.TransformNDJSON(r, &w, spec, opts)
+			if nil != err {
+				t.Fatal(err)
+			}
+			if "{\"id\":3}\n" != w.String() || 1 != len(errs) || 1 != errs[0].Index {
+				t.Errorf("Unexpected: %q %v", w.String(), errs)
+			}
+		}
+
+		// Lists resume by count.
+		opts.Resume = &voxgigstruct.Checkpoint{Count: 2}
+		out, _ := voxgigstruct.TransformList(records, spec, opts)
+		if !reflect.DeepEqual([]any{map[string]any{"id": 3}}, out) {
+			t.Errorf("Unexpected: %v", out)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// Select a sub-spec by a value. Format: ['`$SWITCH`', value, cases,
+// default?]. The value is injected (usually a source path, such as
+// "`event.type`"), and selects the case with the same key (numbers
+// and booleans match their string form). The selected case, or else
+// the default, is injected in place of the `$SWITCH`, as if it were
+// part of the spec. If no case matches and there is no default, there
+// is no output.
+//
+//	{ "shape": ['`$SWITCH`', '`type`', {
+//	    "circle": { "r": '`radius`' },
+//	    "square": { "side": '`width`' } },
+//	  { "unknown": '`type`' }] }
+var Transform_SWITCH Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$SWITCH", 2)
+	if !ok {
+		return nil
+	}
+
+	cases := args[1]
+	if !IsMap(cases) {
+		state.Warn("switch-args", "Invalid cases for $SWITCH: "+Stringify(cases))
+		return _replaceTransform(state, nil)
+	}
+
+	branch := GetProp(args, 2)
+	if key, ok := _switchKey(_injectArg(args[0], store, current, state)); ok && HasKey(cases, key) {
+		branch = GetProp(cases, key)
+	}
+	if nil == branch {
+		return _replaceTransform(state, nil)
+	}
+
+	// The branch is injected as a child of the current node.
+	bval := Clone(branch)
+	bcur := map[string]any{S_DTOP: current}
+	out := InjectDescend(bval, store, state.Modify, bcur, _nestedState(bval, store, state))
+
+	return _replaceTransform(state, out)
+}
+
+// The case key of a `$SWITCH` value. Only scalars have a key.
+func _switchKey(val any) (string, bool) {
+	if s, ok := val.(string); ok {
+		return s, true
+	}
+	if IsNode(val) || IsFunc(val) || nil == val {
+		return S_MT, false
+	}
+	return Stringify(val), true
+}
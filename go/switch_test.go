@@ -0,0 +1,108 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestSwitch(t *testing.T) {
+
+	cases := map[string]any{
+		"click": map[string]any{"kind": "click", "at": []any{"`x`", "`y`"}},
+		"key":   map[string]any{"kind": "key", "code": "`code`"},
+	}
+
+	t.Run("switch-case", func(t *testing.T) {
+		spec := map[string]any{
+			"event": []any{"`$SWITCH`", "`type`", cases, map[string]any{"kind": "other", "type": "`type`"}},
+		}
+
+		out := voxgigstruct.Transform(map[string]any{"type": "click", "x": 1, "y": 2}, spec)
+		expected := map[string]any{"event": map[string]any{"kind": "click", "at": []any{1, 2}}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		out = voxgigstruct.Transform(map[string]any{"type": "key", "code": 13}, spec)
+		expected = map[string]any{"event": map[string]any{"kind": "key", "code": 13}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		out = voxgigstruct.Transform(map[string]any{"type": "scroll"}, spec)
+		expected = map[string]any{"event": map[string]any{"kind": "other", "type": "scroll"}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("switch-no-default", func(t *testing.T) {
+		spec := map[string]any{
+			"a":     1,
+			"event": []any{"`$SWITCH`", "`type`", cases},
+		}
+
+		out := voxgigstruct.Transform(map[string]any{"type": "scroll"}, spec)
+		expected := map[string]any{"a": 1}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		out = voxgigstruct.Transform(map[string]any{}, spec)
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("switch-scalar-key", func(t *testing.T) {
+		spec := map[string]any{
+			"status": []any{"`$SWITCH`", "`code`", map[string]any{
+				"200":  "ok",
+				"true": "yes",
+			}, "error"},
+		}
+
+		for _, c := range []struct {
+			code     any
+			expected any
+		}{{200, "ok"}, {true, "yes"}, {404, "error"}, {map[string]any{}, "error"}} {
+			out := voxgigstruct.Transform(map[string]any{"code": c.code}, spec)
+			if c.expected != voxgigstruct.GetProp(out, "status") {
+				t.Errorf("Expected: %v, Got: %v", c.expected, out)
+			}
+		}
+	})
+
+	t.Run("switch-each", func(t *testing.T) {
+		out := voxgigstruct.Transform(map[string]any{
+			"events": []any{
+				map[string]any{"type": "key", "code": 27},
+				map[string]any{"type": "click", "x": 3, "y": 4},
+			},
+		}, map[string]any{
+			"events": []any{"`$EACH`", "events", []any{"`$SWITCH`", "`.type`", map[string]any{
+				"click": map[string]any{"x": "`.x`", "y": "`.y`"},
+				"key":   map[string]any{"code": "`.code`"},
+			}}},
+		})
+		expected := map[string]any{"events": []any{
+			map[string]any{"code": 27},
+			map[string]any{"x": 3, "y": 4},
+		}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("switch-args", func(t *testing.T) {
+		result := voxgigstruct.TransformCollect(map[string]any{"type": "a"}, map[string]any{
+			"x": []any{"`$SWITCH`", "`type`", "a"},
+			"y": []any{"`$SWITCH`"},
+		}, nil)
+		if 1 != len(result.Warnings) || "switch-args" != result.Warnings[0].Code || 1 != len(result.Errs) {
+			t.Errorf("Unexpected result: %v %v %v", result.Out, result.Warnings, result.Errs)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"html"
+	"strings"
+)
+
+// Render a list of maps as a Markdown table, for reports. Each column
+// is a dotted path into the items (as for GetPath), and is also the
+// column heading. If no columns are given, the columns are the keys
+// of the items, in order of appearance (sorted within each map), as
+// for CSV encoding. Strings are shown as is, missing values are
+// empty, and other values are shown with Stringify. Pipes are
+// escaped, and newlines are shown as `<br>`.
+func ToMarkdownTable(list any, columns []string) (string, error) {
+	header, rows, err := _tableRows(list, columns)
+	if nil != err {
+		return S_MT, err
+	}
+
+	var sb strings.Builder
+	_markdownRow(&sb, header)
+
+	sb.WriteString("|")
+	for range header {
+		sb.WriteString(" --- |")
+	}
+	sb.WriteString("\n")
+
+	for _, row := range rows {
+		_markdownRow(&sb, row)
+	}
+
+	return sb.String(), nil
+}
+
+// Render a list of maps as an HTML table, with the same columns and
+// cell values as ToMarkdownTable. Headings and cells are escaped.
+func ToHTMLTable(list any, columns []string) (string, error) {
+	header, rows, err := _tableRows(list, columns)
+	if nil != err {
+		return S_MT, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<table>\n<thead>\n")
+	_htmlRow(&sb, "th", header)
+	sb.WriteString("</thead>\n<tbody>\n")
+	for _, row := range rows {
+		_htmlRow(&sb, "td", row)
+	}
+	sb.WriteString("</tbody>\n</table>\n")
+
+	return sb.String(), nil
+}
+
+// The header and the cell text of the rows of a table.
+func _tableRows(list any, columns []string) ([]string, [][]string, error) {
+	if !IsList(list) {
+		return nil, nil, NewPathError(ErrType, nil, nil, "A table requires a list, not: %s", Typify(list))
+	}
+	items := _listify(list)
+
+	header := columns
+	paths := make([]any, len(columns))
+	for cI, col := range columns {
+		path, err := CompilePath(col)
+		if nil != err {
+			return nil, nil, err
+		}
+		paths[cI] = path
+	}
+
+	if 0 == len(header) {
+		seen := map[string]bool{}
+		for _, item := range items {
+			for _, key := range KeysOf(item) {
+				if !seen[key] {
+					seen[key] = true
+					header = append(header, key)
+					paths = append(paths, []string{key})
+				}
+			}
+		}
+	}
+
+	rows := make([][]string, len(items))
+	for iI, item := range items {
+		if !IsMap(item) {
+			return nil, nil, NewPathError(ErrType, []string{StrKey(iI)}, nil,
+				"A table requires a list of maps, not: %s", Typify(item))
+		}
+		row := make([]string, len(header))
+		for cI, path := range paths {
+			row[cI] = _tableCell(GetPath(path, item))
+		}
+		rows[iI] = row
+	}
+
+	return header, rows, nil
+}
+
+func _tableCell(val any) string {
+	if s, ok := val.(string); ok {
+		return s
+	}
+	return Stringify(val)
+}
+
+func _markdownRow(sb *strings.Builder, cells []string) {
+	sb.WriteString("|")
+	for _, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", "\\|")
+		cell = strings.ReplaceAll(strings.ReplaceAll(cell, "\r\n", "\n"), "\n", "<br>")
+		sb.WriteString(" " + cell + " |")
+	}
+	sb.WriteString("\n")
+}
+
+func _htmlRow(sb *strings.Builder, tag string, cells []string) {
+	sb.WriteString("<tr>")
+	for _, cell := range cells {
+		sb.WriteString("<" + tag + ">" + html.EscapeString(cell) + "</" + tag + ">")
+	}
+	sb.WriteString("</tr>\n")
+}
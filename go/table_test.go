@@ -0,0 +1,67 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestTable(t *testing.T) {
+
+	list := []any{
+		map[string]any{"name": "ann", "stats": map[string]any{"runs": 3}, "tags": []any{"a", "b"}},
+		map[string]any{"name": "b|c", "stats": map[string]any{"runs": 1.5}, "note": "x\ny"},
+	}
+
+	t.Run("table-markdown", func(t *testing.T) {
+		out, err := voxgigstruct.ToMarkdownTable(list, []string{"name", "stats.runs", "tags", "missing"})
+		expected := "| name | stats.runs | tags | missing |\n" +
+			"| --- | --- | --- | --- |\n" +
+			"| ann | 3 | [a,b] |  |\n" +
+			"| b\\|c | 1.5 |  |  |\n"
+		if nil != err || expected != out {
+			t.Errorf("Expected: %q, Got: %q %v", expected, out, err)
+		}
+	})
+
+	t.Run("table-markdown-keys", func(t *testing.T) {
+		out, _ := voxgigstruct.ToMarkdownTable(list, nil)
+		expected := "| name | stats | tags | note |\n" +
+			"| --- | --- | --- | --- |\n" +
+			"| ann | {runs:3} | [a,b] |  |\n" +
+			"| b\\|c | {runs:1.5} |  | x<br>y |\n"
+		if expected != out {
+			t.Errorf("Expected: %q, Got: %q", expected, out)
+		}
+	})
+
+	t.Run("table-html", func(t *testing.T) {
+		out, err := voxgigstruct.ToHTMLTable([]any{
+			map[string]any{"a.b": "<i>", "c": map[string]any{"d": true}},
+		}, []string{`a\.b`, "c.d"})
+		expected := "<table>\n<thead>\n" +
+			"<tr><th>a\\.b</th><th>c.d</th></tr>\n" +
+			"</thead>\n<tbody>\n" +
+			"<tr><td>&lt;i&gt;</td><td>true</td></tr>\n" +
+			"</tbody>\n</table>\n"
+		if nil != err || expected != out {
+			t.Errorf("Expected: %q, Got: %q %v", expected, out, err)
+		}
+	})
+
+	t.Run("table-error", func(t *testing.T) {
+		_, err := voxgigstruct.ToMarkdownTable(map[string]any{}, nil)
+		if !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected: %v, Got: %v", voxgigstruct.ErrType, err)
+		}
+		_, err = voxgigstruct.ToHTMLTable([]any{1}, nil)
+		if !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected: %v, Got: %v", voxgigstruct.ErrType, err)
+		}
+		_, err = voxgigstruct.ToHTMLTable([]any{}, []string{"a..b"})
+		if !errors.Is(err, voxgigstruct.ErrSpec) {
+			t.Errorf("Expected: %v, Got: %v", voxgigstruct.ErrSpec, err)
+		}
+	})
+}
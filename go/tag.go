@@ -0,0 +1,113 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Keys of a tagged leaf value: `{"$type":"time","value":"..."}`.
+const (
+	S_DTYPE  = "$type"
+	S_TVALUE = "value"
+)
+
+// Encode a node as JSON, replacing leaves that have a type tag (see
+// LeafType) with tagged values, so that FromJSON can restore them.
+// Functions are removed.
+func ToJSON(val any) ([]byte, error) {
+	return json.Marshal(CloneFlags(val, map[string]bool{"tag": true, "func": false}))
+}
+
+// Decode JSON, restoring tagged leaf values (see ToJSON). Tagged
+// values with an unknown tag are left as they are.
+func FromJSON(data []byte) (any, error) {
+	var val any
+	if err := json.Unmarshal(data, &val); nil != err {
+		return nil, NewPathError(ErrType, nil, err, "Invalid JSON")
+	}
+	return Untag(val)
+}
+
+// Copy of a node with tagged leaf values (see ToJSON) restored.
+func Untag(val any) (any, error) {
+	return _untag(val, []string{})
+}
+
+func _untag(val any, path []string) (any, error) {
+	switch v := val.(type) {
+	case map[string]any:
+		if tag, ok := v[S_DTYPE].(string); ok && 2 == len(v) && HasKey(v, S_TVALUE) {
+			if leaf, typ, ok := _leafTypeByTag(tag); ok {
+				out, err := _untagLeaf(v[S_TVALUE], leaf, typ)
+				if nil != err {
+					return nil, NewPathError(ErrType, path, err, "Invalid %s value", tag)
+				}
+				return out, nil
+			}
+		}
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			restored, err := _untag(child, append(path, key))
+			if nil != err {
+				return nil, err
+			}
+			out[key] = restored
+		}
+		return out, nil
+
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			restored, err := _untag(child, append(path, StrKey(i)))
+			if nil != err {
+				return nil, err
+			}
+			out[i] = restored
+		}
+		return out, nil
+	}
+
+	return val, nil
+}
+
+func _tagLeaf(val any, leaf LeafType) any {
+	var value any
+	if nil != leaf.Value {
+		value = leaf.Value(val)
+	} else if b, err := json.Marshal(val); nil == err {
+		json.Unmarshal(b, &value)
+	}
+	return map[string]any{S_DTYPE: leaf.Tag, S_TVALUE: value}
+}
+
+func _untagLeaf(value any, leaf LeafType, typ reflect.Type) (any, error) {
+	if nil != leaf.Untag {
+		return leaf.Untag(value)
+	}
+	b, err := json.Marshal(value)
+	if nil != err {
+		return nil, err
+	}
+	out := reflect.New(typ)
+	if err := json.Unmarshal(b, out.Interface()); nil != err {
+		return nil, err
+	}
+	return out.Elem().Interface(), nil
+}
+
+func _leafTypeByTag(tag string) (LeafType, reflect.Type, bool) {
+	// Types with the same tag are chosen by type name, not map order.
+	var found reflect.Type
+	types := *leafTypes.Load()
+	for typ, leaf := range types {
+		if tag == leaf.Tag && (nil == found || typ.String() < found.String()) {
+			found = typ
+		}
+	}
+	if nil == found {
+		return LeafType{}, nil, false
+	}
+	return types[found], found, true
+}
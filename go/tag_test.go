@@ -0,0 +1,88 @@
+package voxgigstruct_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/voxgig/struct"
+)
+
+type tagPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func TestTag(t *testing.T) {
+
+	when := time.Date(2025, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	voxgigstruct.RegisterLeafType(tagPoint{}, voxgigstruct.LeafType{Tag: "point"})
+
+	t.Run("tag-clone", func(t *testing.T) {
+		val := map[string]any{"when": when, "n": 1, "list": []any{[]byte("ab")}}
+		out := voxgigstruct.CloneFlags(val, map[string]bool{"tag": true})
+
+		expected := map[string]any{
+			"when": map[string]any{"$type": "time", "value": "2025-01-02T03:04:05.0000006Z"},
+			"n":    1,
+			"list": []any{map[string]any{"$type": "bytes", "value": "YWI="}},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		// Not tagged by default.
+		if _, ok := voxgigstruct.Clone(val).(map[string]any)["when"].(time.Time); !ok {
+			t.Errorf("Expected: %v, Got: %v", "time.Time", voxgigstruct.Clone(val))
+		}
+	})
+
+	t.Run("tag-json-roundtrip", func(t *testing.T) {
+		val := map[string]any{
+			"when":  when,
+			"num":   json.Number("12345678901234567890.5"),
+			"raw":   []byte{0, 1, 2},
+			"point": tagPoint{X: 1, Y: 2},
+			"plain": []any{"a", 1.5, true, nil},
+		}
+
+		data, err := voxgigstruct.ToJSON(val)
+		if nil != err {
+			t.Fatalf("Expected: %v, Got: %v", nil, err)
+		}
+
+		out, err := voxgigstruct.FromJSON(data)
+		if nil != err || !reflect.DeepEqual(val, out) {
+			t.Errorf("Expected: %v, Got: %v %v", val, out, err)
+		}
+	})
+
+	t.Run("tag-untag", func(t *testing.T) {
+		// Unknown tags, and maps with other keys, are left as they are.
+		val := map[string]any{
+			"a": map[string]any{"$type": "unknown", "value": 1},
+			"b": map[string]any{"$type": "time", "value": "x", "other": 1},
+		}
+		out, err := voxgigstruct.Untag(val)
+		if nil != err || !reflect.DeepEqual(val, out) {
+			t.Errorf("Expected: %v, Got: %v %v", val, out, err)
+		}
+
+		_, err = voxgigstruct.Untag(map[string]any{
+			"a": []any{map[string]any{"$type": "time", "value": "x"}},
+		})
+		var perr *voxgigstruct.PathError
+		if !errors.Is(err, voxgigstruct.ErrType) || !errors.As(err, &perr) ||
+			!reflect.DeepEqual([]string{"a", "0"}, perr.Path) {
+			t.Errorf("Expected: %v, Got: %v", "a.0", err)
+		}
+
+		_, err = voxgigstruct.FromJSON([]byte("{"))
+		if !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected: %v, Got: %v", voxgigstruct.ErrType, err)
+		}
+	})
+}
@@ -83,12 +83,31 @@ var (
 
 // MakeRunner creates a runner function that can be used to run tests
 func MakeRunner(testfile string, client Client) func(name string, store any) (*RunPack, error) {
+	return makeRunner(func(name string) map[string]any {
+		return resolveSpec(name, testfile)
+	}, client)
+}
+
+
+// MakeRunnerData creates a runner function for test model JSON that is
+// already loaded (for example, embedded with go:embed).
+func MakeRunnerData(data []byte, client Client) func(name string, store any) (*RunPack, error) {
+	return makeRunner(func(name string) map[string]any {
+		return resolveSpecData(name, data)
+	}, client)
+}
+
+
+func makeRunner(
+	resolve func(name string) map[string]any,
+	client Client,
+) func(name string, store any) (*RunPack, error) {
 
 	return func(name string, store any) (*RunPack, error) {
 		utility := client.Utility()
 		structUtil := utility.Struct()
 
-		spec := resolveSpec(name, testfile)
+		spec := resolve(name)
 
 		clients, err := resolveClients(spec, store, structUtil, client)
 		if err != nil {
@@ -120,7 +139,6 @@ func MakeRunner(testfile string, client Client) func(name string, store any) (*R
 			testset, ok := testspecmap["set"].([]any)
 			if !ok {
 				panic(fmt.Sprintf("No test set in %v", name))
-				return
 			}
 
 			for _, entryVal := range testset {
@@ -184,6 +202,15 @@ func resolveSpec(
 		panic(err)
 	}
 
+	return resolveSpecData(name, data)
+}
+
+
+func resolveSpecData(
+	name string,
+	data []byte,
+) map[string]any {
+
 	var alltests map[string]any
 	if err := json.Unmarshal(data, &alltests); err != nil {
 		panic(err)
@@ -0,0 +1,348 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A transform specification prepared for repeated use. The spec is
+// cloned when the Transformer is created, so later changes to the
+// original spec have no effect. A Transformer is safe for concurrent
+// use.
+type Transformer struct {
+	Name    string // Name of the spec, if known.
+	Version string // Version of the spec, if known.
+	Source  string // Where the spec was loaded from, if anywhere.
+	Hash    string // SHA-256 of the spec source (hex), if loaded.
+
+	spec any
+	opts TransformOptions
+}
+
+// Create a Transformer for a spec. The options (if any) are used for
+// each transform.
+func NewTransformer(spec any, opts *TransformOptions) *Transformer {
+	t := &Transformer{spec: Clone(spec)}
+	if nil != opts {
+		t.opts = *opts
+	}
+	return t
+}
+
+// Transform data using the spec.
+func (t *Transformer) Transform(data any) any {
+	opts := t.opts
+	return TransformWith(data, t.spec, &opts)
+}
+
+// Transform data using the spec, collecting the errors and warnings
+// (see TransformCollect). The limits and quota, if not nil, replace
+// those of the options, such as for untrusted data.
+func (t *Transformer) TransformCollect(data any, limits *Limits, quota *Quota) *TransformResult {
+	opts := t.opts
+	if nil != limits {
+		opts.Limits = limits
+	}
+	if nil != quota {
+		opts.Quota = quota
+	}
+	return TransformCollect(data, t.spec, &opts)
+}
+
+// A copy of the spec.
+func (t *Transformer) Spec() any {
+	return Clone(t.spec)
+}
+
+// Default maximum size of a spec source (see LoadOptions.MaxBytes).
+const DefaultMaxSpecBytes = 10 << 20
+
+// Default timeout for fetching a remote spec (see LoadOptions.Client).
+const DefaultSpecTimeout = 30 * time.Second
+
+var _specClient = &http.Client{Timeout: DefaultSpecTimeout}
+
+// Options for LoadSpec.
+type LoadOptions struct {
+	// Expected SHA-256 of the spec source (hex). If set, the source
+	// must match, otherwise an ErrSpec error is returned.
+	SHA256 string
+
+	// Verify the spec source (for example, check a signature). If an
+	// error is returned, the spec is not loaded.
+	Verify func(src []byte) error
+
+	// Content type of the spec source. The default is taken from the
+	// HTTP response, or is JSON.
+	ContentType string
+
+	// HTTP client for remote specs (default: a client with a timeout
+	// of DefaultSpecTimeout).
+	Client *http.Client
+
+	// Allow plain http URLs. Only https is allowed by default.
+	AllowHTTP bool
+
+	// Maximum size of the spec source, in bytes (default:
+	// DefaultMaxSpecBytes). Larger sources are an ErrLimit error.
+	MaxBytes int64
+
+	// Cache of loaded specs, if any.
+	Cache *SpecCache
+
+	// Options used by the Transformer.
+	Transform *TransformOptions
+}
+
+// A cache of loaded specs, by source. Entries are reused until they
+// are older than the TTL (zero means forever). Remote specs are then
+// revalidated with their ETag, if the server provided one. Only the
+// spec is cached: each load creates a new Transformer with its own
+// options, and the SHA256 and Verify checks of LoadOptions are applied
+// to cached sources as well, so a cache can be shared by loads with
+// different options.
+type SpecCache struct {
+	TTL time.Duration
+
+	mutex   sync.Mutex
+	entries map[string]*specCacheEntry
+}
+
+type specCacheEntry struct {
+	content []byte
+	srctype string // Content type of the source, if known.
+	ctype   string // Content type of the spec, as decoded.
+	spec    any
+	etag    string
+	loaded  time.Time
+}
+
+// Create a spec cache.
+func NewSpecCache(ttl time.Duration) *SpecCache {
+	return &SpecCache{TTL: ttl, entries: map[string]*specCacheEntry{}}
+}
+
+func (c *SpecCache) get(src string) *specCacheEntry {
+	if nil == c {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.entries[src]
+}
+
+func (c *SpecCache) put(src string, entry *specCacheEntry) {
+	if nil == c {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if nil == c.entries {
+		c.entries = map[string]*specCacheEntry{}
+	}
+	c.entries[src] = entry
+}
+
+func (c *SpecCache) fresh(entry *specCacheEntry) bool {
+	return 0 == c.TTL || time.Since(entry.loaded) < c.TTL
+}
+
+// Load a transform spec from a URL (https, or http if allowed) or a
+// file path (optionally as a file URL), verify its integrity, and
+// return a Transformer.
+func LoadSpec(src string, opts *LoadOptions) (*Transformer, error) {
+	if nil == opts {
+		opts = &LoadOptions{}
+	}
+
+	entry := opts.Cache.get(src)
+	if nil != entry && opts.Cache.fresh(entry) {
+		return _buildSpec(entry, src, opts)
+	}
+
+	var content []byte
+	var ctype, etag string
+	var err error
+
+	if strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://") {
+		prevtag := S_MT
+		if nil != entry {
+			prevtag = entry.etag
+		}
+		content, ctype, etag, err = _fetchSpec(src, prevtag, opts)
+		if nil != err {
+			return nil, err
+		}
+
+		// Not modified.
+		if nil == content && nil != entry {
+			entry = &specCacheEntry{
+				content: entry.content,
+				srctype: entry.srctype,
+				ctype:   entry.ctype,
+				spec:    entry.spec,
+				etag:    entry.etag,
+				loaded:  time.Now(),
+			}
+			opts.Cache.put(src, entry)
+			return _buildSpec(entry, src, opts)
+		}
+
+	} else {
+		path := strings.TrimPrefix(src, "file://")
+		file, ferr := os.Open(path)
+		if nil != ferr {
+			return nil, NewPathError(ErrNotFound, nil, ferr, "Cannot load spec: %s", src)
+		}
+		content, err = _readSpec(file, src, opts)
+		file.Close()
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	hash, err := _checkSpec(content, src, opts)
+	if nil != err {
+		return nil, err
+	}
+
+	entry = &specCacheEntry{content: content, srctype: ctype, etag: etag, loaded: time.Now()}
+	entry.ctype = _specType(entry, opts)
+
+	spec, err := Decode(bytes.NewReader(content), entry.ctype)
+	if nil != err {
+		return nil, NewPathError(ErrSpec, nil, err, "Invalid spec: %s", src)
+	}
+	entry.spec = spec
+
+	opts.Cache.put(src, entry)
+
+	return _newSpecTransformer(spec, src, hash, opts), nil
+}
+
+// Create a Transformer for a cached spec, using the options of this
+// load. The spec source is checked again, and decoded again if the
+// options give a different content type.
+func _buildSpec(entry *specCacheEntry, src string, opts *LoadOptions) (*Transformer, error) {
+	hash, err := _checkSpec(entry.content, src, opts)
+	if nil != err {
+		return nil, err
+	}
+
+	spec := entry.spec
+	if ctype := _specType(entry, opts); ctype != entry.ctype {
+		spec, err = Decode(bytes.NewReader(entry.content), ctype)
+		if nil != err {
+			return nil, NewPathError(ErrSpec, nil, err, "Invalid spec: %s", src)
+		}
+	}
+
+	return _newSpecTransformer(spec, src, hash, opts), nil
+}
+
+// The content type of a spec: from the options, else the source, else JSON.
+func _specType(entry *specCacheEntry, opts *LoadOptions) string {
+	if S_MT != opts.ContentType {
+		return opts.ContentType
+	}
+	if S_MT != entry.srctype {
+		return entry.srctype
+	}
+	return CT_JSON
+}
+
+func _newSpecTransformer(spec any, src string, hash string, opts *LoadOptions) *Transformer {
+	t := NewTransformer(spec, opts.Transform)
+	t.Source = src
+	t.Hash = hash
+	return t
+}
+
+// Check the integrity of a spec source, returning its hash.
+func _checkSpec(content []byte, src string, opts *LoadOptions) (string, error) {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	if S_MT != opts.SHA256 && !strings.EqualFold(opts.SHA256, hash) {
+		return hash, NewPathError(ErrSpec, nil, nil,
+			"Spec integrity check failed: %s (expected sha256 %s, found %s)", src, opts.SHA256, hash)
+	}
+
+	if nil != opts.Verify {
+		if err := opts.Verify(content); nil != err {
+			return hash, NewPathError(ErrSpec, nil, err, "Spec verification failed: %s", src)
+		}
+	}
+
+	return hash, nil
+}
+
+// Read a spec source, up to the maximum size.
+func _readSpec(r io.Reader, src string, opts *LoadOptions) ([]byte, error) {
+	max := opts.MaxBytes
+	if max <= 0 {
+		max = DefaultMaxSpecBytes
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, max+1))
+	if nil != err {
+		return nil, NewPathError(ErrNotFound, nil, err, "Cannot load spec: %s", src)
+	}
+	if max < int64(len(content)) {
+		return nil, NewPathError(ErrLimit, nil, nil,
+			"Spec is too large: %s (maximum %d bytes)", src, max)
+	}
+
+	return content, nil
+}
+
+// Fetch a remote spec. Returns nil content if not modified.
+func _fetchSpec(src string, etag string, opts *LoadOptions) ([]byte, string, string, error) {
+	if strings.HasPrefix(src, "http://") && !opts.AllowHTTP {
+		return nil, S_MT, S_MT, NewPathError(ErrSpec, nil, nil,
+			"Insecure spec URL (use https, or set AllowHTTP): %s", src)
+	}
+
+	client := opts.Client
+	if nil == client {
+		client = _specClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, src, nil)
+	if nil != err {
+		return nil, S_MT, S_MT, NewPathError(ErrSpec, nil, err, "Invalid spec URL: %s", src)
+	}
+	if S_MT != etag {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	res, err := client.Do(req)
+	if nil != err {
+		return nil, S_MT, S_MT, NewPathError(ErrNotFound, nil, err, "Cannot load spec: %s", src)
+	}
+	defer res.Body.Close()
+
+	if http.StatusNotModified == res.StatusCode && S_MT != etag {
+		return nil, S_MT, etag, nil
+	}
+
+	if http.StatusOK != res.StatusCode {
+		return nil, S_MT, S_MT, NewPathError(ErrNotFound, nil, nil,
+			"Cannot load spec: %s (status %d)", src, res.StatusCode)
+	}
+
+	content, err := _readSpec(res.Body, src, opts)
+	if nil != err {
+		return nil, S_MT, S_MT, err
+	}
+
+	return content, res.Header.Get("Content-Type"), res.Header.Get("ETag"), nil
+}
@@ -0,0 +1,158 @@
+package voxgigstruct_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/voxgig/struct"
+)
+
+func TestTransformer(t *testing.T) {
+
+	specsrc := `{"x":"` + "`a`" + `"}`
+	sum := sha256.Sum256([]byte(specsrc))
+	hash := hex.EncodeToString(sum[:])
+
+	t.Run("transformer-basic", func(t *testing.T) {
+		spec := map[string]any{"x": "`a`"}
+		tr := voxgigstruct.NewTransformer(spec, nil)
+		spec["x"] = "`b`"
+
+		result := tr.Transform(map[string]any{"a": 1, "b": 2})
+		if !reflect.DeepEqual(map[string]any{"x": 1}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+
+		tres := tr.TransformCollect(map[string]any{"a": "xxxx"}, &voxgigstruct.Limits{MaxBytes: 4}, nil)
+		if nil != tres.Out || 1 != len(tres.Errs) {
+			t.Errorf("Expected a limit error: %v %v", tres.Out, tres.Errs)
+		}
+	})
+
+	t.Run("transformer-load-file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "spec.json")
+		if err := os.WriteFile(file, []byte(specsrc), 0o644); nil != err {
+			t.Fatal(err)
+		}
+
+		tr, err := voxgigstruct.LoadSpec(file, &voxgigstruct.LoadOptions{SHA256: hash})
+		if nil != err || hash != tr.Hash || file != tr.Source {
+			t.Fatalf("Unexpected: %v %v", tr, err)
+		}
+		if result := tr.Transform(map[string]any{"a": 1}); !reflect.DeepEqual(map[string]any{"x": 1}, result) {
+			t.Errorf("Unexpected: %v", result)
+		}
+
+		_, err = voxgigstruct.LoadSpec("file://"+file, &voxgigstruct.LoadOptions{SHA256: "00"})
+		if !errors.Is(err, voxgigstruct.ErrSpec) {
+			t.Errorf("Expected ErrSpec, Got: %v", err)
+		}
+
+		verr := errors.New("bad signature")
+		_, err = voxgigstruct.LoadSpec(file, &voxgigstruct.LoadOptions{
+			Verify: func(src []byte) error { return verr },
+		})
+		if !errors.Is(err, voxgigstruct.ErrSpec) || !errors.Is(err, verr) {
+			t.Errorf("Expected verification error, Got: %v", err)
+		}
+
+		_, err = voxgigstruct.LoadSpec(filepath.Join(t.TempDir(), "none.json"), nil)
+		if !errors.Is(err, voxgigstruct.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, Got: %v", err)
+		}
+	})
+
+	t.Run("transformer-load-url", func(t *testing.T) {
+		fetches := 0
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches++
+			if `"v1"` == r.Header.Get("If-None-Match") {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(specsrc))
+		}))
+		defer server.Close()
+
+		cache := voxgigstruct.NewSpecCache(time.Hour)
+		opts := &voxgigstruct.LoadOptions{Client: server.Client(), Cache: cache, SHA256: hash}
+
+		tr0, err := voxgigstruct.LoadSpec(server.URL+"/spec", opts)
+		if nil != err {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		tr1, err := voxgigstruct.LoadSpec(server.URL+"/spec", opts)
+		if nil != err || !reflect.DeepEqual(tr0.Spec(), tr1.Spec()) || hash != tr1.Hash || 1 != fetches {
+			t.Errorf("Expected cached spec: %v %v", fetches, err)
+		}
+
+		// The options of each load apply to its transformer.
+		tr1, err = voxgigstruct.LoadSpec(server.URL+"/spec", &voxgigstruct.LoadOptions{
+			Client: server.Client(), Cache: cache,
+			Transform: &voxgigstruct.TransformOptions{Limits: &voxgigstruct.Limits{MaxBytes: 4}},
+		})
+		if nil != err || 1 != fetches {
+			t.Fatalf("Expected cached spec: %v %v", fetches, err)
+		}
+		if tres := tr1.TransformCollect(map[string]any{"a": "xxxx"}, nil, nil); 1 != len(tres.Errs) {
+			t.Errorf("Expected a limit error: %v", tres.Errs)
+		}
+		if tres := tr0.TransformCollect(map[string]any{"a": "xxxx"}, nil, nil); 0 != len(tres.Errs) {
+			t.Errorf("Unexpected: %v", tres.Errs)
+		}
+
+		// Expired entries are revalidated.
+		cache.TTL = time.Nanosecond
+		time.Sleep(time.Millisecond)
+		tr2, err := voxgigstruct.LoadSpec(server.URL+"/spec", opts)
+		if nil != err || !reflect.DeepEqual(tr0.Spec(), tr2.Spec()) || 2 != fetches {
+			t.Errorf("Expected revalidated transformer: %v %v", fetches, err)
+		}
+
+		// Cached and revalidated (not modified) specs are still checked.
+		verr := errors.New("bad signature")
+		_, err = voxgigstruct.LoadSpec(server.URL+"/spec", &voxgigstruct.LoadOptions{
+			Client: server.Client(), Cache: cache,
+			Verify: func(src []byte) error { return verr },
+		})
+		if !errors.Is(err, voxgigstruct.ErrSpec) || !errors.Is(err, verr) || 3 != fetches {
+			t.Errorf("Expected verification error for revalidated spec, Got: %v %v", fetches, err)
+		}
+
+		cache.TTL = time.Hour
+		_, err = voxgigstruct.LoadSpec(server.URL+"/spec", &voxgigstruct.LoadOptions{
+			Client: server.Client(), Cache: cache, SHA256: "00",
+		})
+		if !errors.Is(err, voxgigstruct.ErrSpec) || 3 != fetches {
+			t.Errorf("Expected integrity error for cached spec, Got: %v %v", fetches, err)
+		}
+
+		_, err = voxgigstruct.LoadSpec(server.URL+"/spec", &voxgigstruct.LoadOptions{
+			Client: server.Client(), MaxBytes: 4,
+		})
+		if !errors.Is(err, voxgigstruct.ErrLimit) {
+			t.Errorf("Expected ErrLimit for large spec, Got: %v", err)
+		}
+
+		_, err = voxgigstruct.LoadSpec("http://localhost/spec", nil)
+		if !errors.Is(err, voxgigstruct.ErrSpec) {
+			t.Errorf("Expected insecure URL error, Got: %v", err)
+		}
+
+		_, err = voxgigstruct.LoadSpec(server.URL+"/spec", &voxgigstruct.LoadOptions{})
+		if !errors.Is(err, voxgigstruct.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for untrusted certificate, Got: %v", err)
+		}
+	})
+}
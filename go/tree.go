@@ -0,0 +1,110 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strings"
+)
+
+// Default name of the self-reference of a `$TREE` template.
+const S_DSELF = "$SELF"
+
+// Apply a template recursively to a nested source, such as a category
+// tree. Format: ['`$TREE`', source-path, template, name?]. The source
+// path is resolved as for `$EACH`. If the source is a list, each
+// element is transformed. Inside the template, the self-reference
+// (named `$SELF`, unless a name is given) applies the template again
+// to a child of the current source node, in the list form
+// ['`$SELF`', child-path]:
+//
+//	['`$TREE`', 'root', {
+//	  'name': '`.title`',
+//	  'kids': ['`$SELF`', 'children']
+//	}]
+//
+// Names allow nested trees to refer to an outer template.
+var Transform_TREE Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$TREE", 2)
+	if !ok {
+		return nil
+	}
+
+	srcpath := args[0]
+	template := args[1]
+
+	name := S_DSELF
+	if sname, ok := GetProp(args, 2).(string); ok && S_MT != sname {
+		name = S_DS + strings.TrimPrefix(sname, S_DS)
+	}
+
+	srcstore := GetProp(store, state.Base, store)
+	src := GetPathState(srcpath, srcstore, current, nil)
+	if parts, ok := _pathParts(srcpath); ok {
+		_markUsed(store, parts)
+	}
+
+	t := &tree{template: template, name: name, store: store}
+	return _replaceTransform(state, t.build(src, state))
+}
+
+type tree struct {
+	template any
+	name     string
+	store    any
+}
+
+// Transform a source node, or each element of a source list, in the
+// context of the injection state of the transform or self-reference.
+func (t *tree) build(src any, state *Injection) any {
+	if IsList(src) {
+		list := _listify(src)
+		out := make([]any, len(list))
+		for i, item := range list {
+			out[i] = t.node(item, state, []string{StrKey(i)})
+		}
+		return out
+	}
+	return t.node(src, state, nil)
+}
+
+// Transform a source node, whose output is at the sub path of the
+// output of the state.
+func (t *tree) node(src any, state *Injection, sub []string) any {
+	if nil == src {
+		return nil
+	}
+
+	_reserveOutput(t.store, 1, t.template, state.Path)
+
+	// The self-reference is bound to this source node.
+	tstore := map[string]any{}
+	for k, v := range _storeMap(t.store) {
+		tstore[k] = v
+	}
+	tstore[t.name] = Injector(func(
+		sstate *Injection,
+		val any,
+		current any,
+		ref *string,
+		store any,
+	) any {
+		sargs, ok := _listTransformArgs(sstate, t.name, 1)
+		if !ok {
+			return nil
+		}
+		child := GetPath(sargs[0], src)
+		return _replaceTransform(sstate, t.build(child, sstate))
+	})
+
+	tval := Clone(t.template)
+	nested := _nestedState(tval, tstore, state)
+	nested.Prefix = append(nested.Prefix, sub...)
+
+	return InjectDescend(tval, tstore, state.Modify, map[string]any{S_DTOP: src}, nested)
+}
@@ -0,0 +1,77 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestTree(t *testing.T) {
+
+	data := map[string]any{
+		"root": map[string]any{
+			"title": "all",
+			"children": []any{
+				map[string]any{"title": "books", "children": []any{
+					map[string]any{"title": "fiction"},
+				}},
+				map[string]any{"title": "music"},
+			},
+		},
+	}
+
+	t.Run("tree-self", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"menu": []any{"`$TREE`", "root", map[string]any{
+				"name": "`.title`",
+				"kids": []any{"`$SELF`", "children"},
+			}},
+		})
+		expected := map[string]any{"menu": map[string]any{
+			"name": "all",
+			"kids": []any{
+				map[string]any{"name": "books", "kids": []any{
+					map[string]any{"name": "fiction"},
+				}},
+				map[string]any{"name": "music"},
+			},
+		}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("tree-named", func(t *testing.T) {
+		// A list source, and a named self-reference.
+		out := voxgigstruct.Transform(data, []any{"`$TREE`", "root.children", map[string]any{
+			"n":   "`.title`",
+			"sub": []any{"`$NODE`", "children"},
+		}, "NODE"})
+		expected := []any{
+			map[string]any{"n": "books", "sub": []any{map[string]any{"n": "fiction"}}},
+			map[string]any{"n": "music"},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("tree-convert-path", func(t *testing.T) {
+		paths := []string{}
+		voxgigstruct.TransformWith(data, map[string]any{
+			"menu": []any{"`$TREE`", "root", map[string]any{
+				"name": "`.title`",
+				"kids": []any{"`$SELF`", "children"},
+			}},
+		}, &voxgigstruct.TransformOptions{Convert: func(path []string, val any) any {
+			if "fiction" == val {
+				paths = append(paths, voxgigstruct.Pathify(path))
+			}
+			return val
+		}})
+		if !reflect.DeepEqual([]string{"menu.kids.0.kids.0.name"}, paths) {
+			t.Errorf("Expected: %v, Got: %v", "menu.kids.0.kids.0.name", paths)
+		}
+	})
+}
@@ -0,0 +1,332 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Get a value at a key path (see GetPath), as type T. Values of type
+// T are returned directly. Numbers are converted between numeric
+// types if no precision is lost. Nodes are mapped onto structs,
+// typed maps and typed slices using the encoding/json rules (so
+// struct field tags apply). Returns ErrNotFound if there is no value
+// at the path, and ErrType if the value cannot be converted.
+func GetPathAs[T any](path any, store any) (T, error) {
+	var out T
+
+	parts, ok := _pathParts(path)
+	if !ok {
+		return out, NewPathError(ErrSpec, nil, nil, "Invalid path: %v", path)
+	}
+
+	val := GetPath(path, store)
+	if nil == val {
+		return out, NewPathError(ErrNotFound, parts, nil, "No value at path")
+	}
+
+	if tval, ok := val.(T); ok {
+		return tval, nil
+	}
+
+	err := _convertInto(val, reflect.ValueOf(&out).Elem())
+	if nil != err {
+		return out, NewPathError(ErrType, parts, err, "Cannot convert %s to %s",
+			Typify(val), reflect.TypeOf(&out).Elem().String())
+	}
+
+	return out, nil
+}
+
+// Convert val into the settable target.
+func _convertInto(val any, target reflect.Value) error {
+	rval := reflect.ValueOf(val)
+	ttype := target.Type()
+
+	if _isNumberKind(rval.Kind()) && _isNumberKind(ttype.Kind()) {
+		conv := rval.Convert(ttype)
+		if !reflect.DeepEqual(conv.Convert(rval.Type()).Interface(), val) ||
+			(_isFloatKind(rval.Kind()) && math.IsNaN(rval.Float())) ||
+			_isSignWrap(rval, ttype.Kind()) {
+			return NewPathError(ErrType, nil, nil, "Number %v is not representable", val)
+		}
+		target.Set(conv)
+		return nil
+	}
+
+	if rval.Type().AssignableTo(ttype) {
+		target.Set(rval)
+		return nil
+	}
+
+	// Map nodes onto structs (and other types) using JSON.
+	if reflect.String == ttype.Kind() || reflect.Bool == ttype.Kind() ||
+		_isNumberKind(ttype.Kind()) {
+		return NewPathError(ErrType, nil, nil, "Expected %s", ttype.Kind())
+	}
+
+	b, err := json.Marshal(val)
+	if nil != err {
+		return err
+	}
+	return json.Unmarshal(b, target.Addr().Interface())
+}
+
+// The round trip check does not catch a change of sign, as -1 and
+// MaxUint64 convert into each other.
+func _isSignWrap(rval reflect.Value, kind reflect.Kind) bool {
+	switch {
+	case _isUintKind(kind):
+		return (_isIntKind(rval.Kind()) && rval.Int() < 0) ||
+			(_isFloatKind(rval.Kind()) && rval.Float() < 0)
+	case _isIntKind(kind):
+		return _isUintKind(rval.Kind()) && math.MaxInt64 < rval.Uint()
+	}
+	return false
+}
+
+func _isIntKind(kind reflect.Kind) bool {
+	return reflect.Int <= kind && kind <= reflect.Int64
+}
+
+func _isUintKind(kind reflect.Kind) bool {
+	return reflect.Uint <= kind && kind <= reflect.Uint64
+}
+
+func _isNumberKind(kind reflect.Kind) bool {
+	return (reflect.Int <= kind && kind <= reflect.Uint64) || _isFloatKind(kind)
+}
+
+func _isFloatKind(kind reflect.Kind) bool {
+	return reflect.Float32 == kind || reflect.Float64 == kind
+}
+
+// Deep copy a value into a target, which must be a non-nil pointer
+// (for example, to a struct or a typed map). Maps are copied onto
+// structs by field name, using the encoding/json names and tags
+// (names match without case, and `json:"-"` fields are skipped), and
+// onto typed maps and slices by key. Missing keys leave the target
+// field unchanged. Scalars are coerced where the meaning is clear:
+// numbers convert between numeric types if no precision is lost,
+// strings are parsed as numbers and booleans, numbers and booleans
+// format as strings, and strings are decoded by targets that
+// implement encoding.TextUnmarshaler (such as time.Time). Returns an
+// ErrType error with the path of the first value that cannot be
+// copied.
+func CloneInto(src any, dst any) error {
+	target := reflect.ValueOf(dst)
+	if reflect.Pointer != target.Kind() || target.IsNil() {
+		return NewPathError(ErrType, nil, nil, "Target must be a non-nil pointer, not %T", dst)
+	}
+	return _cloneInto(src, target.Elem(), []string{})
+}
+
+func _cloneInto(val any, target reflect.Value, path []string) error {
+	ttype := target.Type()
+
+	if nil == val {
+		target.Set(reflect.Zero(ttype))
+		return nil
+	}
+
+	if reflect.String == reflect.TypeOf(val).Kind() && reflect.String != ttype.Kind() {
+		if tu, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := tu.UnmarshalText([]byte(reflect.ValueOf(val).String())); nil != err {
+				return NewPathError(ErrType, path, err, "Cannot decode %s", ttype)
+			}
+			return nil
+		}
+	}
+
+	switch ttype.Kind() {
+	case reflect.Interface:
+		cval := reflect.ValueOf(Clone(val))
+		if !cval.Type().AssignableTo(ttype) {
+			return NewPathError(ErrType, path, nil, "Cannot assign %s to %s", Typify(val), ttype)
+		}
+		target.Set(cval)
+		return nil
+
+	case reflect.Pointer:
+		elem := reflect.New(ttype.Elem())
+		if !target.IsNil() {
+			elem.Elem().Set(target.Elem())
+		}
+		if err := _cloneInto(val, elem.Elem(), path); nil != err {
+			return err
+		}
+		target.Set(elem)
+		return nil
+
+	case reflect.Struct:
+		if !IsMap(val) {
+			break
+		}
+		return _cloneIntoStruct(val.(map[string]any), target, path)
+
+	case reflect.Map:
+		if !IsMap(val) {
+			break
+		}
+		if target.IsNil() {
+			target.Set(reflect.MakeMapWithSize(ttype, len(val.(map[string]any))))
+		}
+		for _, k := range KeysOf(val) {
+			kpath := _childPath(path, k)
+			key := reflect.New(ttype.Key()).Elem()
+			if err := _cloneInto(k, key, kpath); nil != err {
+				return err
+			}
+			elem := reflect.New(ttype.Elem()).Elem()
+			if err := _cloneInto(val.(map[string]any)[k], elem, kpath); nil != err {
+				return err
+			}
+			target.SetMapIndex(key, elem)
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if !IsList(val) {
+			break
+		}
+		list := _listify(val)
+		if reflect.Slice == ttype.Kind() {
+			target.Set(reflect.MakeSlice(ttype, len(list), len(list)))
+		} else if target.Len() < len(list) {
+			return NewPathError(ErrIndexRange, path, nil,
+				"List of %d is too long for %s", len(list), ttype)
+		}
+		for i, item := range list {
+			if err := _cloneInto(item, target.Index(i), _childPath(path, StrKey(i))); nil != err {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return _coerceInto(val, target, path)
+	}
+
+	return NewPathError(ErrType, path, nil, "Cannot copy %s into %s", Typify(val), ttype)
+}
+
+// Copy map properties onto the exported fields of a struct.
+func _cloneIntoStruct(val map[string]any, target reflect.Value, path []string) error {
+	ttype := target.Type()
+
+	for fI := 0; fI < ttype.NumField(); fI++ {
+		field := ttype.Field(fI)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if "-" == tag {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a name are flattened.
+		if field.Anonymous && S_MT == name {
+			ftype := field.Type
+			if reflect.Pointer == ftype.Kind() {
+				ftype = ftype.Elem()
+			}
+			if reflect.Struct == ftype.Kind() {
+				fval := target.Field(fI)
+				if reflect.Pointer == fval.Kind() {
+					if !fval.CanSet() {
+						continue
+					}
+					if fval.IsNil() {
+						fval.Set(reflect.New(ftype))
+					}
+					fval = fval.Elem()
+				}
+				if err := _cloneIntoStruct(val, fval, path); nil != err {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if S_MT == name {
+			name = field.Name
+		}
+
+		key, found := name, false
+		if _, found = val[key]; !found {
+			for k := range val {
+				if strings.EqualFold(k, name) && (!found || k < key) {
+					key, found = k, true
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+
+		if err := _cloneInto(val[key], target.Field(fI), _childPath(path, key)); nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Coerce a scalar into a scalar target.
+func _coerceInto(val any, target reflect.Value, path []string) error {
+	rval := reflect.ValueOf(val)
+	ttype := target.Type()
+	kind := ttype.Kind()
+
+	switch {
+	case rval.Type().AssignableTo(ttype):
+		target.Set(rval)
+		return nil
+
+	case _isNumberKind(rval.Kind()) && _isNumberKind(kind):
+		if err := _convertInto(val, target); nil != err {
+			return NewPathError(ErrType, path, err, "Cannot convert %s to %s", Typify(val), ttype)
+		}
+		return nil
+
+	case reflect.String == rval.Kind() && _isNumberKind(kind):
+		num, err := strconv.ParseFloat(strings.TrimSpace(rval.String()), 64)
+		if nil != err {
+			return NewPathError(ErrType, path, err, "Cannot parse %q as %s", rval.String(), ttype)
+		}
+		if err := _convertInto(num, target); nil != err {
+			return NewPathError(ErrType, path, err, "Cannot convert %s to %s", rval.String(), ttype)
+		}
+		return nil
+
+	case reflect.String == rval.Kind() && reflect.Bool == kind:
+		b, err := strconv.ParseBool(strings.TrimSpace(rval.String()))
+		if nil != err {
+			return NewPathError(ErrType, path, err, "Cannot parse %q as %s", rval.String(), ttype)
+		}
+		target.SetBool(b)
+		return nil
+
+	case reflect.String == kind && (_isNumberKind(rval.Kind()) || reflect.Bool == rval.Kind()):
+		target.SetString(fmt.Sprint(val))
+		return nil
+
+	case rval.Type().ConvertibleTo(ttype) && rval.Kind() == kind:
+		// Named types with the same underlying kind.
+		target.Set(rval.Convert(ttype))
+		return nil
+	}
+
+	return NewPathError(ErrType, path, nil, "Cannot copy %s into %s", Typify(val), ttype)
+}
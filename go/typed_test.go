@@ -0,0 +1,196 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/voxgig/struct"
+)
+
+type typedUser struct {
+	Name  string   `json:"name"`
+	Age   int      `json:"age"`
+	Tags  []string `json:"tags"`
+	Extra *typedUser
+}
+
+func TestTyped(t *testing.T) {
+
+	store := map[string]any{
+		"users": []any{
+			map[string]any{"name": "alice", "age": float64(30), "tags": []any{"a", "b"}},
+		},
+		"n":    float64(3),
+		"f":    1.5,
+		"name": "bob",
+	}
+
+	t.Run("typed-scalar", func(t *testing.T) {
+		name, err := voxgigstruct.GetPathAs[string]("users.0.name", store)
+		if nil != err || "alice" != name {
+			t.Errorf("Unexpected: %v %v", name, err)
+		}
+
+		n, err := voxgigstruct.GetPathAs[int]("n", store)
+		if nil != err || 3 != n {
+			t.Errorf("Unexpected: %v %v", n, err)
+		}
+
+		f, err := voxgigstruct.GetPathAs[float32]("f", store)
+		if nil != err || 1.5 != f {
+			t.Errorf("Unexpected: %v %v", f, err)
+		}
+
+		if _, err := voxgigstruct.GetPathAs[int]("f", store); !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected ErrType, Got: %v", err)
+		}
+
+		if _, err := voxgigstruct.GetPathAs[int]("name", store); !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected ErrType, Got: %v", err)
+		}
+
+		// Conversions that change sign are not representable.
+		signs := map[string]any{"neg": -1, "negf": float64(-1), "big": uint64(math.MaxUint64)}
+		if u, err := voxgigstruct.GetPathAs[uint]("neg", signs); !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected ErrType, Got: %v %v", u, err)
+		}
+		if u, err := voxgigstruct.GetPathAs[uint32]("negf", signs); !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected ErrType, Got: %v %v", u, err)
+		}
+		if i, err := voxgigstruct.GetPathAs[int64]("big", signs); !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected ErrType, Got: %v %v", i, err)
+		}
+		if u, err := voxgigstruct.GetPathAs[uint8]("n", store); nil != err || 3 != u {
+			t.Errorf("Unexpected: %v %v", u, err)
+		}
+
+		_, err = voxgigstruct.GetPathAs[string]("users.1.name", store)
+		var perr *voxgigstruct.PathError
+		if !errors.Is(err, voxgigstruct.ErrNotFound) || !errors.As(err, &perr) ||
+			!reflect.DeepEqual([]string{"users", "1", "name"}, perr.Path) {
+			t.Errorf("Expected ErrNotFound, Got: %v", err)
+		}
+	})
+
+	t.Run("typed-struct", func(t *testing.T) {
+		user, err := voxgigstruct.GetPathAs[typedUser]("users.0", store)
+		expected := typedUser{Name: "alice", Age: 30, Tags: []string{"a", "b"}}
+		if nil != err || !reflect.DeepEqual(expected, user) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, user, err)
+		}
+
+		users, err := voxgigstruct.GetPathAs[[]*typedUser]("users", store)
+		if nil != err || 1 != len(users) || "alice" != users[0].Name {
+			t.Errorf("Unexpected: %v %v", users, err)
+		}
+
+		tags, err := voxgigstruct.GetPathAs[[]string]("users.0.tags", store)
+		if nil != err || !reflect.DeepEqual([]string{"a", "b"}, tags) {
+			t.Errorf("Unexpected: %v %v", tags, err)
+		}
+
+		if _, err := voxgigstruct.GetPathAs[typedUser]("users.0.tags", store); !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Expected ErrType, Got: %v", err)
+		}
+
+		node, err := voxgigstruct.GetPathAs[map[string]any]("users.0", store)
+		if nil != err || "alice" != node["name"] {
+			t.Errorf("Unexpected: %v %v", node, err)
+		}
+	})
+
+	t.Run("typed-clone-into", func(t *testing.T) {
+		type base struct {
+			ID string
+		}
+		type order struct {
+			base
+			Items   []typedUser         `json:"items"`
+			Counts  map[string]int      `json:"counts"`
+			ByIndex map[int]string      `json:"byIndex"`
+			When    time.Time           `json:"when"`
+			Paid    bool                `json:"paid"`
+			Total   float32             `json:"total"`
+			Note    *string             `json:"note"`
+			Meta    any                 `json:"meta"`
+			Skip    string              `json:"-"`
+			Nested  map[string][]string `json:"nested"`
+		}
+
+		meta := map[string]any{"x": []any{1}}
+		src := map[string]any{
+			"id":      "o1",
+			"items":   []any{map[string]any{"NAME": "alice", "age": "30", "tags": []any{"a"}}},
+			"counts":  map[string]any{"a": float64(2)},
+			"byIndex": map[string]any{"1": "one"},
+			"when":    "2025-01-02T03:04:05Z",
+			"paid":    "true",
+			"total":   12,
+			"note":    "n",
+			"meta":    meta,
+			"Skip":    "x",
+			"nested":  map[string]any{"k": []any{"v"}},
+		}
+
+		var out order
+		out.Skip = "keep"
+		if err := voxgigstruct.CloneInto(src, &out); nil != err {
+			t.Fatalf("Unexpected: %v", err)
+		}
+
+		note := "n"
+		expected := order{
+			base:    base{ID: "o1"},
+			Items:   []typedUser{{Name: "alice", Age: 30, Tags: []string{"a"}}},
+			Counts:  map[string]int{"a": 2},
+			ByIndex: map[int]string{1: "one"},
+			When:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Paid:    true,
+			Total:   12,
+			Note:    &note,
+			Meta:    map[string]any{"x": []any{1}},
+			Skip:    "keep",
+			Nested:  map[string][]string{"k": {"v"}},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %+v, Got: %+v", expected, out)
+		}
+
+		// Deep copy.
+		out.Meta.(map[string]any)["x"].([]any)[0] = 2
+		if 1 != meta["x"].([]any)[0] {
+			t.Errorf("Expected source unchanged")
+		}
+
+		var user typedUser
+		err := voxgigstruct.CloneInto(map[string]any{"name": "a", "extra": map[string]any{"age": 1.5}}, &user)
+		var perr *voxgigstruct.PathError
+		if !errors.Is(err, voxgigstruct.ErrType) || !errors.As(err, &perr) ||
+			!reflect.DeepEqual([]string{"extra", "age"}, perr.Path) {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		if err := voxgigstruct.CloneInto(map[string]any{}, user); !errors.Is(err, voxgigstruct.ErrType) {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		var counter struct {
+			N uint
+			I int8
+		}
+		for _, src := range []map[string]any{{"N": -5}, {"N": "-5"}, {"I": uint64(1) << 63}} {
+			if err := voxgigstruct.CloneInto(src, &counter); !errors.Is(err, voxgigstruct.ErrType) {
+				t.Errorf("Expected ErrType for %v, Got: %v %v", src, counter, err)
+			}
+		}
+
+		typed := map[string][]int{}
+		if err := voxgigstruct.CloneInto(map[string]any{"a": []any{1, "2"}}, &typed); nil != err ||
+			!reflect.DeepEqual(map[string][]int{"a": {1, 2}}, typed) {
+			t.Errorf("Unexpected: %v %v", typed, err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// How TransformWith carries over source data that is not used by the
+// spec, so that migration transforms do not silently drop fields.
+// Source data is used if it is referenced by a path injection, or by
+// a transform (such as `$COPY` or `$EACH`). A node is carried over in
+// full if none of its data is used, and lists are used in full if any
+// element is used.
+type UnmappedMode string
+
+const (
+	UnmappedNone    UnmappedMode = ""        // Unused data is dropped (the default).
+	UnmappedArea    UnmappedMode = "area"    // Unused data is placed under `$UNMAPPED`.
+	UnmappedInPlace UnmappedMode = "inplace" // Unused data is placed at the same path, if free.
+)
+
+// Source data paths used by a transform, stored as `$USED`.
+type usedPaths struct {
+	paths [][]string
+}
+
+// Record a source data path as used, if the store is tracking usage.
+// Wildcard and relative parts end the path, so the whole subtree is
+// used.
+func _markUsed(store any, parts []string) {
+	used, ok := GetProp(store, S_DUSED).(*usedPaths)
+	if !ok {
+		return
+	}
+
+	path := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if S_ST == part || S_MT == part {
+			break
+		}
+		path = append(path, part)
+	}
+	used.paths = append(used.paths, path)
+}
+
+// Add the unused source data to the output.
+func _addUnmapped(out any, data any, used *usedPaths, mode UnmappedMode) any {
+	unmapped := _unmapped(data, used, []string{})
+	if nil == unmapped || !IsMap(out) {
+		return out
+	}
+
+	if UnmappedInPlace == mode && IsMap(unmapped) {
+		_fillMissing(out.(map[string]any), unmapped.(map[string]any))
+	} else if UnmappedArea == mode {
+		SetProp(out, S_DUNMAPPED, unmapped)
+	}
+	return out
+}
+
+// The parts of val (at path) that are not used, or nil.
+func _unmapped(val any, used *usedPaths, path []string) any {
+	partial := false
+	for _, upath := range used.paths {
+		if _isPathPrefix(upath, path) {
+			return nil
+		}
+		if _isPathPrefix(path, upath) {
+			partial = true
+		}
+	}
+
+	if !partial {
+		return Clone(val)
+	}
+	if !IsMap(val) {
+		return nil
+	}
+
+	out := map[string]any{}
+	for _, key := range KeysOf(val) {
+		if child := _unmapped(GetProp(val, key), used, _childPath(path, key)); nil != child {
+			out[key] = child
+		}
+	}
+	if 0 == len(out) {
+		return nil
+	}
+	return out
+}
+
+// Set the values of src that are not already defined in dst.
+func _fillMissing(dst map[string]any, src map[string]any) {
+	for key, val := range src {
+		existing, found := dst[key]
+		if !found || nil == existing {
+			dst[key] = val
+		} else if IsMap(existing) && IsMap(val) {
+			_fillMissing(existing.(map[string]any), val.(map[string]any))
+		}
+	}
+}
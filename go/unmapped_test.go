@@ -0,0 +1,74 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestUnmapped(t *testing.T) {
+
+	data := map[string]any{
+		"id":    1,
+		"name":  "a",
+		"addr":  map[string]any{"city": "x", "zip": "y"},
+		"tags":  []any{"t0", "t1"},
+		"items": []any{map[string]any{"v": 1}},
+		"extra": map[string]any{"p": true},
+	}
+
+	spec := map[string]any{
+		"id":    "`id`",
+		"label": "Name: `name`",
+		"city":  "`$TOP.addr.city`",
+		"tags":  "`tags.0`",
+		"items": []any{"`$EACH`", "items", map[string]any{"v": "`$COPY`"}},
+	}
+
+	t.Run("unmapped-none", func(t *testing.T) {
+		out := voxgigstruct.TransformWith(data, spec, nil)
+		if _, has := out.(map[string]any)["extra"]; has {
+			t.Errorf("Unexpected: %v", out)
+		}
+	})
+
+	t.Run("unmapped-area", func(t *testing.T) {
+		out := voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{
+			Unmapped: voxgigstruct.UnmappedArea,
+		})
+		expected := map[string]any{
+			"id":    1,
+			"label": "Name: a",
+			"city":  "x",
+			"tags":  "t0",
+			"items": []any{map[string]any{"v": 1}},
+			"$UNMAPPED": map[string]any{
+				"addr":  map[string]any{"zip": "y"},
+				"extra": map[string]any{"p": true},
+			},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+
+		// Copying everything leaves nothing unmapped.
+		out = voxgigstruct.TransformWith(data, map[string]any{"all": "`$TOP`"},
+			&voxgigstruct.TransformOptions{Unmapped: voxgigstruct.UnmappedArea})
+		if _, has := out.(map[string]any)["$UNMAPPED"]; has {
+			t.Errorf("Unexpected: %v", out)
+		}
+	})
+
+	t.Run("unmapped-inplace", func(t *testing.T) {
+		out := voxgigstruct.TransformWith(
+			map[string]any{"a": 1, "b": 2, "c": map[string]any{"d": 3, "e": 4}},
+			map[string]any{"a": "`$COPY`", "b": "B", "c": map[string]any{"d": "`c.d`"}},
+			&voxgigstruct.TransformOptions{Unmapped: voxgigstruct.UnmappedInPlace},
+		)
+		expected := map[string]any{"a": 1, "b": "B", "c": map[string]any{"d": 3, "e": 4}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const S_DVALID = "$VALID"
+
+// A custom validator (see RegisterValidator). The path is the path of
+// the value in the data, and errors are appended to errs (as strings,
+// to match the built in validators). The value is nil if the field is
+// missing, unless it is optional (see `$REQUIRED`).
+type Validator func(val any, path []string, errs *ListRef[any])
+
+var (
+	validators      atomic.Pointer[map[string]Validator]
+	validatorsMutex sync.Mutex
+)
+
+func init() {
+	validators.Store(&map[string]Validator{})
+}
+
+// Register a named validator, referenced in shapes as `$VALID:name`.
+// Registration is global, and should be done before use (for
+// example, in init). A nil validator removes the name.
+func RegisterValidator(name string, validator Validator) {
+	validatorsMutex.Lock()
+	defer validatorsMutex.Unlock()
+
+	all := map[string]Validator{}
+	for n, v := range *validators.Load() {
+		all[n] = v
+	}
+	if nil == validator {
+		delete(all, name)
+	} else {
+		all[name] = validator
+	}
+	validators.Store(&all)
+}
+
+// Apply a registered validator: `$VALID:name`.
+var validate_VALID Injector = func(
+	state *Injection,
+	_val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	if S_MVAL != state.Mode {
+		return nil
+	}
+
+	name := S_MT
+	if nil != ref && strings.HasPrefix(*ref, S_DVALID+":") {
+		name = (*ref)[len(S_DVALID)+1:]
+	}
+
+	validator, ok := (*validators.Load())[name]
+	if !ok {
+		_invalidSpec(state, store, "Unknown validator at field "+Pathify(state.Path, 1)+": "+name)
+		return nil
+	}
+
+	out := GetProp(current, state.Key)
+	if nil == out && _validateOptional(state, store) {
+		return nil
+	}
+
+	path := []string{}
+	if 1 < len(state.Path) {
+		path = append(path, state.Path[1:]...)
+	}
+	// The validator reports to its own collector, so that the errors
+	// can also be recorded as structured errors.
+	errs := ListRefCreate[any]()
+	validator(out, path, errs)
+	for _, e := range errs.List {
+		msg, ok := e.(string)
+		if !ok {
+			msg = Stringify(e)
+			if err, isErr := e.(error); isErr {
+				msg = err.Error()
+			}
+		}
+		_invalid(state, store, state.Path, VE_VALID, name, out, msg)
+	}
+
+	return out
+}
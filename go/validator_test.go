@@ -0,0 +1,71 @@
+package voxgigstruct_test
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestValidator(t *testing.T) {
+
+	voxgigstruct.RegisterValidator("ipaddr", func(val any, path []string, errs *voxgigstruct.ListRef[any]) {
+		if s, ok := val.(string); !ok || nil == net.ParseIP(s) {
+			errs.Append("Expected field " + voxgigstruct.Pathify(path) + " to be an IP address, but found " +
+				voxgigstruct.Stringify(val) + ".")
+		}
+	})
+	defer voxgigstruct.RegisterValidator("ipaddr", nil)
+
+	spec := func() any {
+		return map[string]any{
+			"hosts": []any{"`$CHILD`", map[string]any{"ip": "`$VALID:ipaddr`"}},
+		}
+	}
+
+	t.Run("validator-valid", func(t *testing.T) {
+		data := map[string]any{"hosts": []any{map[string]any{"ip": "10.0.0.1"}}}
+		out, err := voxgigstruct.Validate(data, spec())
+		if nil != err || !reflect.DeepEqual(data, out) {
+			t.Errorf("Expected: %v, Got: %v %v", data, out, err)
+		}
+	})
+
+	t.Run("validator-invalid", func(t *testing.T) {
+		errs := voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{"hosts": []any{
+			map[string]any{"ip": "10.0.0.1"},
+			map[string]any{"ip": "nope"},
+		}}, spec(), nil, errs)
+
+		expected := []any{"Expected field hosts.1.ip to be an IP address, but found nope."}
+		if !reflect.DeepEqual(expected, errs.List) {
+			t.Errorf("Expected: %v, Got: %v", expected, errs.List)
+		}
+
+		// Missing values are passed to the validator, unless optional.
+		shape := map[string]any{"ip": "`$VALID:ipaddr`"}
+		errs = voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{}, shape, nil, errs)
+		expected = []any{"Expected field ip to be an IP address, but found ."}
+		if !reflect.DeepEqual(expected, errs.List) {
+			t.Errorf("Expected: %v, Got: %v", expected, errs.List)
+		}
+
+		shape = map[string]any{"ip": "`$VALID:ipaddr`", "`$REQUIRED`": []any{}}
+		errs = voxgigstruct.ListRefCreate[any]()
+		voxgigstruct.ValidateCollect(map[string]any{}, shape, nil, errs)
+		if 0 != len(errs.List) {
+			t.Errorf("Expected: %v, Got: %v", 0, errs.List)
+		}
+	})
+
+	t.Run("validator-unknown", func(t *testing.T) {
+		_, err := voxgigstruct.Validate(map[string]any{"a": 1}, map[string]any{"a": "`$VALID:nope`"})
+		expected := "Invalid data: Unknown validator at field a: nope"
+		if nil == err || expected != err.Error() {
+			t.Errorf("Expected: %v, Got: %v", expected, err)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"strings"
+)
+
+// Store key of the collector of structured validation errors.
+const S_DVERRS = "$VERRS"
+
+// Store key of the closed validation mode (see ValidateOptions).
+const S_DCLOSED = "$CLOSED"
+
+// Codes of validation errors.
+const (
+	VE_TYPE     = "type"     // Value has the wrong type.
+	VE_MISSING  = "missing"  // Value of a type is missing.
+	VE_EMPTY    = "empty"    // String is empty.
+	VE_ONE      = "one"      // Value matches none of the `$ONE` shapes (and none is close).
+	VE_EXACT    = "exact"    // Value is not equal to the `$EXACT` values.
+	VE_KEYS     = "keys"     // Object has keys that are not in a closed shape.
+	VE_REQUIRED = "required" // Required field is missing (see `$REQUIRED`).
+	VE_PATTERN  = "pattern"  // String does not match the `$REGEX` pattern.
+	VE_VALID    = "valid"    // A custom validator failed (see RegisterValidator).
+	VE_UNKNOWN  = "unknown"  // Field is not in the shape (see ValidateOptions.Closed).
+	VE_SPEC     = "spec"     // The shape itself is invalid.
+)
+
+// A validation problem, at a path in the data (the root is an empty
+// path). Expected and Actual depend on the code: for VE_TYPE, they
+// are the expected type name and the value.
+type ValidationError struct {
+	Path     []string
+	Code     string
+	Expected any
+	Actual   any
+	Msg      string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+// Dotted path of the problem (see Pathify), or "" for the root.
+func (e *ValidationError) Field() string {
+	if 0 == len(e.Path) {
+		return S_MT
+	}
+	return Pathify(e.Path)
+}
+
+// All the problems found by a validation.
+type ValidationErrors []*ValidationError
+
+func (es ValidationErrors) Error() string {
+	return "Invalid data: " + strings.Join(es.Strings(), " | ")
+}
+
+// The messages of the problems, in order.
+func (es ValidationErrors) Strings() []string {
+	out := make([]string, len(es))
+	for eI, e := range es {
+		out[eI] = e.Msg
+	}
+	return out
+}
+
+// Problems grouped by dotted path (see Field).
+func (es ValidationErrors) ByField() map[string][]*ValidationError {
+	out := map[string][]*ValidationError{}
+	for _, e := range es {
+		out[e.Field()] = append(out[e.Field()], e)
+	}
+	return out
+}
+
+// Validate data against a shape, as for Validate, collecting all the
+// problems as structured errors. The error list is nil if the data
+// is valid.
+func ValidateAll(data any, spec any) (any, ValidationErrors) {
+	return ValidateWith(data, spec, nil)
+}
+
+// Options for ValidateWith.
+type ValidateOptions struct {
+	// Extra validators and store data, as for ValidateCollect.
+	Extra map[string]any
+
+	// Reject fields of the data that are not in the shape, even for
+	// empty object shapes ({}), which are otherwise open. Each unknown
+	// field is reported separately, with its path (VE_UNKNOWN).
+	// Object shapes marked with `$OPEN`, and type validators such as
+	// `$OBJECT`, still accept any fields.
+	Closed bool
+}
+
+// Validate data against a shape, with options, collecting all the
+// problems as structured errors, as for ValidateAll.
+func ValidateWith(data any, spec any, opts *ValidateOptions) (any, ValidationErrors) {
+	if nil == opts {
+		opts = &ValidateOptions{}
+	}
+
+	verrs := ListRefCreate[*ValidationError]()
+	extra := map[string]any{}
+	for k, v := range opts.Extra {
+		extra[k] = v
+	}
+	extra[S_DVERRS] = verrs
+	if opts.Closed {
+		extra[S_DCLOSED] = true
+	}
+
+	out, _ := ValidateCollect(data, spec, extra, ListRefCreate[any]())
+	if 0 == len(verrs.List) {
+		return out, nil
+	}
+	return out, ValidationErrors(verrs.List)
+}
+
+// Record a validation problem: the message is added to the error
+// collector, and the structured error to the collector of
+// ValidateAll, if any.
+func _invalid(state *Injection, store any, path []string, code string, expected any, actual any, msg string) {
+	state.Errs.Append(msg)
+
+	if verrs, ok := GetProp(store, S_DVERRS).(*ListRef[*ValidationError]); ok {
+		vpath := []string{}
+		if 1 < len(path) {
+			vpath = append(vpath, path[1:]...)
+		}
+		verrs.Append(&ValidationError{
+			Path:     vpath,
+			Code:     code,
+			Expected: expected,
+			Actual:   actual,
+			Msg:      msg,
+		})
+	}
+}
+
+// Record a value of the wrong type (or a missing value).
+func _invalidType(state *Injection, store any, path []string, needtype string, vt string, v any) {
+	code := VE_TYPE
+	if nil == v {
+		code = VE_MISSING
+	}
+	_invalid(state, store, path, code, needtype, v, _invalidTypeMsg(path, needtype, vt, v))
+}
+
+// Record an invalid shape.
+func _invalidSpec(state *Injection, store any, msg string) {
+	_invalid(state, store, state.Path, VE_SPEC, nil, nil, msg)
+}
@@ -0,0 +1,144 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestValidateAll(t *testing.T) {
+
+	spec := func() any {
+		return map[string]any{
+			"name":  "`$STRING`",
+			"age":   "`$NUMBER`",
+			"level": []any{"`$ONE`", "debug", "info"},
+			"tags":  []any{"`$CHILD`", "`$STRING`"},
+			"opts":  map[string]any{"a": 1},
+			"code":  "`$REGEX:^[a-z]+$`",
+		}
+	}
+
+	t.Run("validate-all-errors", func(t *testing.T) {
+		out, errs := voxgigstruct.ValidateAll(map[string]any{
+			"age":   "old",
+			"level": "trace",
+			"tags":  []any{"a", 2},
+			"opts":  map[string]any{"a": 2, "b": 3},
+			"code":  "ABC",
+		}, spec())
+
+		if nil == out {
+			t.Errorf("Expected: %v, Got: %v", "output", out)
+		}
+
+		type summary struct {
+			field    string
+			code     string
+			expected any
+			actual   any
+		}
+		got := []summary{}
+		for _, e := range errs {
+			got = append(got, summary{e.Field(), e.Code, e.Expected, e.Actual})
+		}
+		expected := []summary{
+			{"age", voxgigstruct.VE_TYPE, "number", "old"},
+			{"code", voxgigstruct.VE_PATTERN, "^[a-z]+$", "ABC"},
+			{"level", voxgigstruct.VE_ONE, []any{"debug", "info"}, "trace"},
+			{"name", voxgigstruct.VE_MISSING, "string", nil},
+			{"opts", voxgigstruct.VE_KEYS, []string{"a"}, []string{"b"}},
+			{"tags.1", voxgigstruct.VE_TYPE, "string", 2},
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected: %v, Got: %v", expected, got)
+		}
+
+		// The messages are the same as for Validate.
+		_, err := voxgigstruct.Validate(map[string]any{"age": "old"}, map[string]any{"age": "`$NUMBER`"})
+		_, verrs := voxgigstruct.ValidateAll(map[string]any{"age": "old"}, map[string]any{"age": "`$NUMBER`"})
+		if err.Error() != verrs.Error() {
+			t.Errorf("Expected: %v, Got: %v", err.Error(), verrs.Error())
+		}
+		if !reflect.DeepEqual([]string{"Expected field age to be number, but found string: old."}, verrs.Strings()) {
+			t.Errorf("Expected: %v, Got: %v", "age message", verrs.Strings())
+		}
+		if 1 != len(verrs.ByField()["age"]) {
+			t.Errorf("Expected: %v, Got: %v", 1, verrs.ByField())
+		}
+	})
+
+	t.Run("validate-all-valid", func(t *testing.T) {
+		out, errs := voxgigstruct.ValidateAll(map[string]any{
+			"name": "n", "age": 1, "tags": []any{}, "code": "x",
+		}, spec())
+		if nil != errs || "n" != voxgigstruct.GetProp(out, "name") {
+			t.Errorf("Expected: %v, Got: %v %v", nil, errs, out)
+		}
+	})
+
+	t.Run("validate-all-required", func(t *testing.T) {
+		_, errs := voxgigstruct.ValidateAll(map[string]any{"a": map[string]any{}}, map[string]any{
+			"a": map[string]any{"`$REQUIRED`": []any{"id"}, "id": "`$STRING`"},
+		})
+		if 1 != len(errs) || voxgigstruct.VE_REQUIRED != errs[0].Code ||
+			!reflect.DeepEqual([]string{"a", "id"}, errs[0].Path) {
+			t.Errorf("Expected: %v, Got: %v", "a.id required", errs)
+		}
+	})
+
+	t.Run("validate-closed", func(t *testing.T) {
+		spec := func() any {
+			return map[string]any{
+				"name": "`$STRING`",
+				"opts": map[string]any{},
+				"meta": map[string]any{"`$OPEN`": true, "v": 1},
+				"any":  "`$OBJECT`",
+				"kids": map[string]any{"`$CHILD`": map[string]any{"id": "`$NUMBER`"}},
+			}
+		}
+		data := func() any {
+			return map[string]any{
+				"name": "a",
+				"nmae": "typo",
+				"opts": map[string]any{"debug": true},
+				"meta": map[string]any{"x": 1},
+				"any":  map[string]any{"y": 2},
+				"kids": map[string]any{"k0": map[string]any{"id": 1, "idd": 2}},
+			}
+		}
+
+		_, verrs := voxgigstruct.ValidateWith(data(), spec(), &voxgigstruct.ValidateOptions{Closed: true})
+		fields := []string{}
+		for _, e := range verrs {
+			if voxgigstruct.VE_UNKNOWN != e.Code {
+				t.Errorf("Unexpected error: %v", e)
+			}
+			fields = append(fields, e.Field())
+		}
+		sort.Strings(fields)
+		expected := []string{"kids.k0.idd", "nmae", "opts.debug"}
+		if !reflect.DeepEqual(expected, fields) {
+			t.Errorf("Expected: %v, Got: %v (%v)", expected, fields, verrs)
+		}
+		if !strings.Contains(verrs.Error(), "Unknown field opts.debug.") {
+			t.Errorf("Unexpected message: %v", verrs)
+		}
+
+		// Not closed: {} is open, and extra keys are reported per object.
+		_, verrs = voxgigstruct.ValidateWith(data(), spec(), nil)
+		if 2 != len(verrs) || voxgigstruct.VE_KEYS != verrs[0].Code {
+			t.Errorf("Unexpected errors: %v", verrs)
+		}
+
+		// Valid data.
+		_, verrs = voxgigstruct.ValidateWith(map[string]any{"name": "a", "any": map[string]any{"z": 1}}, spec(),
+			&voxgigstruct.ValidateOptions{Closed: true})
+		if nil != verrs {
+			t.Errorf("Unexpected errors: %v", verrs)
+		}
+	})
+}
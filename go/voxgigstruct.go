@@ -13,6 +13,8 @@
  *
  * Main utilities
  * - getpath: get the value at a key path deep inside an object.
+ * - setpath: set the value at a key path deep inside an object.
+ * - delpath: delete the value at a key path deep inside an object.
  * - merge: merge multiple nodes, overriding values in earlier nodes.
  * - walk: walk a node tree, applying a function at each node and leaf.
  * - inject: inject values from a data store into a new data structure.
@@ -23,7 +25,9 @@
  * - isnode, islist, ismap, iskey, isfunc: identify value kinds.
  * - isempty: undefined values, or empty nodes.
  * - keysof: sorted list of node keys (ascending).
+ * - keys, numkeys: typed node keys (ints for lists), and key count.
  * - haskey: true if key value is defined.
+ * - haspath: true if key path is defined.
  * - clone: create a copy of a JSON-like data structure.
  * - items: list entries of a map or list as [key, value] pairs.
  * - getprop: safely get a property value by key.
@@ -78,6 +82,17 @@ const (
 	S_DMETA = "`$META`"
 	S_DTOP  = "$TOP"
 	S_DERRS = "$ERRS"
+	S_DWARNS = "$WARNS"
+	S_DENV  = "$ENV"
+	S_DLOG  = "$LOG"
+	S_DCALL = "$CALL"
+	S_DUSED = "$USED"
+	S_DSIZE = "$SIZE"
+	S_DCYCLE = "$CYCLE"
+
+	S_DUNMAPPED = "$UNMAPPED"
+
+	S_DASSERT = "$ASSERT"
 
 	// General strings.
 	S_array    = "array"
@@ -95,6 +110,8 @@ const (
 	S_DS       = "$"
 	S_DT       = "."
 	S_CN       = ":"
+	S_ST       = "*"
+	S_BS       = "\\"
 	S_KEY      = "KEY"
 )
 
@@ -140,9 +157,13 @@ type Injection struct {
 	Nodes   []any          // Stack of ancestor nodes.
 	Handler Injector       // Custom handler for injections.
 	Errs    *ListRef[any]  // Error collector.
+	Warns   *ListRef[any]  // Warning collector (see Warn).
 	Meta    map[string]any // Custom meta data.
 	Base    string         // Base key for data in store, if any.
+	NoBase  bool           // Do not fall back to Base data for top level paths.
 	Modify  Modify         // Modify injection output.
+	Log     Logger         // Structured logger, if any.
+	Prefix  []string       // Output path of the root, for nested injections.
 }
 
 // Apply a custom modification to injections.
@@ -183,10 +204,17 @@ func IsMap(val any) bool {
 }
 
 // Value is a defined list (array) with integer keys (indexes).
+// Leaf types (such as []byte) are not lists (see RegisterLeafType).
 func IsList(val any) bool {
 	if val == nil {
 		return false
 	}
+	if _, ok := val.([]any); ok {
+		return true
+	}
+	if IsLeaf(val) {
+		return false
+	}
 	rv := reflect.ValueOf(val)
 	kind := rv.Kind()
 	return kind == reflect.Slice || kind == reflect.Array
@@ -269,8 +297,10 @@ func Typify(value any) string {
 
 // Safely get a property of a node. Nil arguments return nil.
 // If the key is not found, return the alternative value, if any.
+// Negative list indexes count back from the end of the list.
+// NOTE: a stored nil value also returns the alternative value; use
+// GetPropOk to distinguish stored nil values from missing keys.
 func GetProp(val any, key any, alts ...any) any {
-	var out any
 	var alt any
 
 	if len(alts) > 0 {
@@ -281,6 +311,25 @@ func GetProp(val any, key any, alts ...any) any {
 		return alt
 	}
 
+	out, _ := _getProp(val, key)
+
+	if nil == out {
+		return alt
+	}
+
+	return out
+}
+
+// Get a property of a node, also reporting if the key is present,
+// so that stored nil values can be distinguished from missing keys.
+func _getProp(val any, key any) (any, bool) {
+	var out any
+	found := false
+
+	if nil == val || nil == key {
+		return nil, false
+	}
+
 	if IsMap(val) {
 		ks, ok := key.(string)
 		if !ok {
@@ -288,38 +337,26 @@ func GetProp(val any, key any, alts ...any) any {
 		}
 
 		v := val.(map[string]any)
-		res, has := v[ks]
-		if has {
-			out = res
-		}
+		out, found = v[ks]
 
 	} else if IsList(val) {
-		ki, ok := key.(int)
-		if !ok {
-			switch kf := key.(type) {
-			case float64:
-				ki = int(kf)
-
-			case string:
-				ki = -1
-				ski, err := strconv.Atoi(key.(string))
-				if nil == err {
-					ki = ski
-				}
-			}
-		}
-
 		v, ok := val.([]any)
 
 		if !ok {
 			rv := reflect.ValueOf(val)
-			if rv.Kind() == reflect.Slice && 0 <= ki && ki < rv.Len() {
-				out = rv.Index(ki).Interface()
+			if rv.Kind() == reflect.Slice {
+				ki, valid := _listIndex(key, rv.Len())
+				if valid {
+					out = rv.Index(ki).Interface()
+					found = true
+				}
 			}
 
 		} else {
-			if 0 <= ki && ki < len(v) {
+			ki, valid := _listIndex(key, len(v))
+			if valid {
 				out = v[ki]
+				found = true
 			}
 		}
 
@@ -338,15 +375,12 @@ func GetProp(val any, key any, alts ...any) any {
 			field := valRef.FieldByName(ks)
 			if field.IsValid() {
 				out = field.Interface()
+				found = true
 			}
 		}
 	}
 
-	if nil == out {
-		return alt
-	}
-
-	return out
+	return out, found
 }
 
 // Sorted keys of a map, or indexes of a list.
@@ -375,10 +409,47 @@ func KeysOf(val any) []string {
 	return make([]string, 0)
 }
 
+// Keys of a node, as typed keys: ints for lists (in order), and
+// strings for maps (sorted ascending). Returns an empty list if val
+// is not a node.
+func Keys(val any) []PropKey {
+	if IsMap(val) {
+		m := val.(map[string]any)
+		keys := make([]PropKey, 0, len(m))
+		for _, k := range KeysOf(m) {
+			keys = append(keys, k)
+		}
+		return keys
+
+	} else if IsList(val) {
+		keys := make([]PropKey, NumKeys(val))
+		for i := range keys {
+			keys[i] = i
+		}
+		return keys
+	}
+
+	return make([]PropKey, 0)
+}
+
+// Number of keys in a node (zero if val is not a node).
+func NumKeys(val any) int {
+	if m, ok := val.(map[string]any); ok {
+		return len(m)
+	} else if l, ok := val.([]any); ok {
+		return len(l)
+	} else if IsList(val) {
+		return reflect.ValueOf(val).Len()
+	}
+	return 0
+}
+
 
-// Value of property with name key in node val is defined.
+// Value of property with name key in node val is defined. A key
+// that is present with a nil value is considered defined.
 func HasKey(val any, key any) bool {
-	return nil != GetProp(val, key)
+	_, found := _getProp(val, key)
+	return found
 }
 
 
@@ -474,9 +545,13 @@ func Stringify(val any, maxlen ...int) string {
 		return S_MT
 	}
 
-	b, err := json.Marshal(val)
+	b, err := json.Marshal(_formatLeaves(val))
 	if err != nil {
-		return ""
+		// Nodes that contain themselves are shown as `$CYCLE`.
+		cval := CloneFlags(val, map[string]bool{"cycle": true})
+		if b, err = json.Marshal(_formatLeaves(cval)); nil != err {
+			return ""
+		}
 	}
 	jsonStr := string(b)
 
@@ -498,7 +573,16 @@ func Stringify(val any, maxlen ...int) string {
 
 // Build a human friendly path string.
 func Pathify(val any, from ...int) string {
+	return PathifyFlags(val, nil, from...)
+}
+
+// Build a path string, with optional flags:
+// - escape: escape dots and backslashes in keys (rather than removing
+//   dots), so that the path string can be parsed by getpath to give
+//   the same keys.
+func PathifyFlags(val any, flags map[string]bool, from ...int) string {
 	var pathstr *string
+	escape := nil != flags && flags["escape"]
 
 	var path []any = nil
 
@@ -557,7 +641,12 @@ func Pathify(val any, from ...int) string {
 			for _, p := range filtered {
 				switch x := p.(type) {
 				case string:
-					replaced := strings.ReplaceAll(x, S_DT, S_MT)
+					var replaced string
+					if escape {
+						replaced = EscPathKey(x)
+					} else {
+						replaced = strings.ReplaceAll(x, S_DT, S_MT)
+					}
 					mapped = append(mapped, replaced)
 				default:
 					numVal, err := _toFloat64(x)
@@ -595,11 +684,28 @@ func Clone(val any) any {
 	return CloneFlags(val, nil)
 }
 
+// Clone with optional flags:
+// - func: copy function references (the default), rather than removing them.
+// - cycle: replace nodes that contain themselves with a `$CYCLE` marker.
+// - reflect: deep copy other Go values, such as structs, pointers, and
+//   typed maps and slices (the default). If false, they are shared.
+//   Providers, loggers and store internals (such as ListRef
+//   collectors) are always shared.
+// - tag: replace leaves that have a type tag with a tagged value (see
+//   ToJSON).
+// Leaf types (see RegisterLeafType) are always copied as they define.
 func CloneFlags(val any, flags map[string]bool) any {
 	if val == nil {
 		return nil
 	}
 
+	// Scalars are immutable, and are not copied.
+	switch val.(type) {
+	case string, bool, float64, float32, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		return val
+	}
+
 	if nil == flags {
 		flags = map[string]bool{}
 	}
@@ -608,6 +714,10 @@ func CloneFlags(val any, flags map[string]bool) any {
 		flags["func"] = true
 	}
 
+	if _, ok := flags["reflect"]; !ok {
+		flags["reflect"] = true
+	}
+
 	typ := reflect.TypeOf(val)
 	if typ.Kind() == reflect.Func {
 		if flags["func"] {
@@ -616,6 +726,11 @@ func CloneFlags(val any, flags map[string]bool) any {
 		return nil
 	}
 
+	// Replace nodes that contain themselves with a `$CYCLE` marker.
+	if flags["cycle"] {
+		return _cloneCycle(val, flags, map[uintptr]bool{})
+	}
+
 	switch v := val.(type) {
 	case map[string]any:
 		newMap := make(map[string]any, len(v))
@@ -630,7 +745,7 @@ func CloneFlags(val any, flags map[string]bool) any {
 		}
 		return newSlice
 	default:
-		return v
+		return _cloneOther(v, flags)
 	}
 }
 
@@ -752,25 +867,10 @@ func WalkDescend(
 	parent any,
 	path []string,
 ) any {
-
-	if IsNode(val) {
-		for _, kv := range Items(val) {
-			ckey := kv[0]
-			child := kv[1]
-			ckeyStr := StrKey(ckey)
-			newChild := WalkDescend(child, apply, &ckeyStr, val, append(path, ckeyStr))
-			val = SetProp(val, ckey, newChild)
-		}
-
-		if nil != parent && nil != key {
-			SetProp(parent, *key, val)
-		}
-	}
-
 	// Nodes are applied *after* their children.
 	// For the root node, key and parent will be undefined.
-	val = apply(key, val, parent, path)
-
+	// The walk is iterative, so deep trees do not exhaust the stack.
+	val, _ = _walk(val, _noErr(apply), key, parent, path, WalkOptions{}, nil)
 	return val
 }
 
@@ -878,8 +978,13 @@ func Merge(val any) any {
 // as a dotted string, or a string array.  If the path starts with a
 // dot (or the first element is "), the path is considered local, and
 // resolved against the `current` argument, if defined.  Integer path
-// parts are used as array indexes.  The state argument allows for
-// custom handling when called from `inject` or `transform`.
+// parts are used as array indexes.  A `*` path part matches every key
+// or index at that level, and the result is then a list of all
+// matches, for example `users.*.email`.  Keys containing dots can be
+// escaped with a backslash, for example `a\.b` (see EscPathKey and
+// PathifyFlags). A path compiled with CompilePath can also be used.
+// The state argument allows for custom handling when called from
+// `inject` or `transform`.
 func GetPath(path any, store any) any {
 	return GetPathState(path, store, nil, nil)
 }
@@ -890,28 +995,13 @@ func GetPathState(
 	current any,
 	state *Injection,
 ) any {
-	var parts []string
-
 	val := store
 	root := store
 
 	// Operate on a string array.
-	switch pp := path.(type) {
-	case []string:
-		parts = pp
-
-	case string:
-		if pp == "" {
-			parts = []string{S_MT}
-		} else {
-			parts = strings.Split(pp, S_DT)
-		}
-	default:
-		if IsList(path) {
-			parts = _resolveStrings(pp.([]any))
-		} else {
-			return nil
-		}
+	parts, ok := _pathParts(path)
+	if !ok {
+		return nil
 	}
 
 	var base *string = nil
@@ -923,6 +1013,7 @@ func GetPathState(
 	if nil == path || nil == store || (1 == len(parts) && S_MT == parts[0]) {
 		// The actual store data may be in a store sub property, defined by state.base.
 		val = GetProp(store, base, store)
+		_markUsed(store, []string{})
 
 	} else if 0 < len(parts) {
 
@@ -939,31 +1030,281 @@ func GetPathState(
 			part = &parts[pI]
 		}
 
-		first := GetProp(root, *part)
+		// Wildcards are only parsed from path strings, so that a `*`
+		// key in a list of keys is literal.
+		wild := _pathWildcards(path)
 
-		// At top level, check state.base, if provided
-		val = first
-		if nil == first && 0 == pI {
-			val = GetProp(GetProp(root, base), *part)
-		}
+		if _isWild(wild, pI) {
+			// At top level, match against the data in state.base, if provided.
+			if 0 == pI && nil != base {
+				root = GetProp(root, *base, root)
+				_markUsed(store, parts)
+			}
+			val = _getPathAll([]any{root}, parts, wild, pI)
 
-		// Move along the path, trying to descend into the store.
-		pI++
-		for nil != val && pI < len(parts) {
-			val = GetProp(val, parts[pI])
+		} else {
+			first := GetProp(root, *part)
+
+			// At top level, check state.base, if provided
+			val = first
+			if nil == first && 0 == pI && (nil == state || !state.NoBase) {
+				val = GetProp(GetProp(root, base), *part)
+				_markUsed(store, parts)
+			} else if 0 == pI && S_DTOP == *part {
+				_markUsed(store, parts[1:])
+			}
+
+			// Move along the path, trying to descend into the store.
 			pI++
+			for nil != val && pI < len(parts) {
+				// The rest of the path is the provider key.
+				if provider, ok := val.(Provider); ok {
+					val = _providerFetch(provider, parts[pI-1], strings.Join(parts[pI:], S_DT), store, state)
+					break
+				}
+				if _isWild(wild, pI) {
+					val = _getPathAll([]any{val}, parts, wild, pI)
+					break
+				}
+				val = GetProp(val, parts[pI])
+				pI++
+			}
 		}
 	}
 
   
 	if nil != state && state.Handler != nil {
-		ref := Pathify(path)
+		var ref string
+		if cp, ok := path.(*Path); ok {
+			ref = Pathify(cp.src)
+		} else {
+			ref = Pathify(path)
+		}
 		val = state.Handler(state, val, current, &ref, store)
 	}
 
 	return val
 }
 
+// Resolve the remaining path parts (from pI) against each of vals. A
+// wildcard part (see _pathWildcards) matches every child of a node.
+// All defined matches are returned in a list, in key order.
+func _getPathAll(vals []any, parts []string, wild []bool, pI int) []any {
+	for ; pI < len(parts); pI++ {
+		next := []any{}
+		for _, val := range vals {
+			if _isWild(wild, pI) {
+				if IsMap(val) {
+					m := val.(map[string]any)
+					for _, k := range KeysOf(m) {
+						if nil != m[k] {
+							next = append(next, m[k])
+						}
+					}
+				} else if IsList(val) {
+					for _, child := range _listify(val) {
+						if nil != child {
+							next = append(next, child)
+						}
+					}
+				}
+			} else if child := GetProp(val, parts[pI]); nil != child {
+				next = append(next, child)
+			}
+		}
+		vals = next
+	}
+	return vals
+}
+
+// Resolve path parts (which may contain wildcards) against a node,
+// returning the concrete path of each defined match, in key order.
+func _getPathMatches(node any, parts []string, wild []bool) [][]string {
+	matches := [][]string{{}}
+	vals := []any{node}
+
+	for pI, part := range parts {
+		nextmatches := [][]string{}
+		nextvals := []any{}
+
+		for vI, val := range vals {
+			var keys []string
+			if _isWild(wild, pI) {
+				if IsMap(val) {
+					keys = KeysOf(val)
+				} else if IsList(val) {
+					keys = make([]string, len(_listify(val)))
+					for i := range keys {
+						keys[i] = StrKey(i)
+					}
+				}
+			} else {
+				keys = []string{part}
+			}
+
+			for _, key := range keys {
+				child, found := _getProp(val, key)
+				if found && nil != child {
+					// Negative list indexes are resolved to the actual index.
+					if IsList(val) {
+						ki, _ := _listIndex(key, len(_listify(val)))
+						key = StrKey(ki)
+					}
+					nextmatches = append(nextmatches, _childPath(matches[vI], key))
+					nextvals = append(nextvals, child)
+				}
+			}
+		}
+
+		matches = nextmatches
+		vals = nextvals
+	}
+
+	return matches
+}
+
+// The key path is defined inside a node, even if the value at the
+// path is nil. An empty path refers to the store itself.
+func HasPath(path any, store any) bool {
+	parts, ok := _pathParts(path)
+	if !ok || nil == store {
+		return false
+	}
+
+	if 0 == len(parts) || (1 == len(parts) && S_MT == parts[0]) {
+		return true
+	}
+
+	val := store
+	for _, part := range parts {
+		var found bool
+		val, found = _getProp(val, part)
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Set a value deep inside a node using a key path. The path is
+// resolved as for `getpath`, and missing or scalar intermediate values
+// are replaced by maps. The final key is set using the `setprop`
+// rules, so a nil value deletes the key (see `delpath`). Returns the
+// (possibly new) store, as the store is created if needed, and list
+// references are not stable in Go.
+func SetPath(path any, store any, val any) any {
+	parts, ok := _pathParts(path)
+	if !ok {
+		return store
+	}
+
+	if nil == val {
+		return DelPath(parts, store)
+	}
+
+	if 0 == len(parts) || (1 == len(parts) && S_MT == parts[0]) {
+		return val
+	}
+
+	return _setPath(store, parts, val)
+}
+
+func _setPath(node any, parts []string, val any) any {
+	if !IsNode(node) {
+		node = map[string]any{}
+	}
+
+	key := parts[0]
+	if 1 == len(parts) {
+		return SetProp(node, key, val)
+	}
+
+	// Negative list indexes count back from the end of the list.
+	if IsList(node) {
+		if ki, ok := _listIndex(key, len(_listify(node))); ok {
+			key = StrKey(ki)
+		}
+	}
+
+	child := _setPath(GetProp(node, key), parts[1:], val)
+	return SetProp(node, key, child)
+}
+
+// Delete a value deep inside a node using a key path. The path is
+// resolved as for `getpath`, and the final key is removed using the
+// `setprop` deletion rules (list elements are removed, not set to
+// nil). Returns the (possibly modified) store, which may be a new
+// list if the store itself is a list.
+func DelPath(path any, store any) any {
+	return DelPathFlags(path, store, nil)
+}
+
+// Delete a value at a key path, with optional flags:
+// - prune: also remove ancestor nodes left empty by the deletion.
+func DelPathFlags(path any, store any, flags map[string]bool) any {
+	parts, ok := _pathParts(path)
+	if !ok || nil == store || 0 == len(parts) ||
+		(1 == len(parts) && S_MT == parts[0]) {
+		return store
+	}
+
+	// Ancestor nodes along the path, starting with the store.
+	lenparts := len(parts)
+	nodes := make([]any, lenparts)
+	nodes[0] = store
+	for pI := 1; pI < lenparts; pI++ {
+		nodes[pI] = GetProp(nodes[pI-1], parts[pI-1])
+		if !IsNode(nodes[pI]) {
+			return store
+		}
+	}
+
+	// Nothing to delete.
+	if !HasKey(nodes[lenparts-1], parts[lenparts-1]) {
+		return store
+	}
+
+	// Negative list indexes count back from the end of the list, but
+	// setprop prepends for negative keys.
+	keys := make([]string, lenparts)
+	for pI, node := range nodes {
+		keys[pI] = parts[pI]
+		if IsList(node) {
+			ki, _ := _listIndex(parts[pI], len(_listify(node)))
+			keys[pI] = StrKey(ki)
+		}
+	}
+
+	prune := nil != flags && flags["prune"]
+
+	// Delete, then walk back up the path, as list references are not
+	// stable in Go.
+	child := SetProp(nodes[lenparts-1], keys[lenparts-1], nil)
+	for pI := lenparts - 2; -1 < pI; pI-- {
+		if prune && IsEmpty(child) {
+			child = SetProp(nodes[pI], keys[pI], nil)
+		} else {
+			child = SetProp(nodes[pI], keys[pI], child)
+		}
+	}
+
+	return child
+}
+
+// Injection syntax (see also ParseInjection).
+var (
+	// The whole string is an injection.
+	// reInjectFull = regexp.MustCompile("^`([^`]+)[0-9]*`$")
+	reInjectFull = regexp.MustCompile("^`(\\$[A-Z]+|[^`]+)[0-9]*`$")
+
+	// Injections within a string.
+	reInjectPart = regexp.MustCompile("`([^`]+)`")
+
+	// A whole string transform with an argument, such as `$REGEX:^a$`.
+	reInjectArg = regexp.MustCompile(`^(\$[A-Z]+):([\s\S]*)$`)
+)
+
 // Inject store values into a string. Not a public utility - used by
 // `inject`.  Inject are marked with `path` where path is resolved
 // with getpath against the store or current (if defined)
@@ -984,9 +1325,7 @@ func _injectStr(
 	}
 
 	// Pattern examples: "`a.b.c`", "`$NAME`", "`$NAME1`"
-	// fullRe := regexp.MustCompile("^`([^`]+)[0-9]*`$")
-	fullRe := regexp.MustCompile("^`(\\$[A-Z]+|[^`]+)[0-9]*`$")
-	matches := fullRe.FindStringSubmatch(val)
+	matches := reInjectFull.FindStringSubmatch(val)
 
 	// Full string of the val is an injection.
 	if matches != nil {
@@ -1001,15 +1340,23 @@ func _injectStr(
 			pathref = strings.ReplaceAll(pathref, "$DS", S_DS)
 		}
 
+		// The argument of a transform is not part of the path, and is
+		// passed to the transform in the reference.
+		if m := reInjectArg.FindStringSubmatch(pathref); nil != m && nil != state && nil != state.Handler {
+			return _unraw(state.Handler(state, GetProp(store, m[1]), current, &pathref, store))
+		}
+
 		// Get the extracted path reference.
 		out := GetPathState(pathref, store, current, state)
 
-		return out
+		return _unraw(out)
 	}
 
+	// A raw value found within the string replaces the entire string.
+	var raw *RawValue
+
 	// Check for injections within the string.
-	partialRe := regexp.MustCompile("`([^`]+)`")
-	out := partialRe.ReplaceAllStringFunc(val, func(m string) string {
+	out := reInjectPart.ReplaceAllStringFunc(val, func(m string) string {
 		ref := strings.Trim(m, "`")
 
 		// Special escapes inside injection.
@@ -1022,6 +1369,13 @@ func _injectStr(
 		}
 		found := GetPathState(ref, store, current, state)
 
+		if rv, ok := found.(RawValue); ok {
+			if nil == raw {
+				raw = &rv
+			}
+			return S_MT
+		}
+
 		if nil == found {
 			return S_MT
 		}
@@ -1034,14 +1388,34 @@ func _injectStr(
 		}
 	})
 
+	var result any = out
+	if nil != raw {
+		result = raw.V
+	}
+
 	// Also call the state handler on the entire string, providing the
 	// option for custom injection.
 	if nil != state && IsFunc(state.Handler) {
 		state.Full = true
-		out = state.Handler(state, out, current, &val, store).(string)
+		result = _unraw(state.Handler(state, result, current, &val, store))
 	}
 
-	return out
+	return result
+}
+
+// A value returned by an injection handler that is used as is, rather
+// than being stringified when the injection is inside a string. The
+// entire string is replaced by V, so that handlers can produce numbers,
+// booleans and nodes in any injection context.
+type RawValue struct {
+	V any
+}
+
+func _unraw(val any) any {
+	if rv, ok := val.(RawValue); ok {
+		return rv.V
+	}
+	return val
 }
 
 // Inject values from a data store into a node recursively, resolving
@@ -1064,31 +1438,9 @@ func InjectDescend(
 ) any {
 	valType := _getType(val)
 
-	// Create state if at root of injection.  The input value is placed
-	// inside a virtual parent holder to simplify edge cases.
+	// Create state if at root of injection.
 	if state == nil {
-		parent := map[string]any{
-			S_DTOP: val,
-		}
-
-		// Set up state assuming we are starting in the virtual parent.
-		state = &Injection{
-			// Mode:    InjectModeVal,
-      Mode:    S_MVAL,
-			Full:    false,
-			KeyI:    0,
-			Keys:    []string{S_DTOP},
-			Key:     S_DTOP,
-			Val:     val,
-			Parent:  parent,
-			Path:    []string{S_DTOP},
-			Nodes:   []any{parent},
-			Handler: injectHandler,
-			Base:    S_DTOP,
-			Modify:  modify,
-			Errs:    GetProp(store, S_DERRS, ListRefCreate[any]()).(*ListRef[any]),
-			Meta:    make(map[string]any),
-		}
+		state = _injectState(val, store, modify)
 	}
 
 	// Resolve current node in store for local paths.
@@ -1151,9 +1503,13 @@ func InjectDescend(
 				Nodes:   childnodes,
 				Handler: injectHandler,
 				Base:    state.Base,
+				NoBase:  state.NoBase,
 				Modify:  state.Modify,
 				Errs:    state.Errs,
+				Warns:   state.Warns,
 				Meta:    state.Meta,
+				Log:     state.Log,
+				Prefix:  state.Prefix,
 			}
 
 			// Peform the key:pre mode injection on the child key.
@@ -1228,6 +1584,55 @@ func InjectDescend(
   return rval
 }
 
+// Create the root injection state. The input value is placed inside
+// a virtual parent holder to simplify edge cases.
+func _injectState(val any, store any, modify Modify) *Injection {
+	parent := map[string]any{
+		S_DTOP: val,
+	}
+
+	// Set up state assuming we are starting in the virtual parent.
+	return &Injection{
+		// Mode:    InjectModeVal,
+		Mode:    S_MVAL,
+		Full:    false,
+		KeyI:    0,
+		Keys:    []string{S_DTOP},
+		Key:     S_DTOP,
+		Val:     val,
+		Parent:  parent,
+		Path:    []string{S_DTOP},
+		Nodes:   []any{parent},
+		Handler: injectHandler,
+		Base:    S_DTOP,
+		Modify:  modify,
+		Errs:    GetProp(store, S_DERRS, ListRefCreate[any]()).(*ListRef[any]),
+		Warns:   GetProp(store, S_DWARNS, ListRefCreate[any]()).(*ListRef[any]),
+		Meta:    make(map[string]any),
+		Log:     StoreLogger(store),
+	}
+}
+
+// State for a nested injection (such as the children of `$EACH`),
+// whose output is placed at the parent of the current node.
+func _nestedState(val any, store any, state *Injection) *Injection {
+	nested := _injectState(val, store, state.Modify)
+	nested.Prefix = _outPath(state)
+	if 0 < len(nested.Prefix) {
+		nested.Prefix = nested.Prefix[:len(nested.Prefix)-1]
+	}
+	return nested
+}
+
+// Output path of the current node.
+func _outPath(state *Injection) []string {
+	out := append([]string{}, state.Prefix...)
+	if 1 < len(state.Path) {
+		out = append(out, state.Path[1:]...)
+	}
+	return out
+}
+
 // Default inject handler for transforms. If the path resolves to a function,
 // call the function passing the injection state. This is how transforms operate.
 var injectHandler Injector = func(
@@ -1242,6 +1647,27 @@ var injectHandler Injector = func(
 	iscmd := IsFunc(val) && (nil == ref || strings.HasPrefix(*ref, S_DS))
 
 	if iscmd {
+		refstr := S_MT
+		if nil != ref {
+			refstr = *ref
+		}
+		_logDebug(state.Log, "inject", "ref", refstr, "mode", state.Mode,
+			"path", Pathify(state.Path, 1))
+
+		// Log handler panics with their location, then continue panicking.
+		if nil != state.Log {
+			defer func() {
+				if r := recover(); nil != r {
+					// Cancellation (see TransformCtx) is not an error.
+					if _, abort := r.(transformAbort); !abort {
+						_logError(state.Log, "inject handler panic", "ref", refstr,
+							"mode", state.Mode, "path", Pathify(state.Path, 1), "panic", r)
+					}
+					panic(r)
+				}
+			}()
+		}
+
 		fnih, ok := val.(Injector)
 
 		if ok {
@@ -1289,6 +1715,7 @@ var Transform_COPY Injector = func(
 
 	if !strings.HasPrefix(string(state.Mode), "key") {
 		out = GetProp(current, state.Key)
+		_markUsed(store, state.Path[1:])
     _setParentProp("CP", state, out)
 	}
 
@@ -1367,6 +1794,7 @@ var Transform_MERGE Injector = func(
 		args := GetProp(state.Parent, state.Key)
 		if S_MT == args {
 			args = []any{GetProp(store, S_DTOP)}
+			_markUsed(store, []string{})
 		} else if IsList(args) {
 			// do nothing
 		} else {
@@ -1427,6 +1855,14 @@ var Transform_EACH Injector = func(
   // var src any = nil
   srcstore := GetProp(store, state.Base, store)
   src := GetPathState(srcpath, srcstore, current, nil)
+  if parts, ok := _pathParts(srcpath); ok {
+    _markUsed(store, parts)
+  }
+
+	// Check the output size before creating the children.
+	if IsNode(src) {
+		_reserveOutput(store, NumKeys(src), child, state.Path)
+	}
   
 	// Create parallel data structures:
 	// source entries :: child templates
@@ -1450,7 +1886,7 @@ var Transform_EACH Injector = func(
 		newlist := make([]any, len(srcList))
 		for i := range srcList {
 			newlist[i] = Clone(child)
-			SetProp(tcur, i, srcList[i])
+			tcur = SetProp(tcur, i, srcList[i])
 		}
 		tval = newlist
 
@@ -1477,6 +1913,9 @@ var Transform_EACH Injector = func(
 			tcur = SetProp(tcur, i, v)
 		}
 		tval = newlist
+
+	} else if nil != src {
+		state.Warn("each-source", "Source for $EACH is not a list or map: "+Typify(src))
 	}
 
 	// Parent structure.
@@ -1485,7 +1924,7 @@ var Transform_EACH Injector = func(
 	}
 
 	// Build the substructure.
-	tval = InjectDescend(tval, store, state.Modify, tcur, nil)
+	tval = InjectDescend(tval, store, state.Modify, tcur, _nestedState(tval, store, state))
 
   state.Parent = tval
 	// _updateAncestors("EACH", state, target, tkey, tval)
@@ -1552,7 +1991,9 @@ var Transform_PACK Injector = func(
 	} else if IsMap(src) {
 		m := src.(map[string]any)
 		tmp := make([]any, 0, len(m))
-		for k, v := range m {
+		// Sorted, so that the same input always gives the same output.
+		for _, k := range KeysOf(m) {
+			v := m[k]
 			// carry forward the KEY in DMeta
 			vmeta := GetProp(v, S_DMETA)
 			if vmeta == nil {
@@ -1566,6 +2007,9 @@ var Transform_PACK Injector = func(
 		srclist = tmp
 	} else {
 		// no valid source
+		if nil != src {
+			state.Warn("pack-source", "Source for $PACK is not a list or map: "+Typify(src))
+		}
 		return nil
 	}
 
@@ -1596,13 +2040,57 @@ var Transform_PACK Injector = func(
 		S_DTOP: tcurrent,
 	}
 
-	tvalout := InjectDescend(tval, store, state.Modify, tcur, nil)
+	tvalout := InjectDescend(tval, store, state.Modify, tcur, _nestedState(tval, store, state))
 
 	SetProp(target, tkey, tvalout)
 
 	return nil
 }
 
+
+// Assert that source data values equal expected literals, for
+// self-testing specs. Format: { '`$ASSERT`': { 'path': expected, ... } }.
+// Paths are resolved as for `$COPY`, and the expected values are not
+// injected. Failures are appended to the error collector, and listed
+// in the injection meta data under `$ASSERT`. The output is not
+// affected, as the `$ASSERT` key is removed.
+var Transform_ASSERT Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	if S_MKEYPRE != state.Mode {
+		return nil
+	}
+
+	args := GetProp(state.Parent, state.Key)
+	_setParentProp("ASRT", state, nil)
+
+	srcstore := GetProp(store, state.Base, store)
+
+	for _, path := range KeysOf(args) {
+		expected := GetProp(args, path)
+		found := GetPathState(path, srcstore, current, nil)
+
+		if !_jsonEqual(expected, found) {
+			state.Errs.Append("Assertion failed at " + path + ": expected " +
+				Stringify(expected) + ", but found " + Stringify(found) + ".")
+
+			failures, _ := state.Meta[S_DASSERT].([]any)
+			state.Meta[S_DASSERT] = append(failures, map[string]any{
+				"path":     path,
+				"expected": expected,
+				"found":    found,
+			})
+		}
+	}
+
+	// Skip the assertion arguments.
+	return nil
+}
+
 // ---------------------------------------------------------------------
 // Transform function: top-level
 
@@ -1619,6 +2107,200 @@ func TransformModify(
 	extra any, // extra store
 	modify Modify, // optional modify
 ) any {
+	return TransformWith(data, spec, &TransformOptions{
+		Extra:  extra,
+		Modify: modify,
+	})
+}
+
+// Options for TransformWith.
+type TransformOptions struct {
+	Extra  any    // Extra store data and transforms.
+	Modify Modify // Modify injection output.
+	Env    *Env   // Sources of time, randomness and identifiers.
+	Logger Logger // Trace output and handler panics.
+	NoBase bool   // No fallback to the data for top level paths (use `$TOP.a`).
+
+	// Limits on the output size (zero means no limit). A transform
+	// that exceeds a limit stops with no output, and an ErrLimit error
+	// is appended to the `$ERRS` collector.
+	MaxNodes int // Maximum number of output values.
+	MaxBytes int // Maximum (approximate) JSON size of the output.
+
+	// Limits on the data and spec, checked before the transform (see
+	// Limits). The node and byte limits also apply to the output, as
+	// for MaxNodes and MaxBytes. A transform that exceeds a limit
+	// stops with no output, and an ErrLimitExceeded error is appended
+	// to the `$ERRS` collector.
+	Limits *Limits
+
+	// Convert each leaf of the output as it is injected, before Modify
+	// is called.
+	Convert Convert
+
+	// Carry over source data not used by the spec (see UnmappedMode).
+	Unmapped UnmappedMode
+
+	// Limits on provider lookups (see Provider) for each transform. If
+	// nil, DefaultProviderBudget is used.
+	Budget *ProviderBudget
+
+	// Previous output for the same input. If defined, only the changed
+	// subtree of the output is returned, in JSON Merge Patch form:
+	// unchanged map keys are omitted, removed keys have a nil value,
+	// and changed lists and scalars are returned in full.
+	Previous any
+
+	// Replay a recorded transform (see Manifest). The environment is
+	// seeded from the manifest, replacing Env, and the data and spec
+	// must match the manifest hashes, otherwise the transform stops
+	// with no output and an ErrSpec error is appended to `$ERRS`.
+	Manifest *Manifest
+
+	// Record a new Manifest in the TransformResult of TransformCollect,
+	// if no Manifest is given.
+	Record bool
+
+	// Memory budget (see Quota). The clones of the data and spec, and
+	// the output, are counted. A transform that exceeds the quota stops
+	// with no output, and an ErrLimit error is appended to `$ERRS`.
+	Quota *Quota
+}
+
+// The built-in transforms of the injection store.
+func _transformStore(env *Env) map[string]any {
+	return map[string]any{
+		// Handy escapes
+		"$BT": func() any { return S_BT },
+		"$DS": func() any { return S_DS },
+
+		// Insert current date/time
+		"$WHEN": func() any {
+			return env.Clock().UTC().Format(time.RFC3339)
+		},
+
+		// Insert a random number in [0.0,1.0)
+		"$RANDOM": func() any {
+			return env.Rand()
+		},
+
+		// Insert a unique identifier
+		"$UUID": func() any {
+			return env.IDGen()
+		},
+
+		// Built-in transform functions
+		"$DELETE": Transform_DELETE,
+		"$COPY":   Transform_COPY,
+		"$KEY":    Transform_KEY,
+		"$META":   Transform_META,
+		"$MERGE":  Transform_MERGE,
+		"$EACH":   Transform_EACH,
+		"$PACK":   Transform_PACK,
+		S_DASSERT: Transform_ASSERT,
+		"$ROUND":  Transform_ROUND,
+		"$FLOOR":  Transform_FLOOR,
+		"$CEIL":   Transform_CEIL,
+		"$ABS":    Transform_ABS,
+		"$CLAMP":  Transform_CLAMP,
+		"$RANGE":  Transform_RANGE,
+		"$REPEAT": Transform_REPEAT,
+		"$ZIP":    Transform_ZIP,
+		"$TREE":   Transform_TREE,
+		"$SWITCH": Transform_SWITCH,
+		"$FILTER": Transform_FILTER,
+		"$MAP":    Transform_MAP,
+		"$SUM":    Transform_SUM,
+		"$MIN":    Transform_MIN,
+		"$MAX":    Transform_MAX,
+		"$AVG":    Transform_AVG,
+		"$COUNT":  Transform_COUNT,
+		"$REDUCE": Transform_REDUCE,
+
+		"$PAIRS":     Transform_PAIRS,
+		"$FROMPAIRS": Transform_FROMPAIRS,
+	}
+}
+
+// Sorted names of the built-in transforms (such as `$EACH`).
+func TransformNames() []string {
+	return KeysOf(_transformStore(_resolveEnv(nil)))
+}
+
+// The built-in validators of the validation store.
+func _validatorStore() map[string]any {
+	return map[string]any{
+		"$STRING":   validate_STRING,
+		"$NUMBER":   validate_NUMBER,
+		"$BOOLEAN":  validate_BOOLEAN,
+		"$OBJECT":   validate_OBJECT,
+		"$ARRAY":    validate_ARRAY,
+		"$FUNCTION": validate_FUNCTION,
+		"$ANY":      validate_ANY,
+		"$CHILD":    validate_CHILD,
+		"$ONE":      validate_ONE,
+		"$EXACT":    validate_EXACT,
+		"$REQUIRED": validate_REQUIRED,
+		S_DREGEX:    validate_REGEX,
+		S_DVALID:    validate_VALID,
+		S_DDEFAULT:  validate_DEFAULT,
+	}
+}
+
+// Sorted names of the built-in validators (such as `$STRING`).
+func ValidatorNames() []string {
+	return KeysOf(_validatorStore())
+}
+
+func TransformWith(
+	data any, // source data
+	spec any, // transform specification
+	opts *TransformOptions, // optional options
+) any {
+	if nil == opts {
+		opts = &TransformOptions{}
+	}
+	extra := opts.Extra
+	modify := opts.Modify
+	env := _resolveEnv(opts.Env)
+
+	if nil != opts.Manifest {
+		if err := opts.Manifest.Check(data, spec); nil != err {
+			_logWarn(opts.Logger, "manifest", "error", err.Error())
+			if errs, ok := GetProp(extra, S_DERRS).(*ListRef[any]); ok {
+				errs.Append(err)
+			}
+			return nil
+		}
+		env = _resolveEnv(opts.Manifest.Env())
+	}
+
+	// Reject oversized input before it is cloned.
+	if err := _checkTransformLimits(data, spec, opts); nil != err {
+		_logWarn(opts.Logger, "input limit", "error", err.Error())
+		if errs, ok := GetProp(extra, S_DERRS).(*ListRef[any]); ok {
+			errs.Append(err)
+		}
+		return nil
+	}
+
+	// The data and spec clones are allocated from the quota.
+	var alloc int64
+	if nil != opts.Quota {
+		var err error
+		alloc, err = _allocCheck(data, 0, opts.Quota.MaxBytes)
+		if nil == err {
+			alloc, err = _allocCheck(spec, alloc, opts.Quota.MaxBytes)
+		}
+		if nil != err {
+			opts.Quota.used.Add(alloc)
+			_logWarn(opts.Logger, "quota", "error", err.Error())
+			if errs, ok := GetProp(extra, S_DERRS).(*ListRef[any]); ok {
+				errs.Append(err)
+			}
+			return nil
+		}
+	}
 
 	// Clone the spec so that the clone can be modified in place as the transform result.
 	spec = Clone(spec)
@@ -1655,35 +2337,72 @@ func TransformModify(
 	})
 
 	// The injection store with transform functions
-	store := map[string]any{
-		// Merged data is at $TOP
-		S_DTOP: dataClone,
+	store := _transformStore(env)
 
-		// Handy escapes
-		"$BT": func() any { return S_BT },
-		"$DS": func() any { return S_DS },
-
-		// Insert current date/time
-		"$WHEN": func() any {
-			return time.Now().UTC().Format(time.RFC3339)
-		},
+	// Merged data is at $TOP
+	store[S_DTOP] = dataClone
 
-		// Built-in transform functions
-		"$DELETE": Transform_DELETE,
-		"$COPY":   Transform_COPY,
-		"$KEY":    Transform_KEY,
-		"$META":   Transform_META,
-		"$MERGE":  Transform_MERGE,
-		"$EACH":   Transform_EACH,
-		"$PACK":   Transform_PACK,
-	}
+	// Sources of nondeterminism.
+	store[S_DENV] = env
 
 	// Add any extra transforms
 	for k, v := range extraTransforms {
 		store[k] = v
 	}
 
-	out := InjectDescend(spec, store, modify, store, nil)
+	if nil != opts.Logger {
+		store[S_DLOG] = opts.Logger
+	}
+
+	// Provider lookups are counted per transform.
+	budget := opts.Budget
+	if nil == budget {
+		budget = DefaultProviderBudget()
+	}
+	store[S_DCALL] = &providerUsage{budget: *budget}
+
+	if S_MT != opts.Unmapped {
+		store[S_DUSED] = &usedPaths{}
+	}
+
+	if nil != opts.Convert {
+		modify = _convertModify(opts.Convert, modify)
+	}
+
+	maxNodes, maxBytes := opts.MaxNodes, opts.MaxBytes
+	if nil != opts.Limits {
+		maxNodes = _minLimit(maxNodes, opts.Limits.MaxNodes)
+		maxBytes = _minLimit(maxBytes, opts.Limits.MaxBytes)
+	}
+
+	if 0 < maxNodes || 0 < maxBytes || nil != opts.Quota {
+		usage := &outputUsage{maxNodes: maxNodes, maxBytes: maxBytes, alloc: alloc}
+		if nil != opts.Quota {
+			usage.maxAlloc = opts.Quota.MaxBytes
+			defer func() { opts.Quota.used.Add(usage.alloc) }()
+		}
+		store[S_DSIZE] = usage
+		modify = usage.modify(modify)
+	}
+
+	state := _injectState(spec, store, modify)
+	state.NoBase = opts.NoBase
+
+	out, err := _injectLimited(spec, store, modify, store, state)
+	if nil != err {
+		_logWarn(state.Log, "output limit", "error", err.Error())
+		state.Errs.Append(err)
+		return nil
+	}
+
+	if S_MT != opts.Unmapped {
+		out = _addUnmapped(out, dataClone, store[S_DUSED].(*usedPaths), opts.Unmapped)
+	}
+
+	// Only emit the changes from the previous output.
+	if nil != opts.Previous {
+		out, _ = _changedTree(opts.Previous, out)
+	}
 
 	return out
 }
@@ -1696,17 +2415,19 @@ var validate_STRING Injector = func(
 	store any,
 ) any {
 	out := GetProp(current, state.Key)
+	if nil == out && _validateOptional(state, store) {
+		return nil
+	}
 
 	t := Typify(out)
 	if S_string != t {
-		msg := _invalidTypeMsg(state.Path, S_string, t, out)
-		state.Errs.Append(msg)
+		_invalidType(state, store, state.Path, S_string, t, out)
 		return nil
 	}
 
 	if S_MT == out.(string) {
-		msg := "Empty string at " + Pathify(state.Path, 0)
-		state.Errs.Append(msg)
+		_invalid(state, store, state.Path, VE_EMPTY, S_string, out,
+			"Empty string at "+Pathify(state.Path, 0))
 		return nil
 	}
 
@@ -1721,11 +2442,13 @@ var validate_NUMBER Injector = func(
 	store any,
 ) any {
 	out := GetProp(current, state.Key)
+	if nil == out && _validateOptional(state, store) {
+		return nil
+	}
 
 	t := Typify(out)
 	if S_number != t {
-		msg := _invalidTypeMsg(state.Path, S_number, t, out)
-		state.Errs.Append(msg)
+		_invalidType(state, store, state.Path, S_number, t, out)
 		return nil
 	}
 
@@ -1740,11 +2463,13 @@ var validate_BOOLEAN Injector = func(
 	store any,
 ) any {
 	out := GetProp(current, state.Key)
+	if nil == out && _validateOptional(state, store) {
+		return nil
+	}
 
 	t := Typify(out)
 	if S_boolean != t {
-		msg := _invalidTypeMsg(state.Path, S_boolean, t, out)
-		state.Errs.Append(msg)
+		_invalidType(state, store, state.Path, S_boolean, t, out)
 		return nil
 	}
 
@@ -1759,12 +2484,14 @@ var validate_OBJECT Injector = func(
 	store any,
 ) any {
 	out := GetProp(current, state.Key)
+	if nil == out && _validateOptional(state, store) {
+		return nil
+	}
 
 	t := Typify(out)
 
 	if S_object != t {
-		msg := _invalidTypeMsg(state.Path, S_object, t, out)
-		state.Errs.Append(msg)
+		_invalidType(state, store, state.Path, S_object, t, out)
 
     return nil
 	}
@@ -1780,11 +2507,13 @@ var validate_ARRAY Injector = func(
 	store any,
 ) any {
 	out := GetProp(current, state.Key)
+	if nil == out && _validateOptional(state, store) {
+		return nil
+	}
 
 	t := Typify(out)
 	if S_array != t {
-		msg := _invalidTypeMsg(state.Path, S_array, t, out)
-		state.Errs.Append(msg)
+		_invalidType(state, store, state.Path, S_array, t, out)
 		return nil
 	}
 
@@ -1799,11 +2528,13 @@ var validate_FUNCTION Injector = func(
 	store any,
 ) any {
 	out := GetProp(current, state.Key)
+	if nil == out && _validateOptional(state, store) {
+		return nil
+	}
 
 	t := Typify(out)
 	if S_function != t {
-		msg := _invalidTypeMsg(state.Path, S_function, t, out)
-		state.Errs.Append(msg)
+		_invalidType(state, store, state.Path, S_function, t, out)
 		return nil
 	}
 
@@ -1838,13 +2569,7 @@ var validate_CHILD Injector = func(
 			tval = map[string]any{}
 
 		} else if !IsMap(tval) {
-			state.Errs.Append(
-				_invalidTypeMsg(
-					state.Path[:len(state.Path)-1],
-					S_object,
-					Typify(tval),
-					tval,
-				))
+			_invalidType(state, store, state.Path[:len(state.Path)-1], S_object, Typify(tval), tval)
 			return nil
 		}
 
@@ -1865,7 +2590,7 @@ var validate_CHILD Injector = func(
 
 		// We expect 'parent' to be a slice of any, like ["`$CHILD`", childTemplate].
 		if !IsList(state.Parent) {
-			state.Errs.Append("Invalid $CHILD as value")
+			_invalidSpec(state, store, "Invalid $CHILD as value")
 			return nil
 		}
 
@@ -1881,13 +2606,7 @@ var validate_CHILD Injector = func(
 
 		// If current is not a list => error
 		if !IsList(current) {
-			state.Errs.Append(
-				_invalidTypeMsg(
-					state.Path[:len(state.Path)-1],
-					S_array,
-					Typify(current),
-					current,
-				))
+			_invalidType(state, store, state.Path[:len(state.Path)-1], S_array, Typify(current), current)
 			state.KeyI = len(state.Parent.([]any))
 			return current
 		}
@@ -1916,11 +2635,26 @@ var validate_CHILD Injector = func(
 }
 
 // Forward declaration for validate_ONE
+// The value must match one of the alternative shapes. If none match,
+// and an alternative is a node shape of the same type as the value
+// (such as a map shape for a map), the errors of the closest such
+// alternative (with the fewest errors) are reported, rather than a
+// list of the alternatives.
 var validate_ONE Injector
 
 // Forward declaration for validate_EXACT
 var validate_EXACT Injector
 
+// Set the validators that call ValidateCollect when the package is
+// initialized, as a variable initializer would be a circular
+// reference, and lazy initialization would race with concurrent
+// validation.
+func init() {
+	init_validate_ONE()
+	init_validate_EXACT()
+	init_validate_DEFAULT()
+}
+
 // Implementation will be set after ValidateCollect is defined
 func init_validate_ONE() {
 	validate_ONE = func(
@@ -1934,8 +2668,8 @@ func init_validate_ONE() {
 		if state.Mode == S_MVAL {
 			// Validate that parent is a list and we're at the first element
 			if !IsList(state.Parent) || state.KeyI != 0 {
-				state.Errs.Append("The $ONE validator at field " +
-					Pathify(state.Path, 1, 1) +
+				_invalidSpec(state, store, "The $ONE validator at field "+
+					Pathify(state.Path, 1, 1)+
 					" must be the first element of an array.")
 				return nil
 			}
@@ -1967,14 +2701,35 @@ func init_validate_ONE() {
 			
 			// Ensure we have at least one alternative
 			if len(tvals) == 0 {
-				state.Errs.Append("The $ONE validator at field " +
-					Pathify(state.Path, 1, 1) +
+				_invalidSpec(state, store, "The $ONE validator at field "+
+					Pathify(state.Path, 1, 1)+
 					" must have at least one argument.")
 				return nil
 			}
 
+			// A missing optional field is not checked, unless the first
+			// alternative is a default.
+			if nil == current && !_isEnumValue(tvals[0]) && _validateOptional(state, store) {
+				return nil
+			}
+
+			// The closest alternative is a node shape of the same type as
+			// the value, with the fewest errors.
+			var closest any
+			closestErrs := 0
+
 			// Try each alternative shape
 			for _, tval := range tvals {
+				// A literal scalar alternative is an enum value, that must be
+				// matched exactly. If there is no value, it is a default.
+				if nil != current && _isEnumValue(tval) {
+					if _enumMatch(tval, current) {
+						SetProp(grandparent, grandkey, current)
+						return nil
+					}
+					continue
+				}
+
 				// Collect errors in a temporary slice
 				var terrs = ListRefCreate[any]()
 
@@ -1982,6 +2737,9 @@ func init_validate_ONE() {
 				vstore := Clone(store).(map[string]any)
 				vstore["$TOP"] = current
 
+				// The errors of an alternative are only collected in terrs.
+				delete(vstore, S_DVERRS)
+
 				// Attempt validation of `current` with shape `tval`
 				vcurrent, err := ValidateCollect(current, tval, vstore, terrs)
 
@@ -1992,6 +2750,20 @@ func init_validate_ONE() {
 				if err == nil && len(terrs.List) == 0 {
 					return nil
 				}
+
+				if IsNode(tval) && Typify(tval) == Typify(current) &&
+					(nil == closest || len(terrs.List) < closestErrs) {
+					closest = tval
+					closestErrs = len(terrs.List)
+				}
+			}
+
+			SetProp(grandparent, grandkey, current)
+
+			// Report the errors of the closest alternative, if any.
+			if nil != closest {
+				_validateAt(state, store, state.Path, current, closest)
+				return nil
 			}
 
 			// If we get here, there was no match
@@ -2023,7 +2795,7 @@ func init_validate_ONE() {
 				current,
 				"V0210",
 			)
-			state.Errs.Append(msg)
+			_invalid(state, store, state.Path, VE_ONE, tvals, current, msg)
 		}
 
 		return nil
@@ -2042,8 +2814,8 @@ func init_validate_EXACT() {
 		if state.Mode == S_MVAL {
 			// Validate that parent is a list and we're at the first element
 			if !IsList(state.Parent) || state.KeyI != 0 {
-				state.Errs.Append("The $EXACT validator at field " +
-					Pathify(state.Path, 1, 1) +
+				_invalidSpec(state, _store, "The $EXACT validator at field "+
+					Pathify(state.Path, 1, 1)+
 					" must be the first element of an array.")
 				return nil
 			}
@@ -2074,12 +2846,17 @@ func init_validate_EXACT() {
 
 			// Ensure we have at least one alternative
 			if len(tvals) == 0 {
-				state.Errs.Append("The $EXACT validator at field " +
-					Pathify(state.Path, 1, 1) +
+				_invalidSpec(state, _store, "The $EXACT validator at field "+
+					Pathify(state.Path, 1, 1)+
 					" must have at least one argument.")
 				return nil
 			}
 
+			// A missing optional field is not checked.
+			if nil == current && _validateOptional(state, _store) {
+				return nil
+			}
+
 			// See if we can find an exact value match
 			var currentStr *string
 			for _, tval := range tvals {
@@ -2143,7 +2920,7 @@ func init_validate_EXACT() {
 				current,
 				"V0110",
 			)
-			state.Errs.Append(msg)
+			_invalid(state, _store, state.Path, VE_EXACT, tvals, current, msg)
 		} else {
 			SetProp(state.Parent, state.Key, nil)
 		}
@@ -2152,6 +2929,42 @@ func init_validate_EXACT() {
 	}
 }
 
+// Validate a value against a shape, such as the closest alternative
+// of a `$ONE`, in the context of the field at path, so that errors
+// have full paths. Returns the validated value.
+func _validateAt(state *Injection, store any, path []string, current any, shape any) any {
+	data, spec := current, Clone(shape)
+	for pI := len(path) - 1; 0 < pI; pI-- {
+		data = map[string]any{path[pI]: data}
+		spec = map[string]any{path[pI]: spec}
+	}
+
+	vstore := map[string]any{}
+	for k, v := range _storeMap(store) {
+		vstore[k] = v
+	}
+	vstore[S_DTOP] = data
+
+	out, _ := ValidateCollect(data, spec, vstore, state.Errs)
+	return GetPath(path[1:], out)
+}
+
+// Scalar shape value that is not a validator (such as `$STRING`).
+func _isEnumValue(tval any) bool {
+	if IsNode(tval) || IsFunc(tval) {
+		return false
+	}
+	if s, ok := tval.(string); ok && strings.Contains(s, S_BT) {
+		return false
+	}
+	return true
+}
+
+// Numbers match by value, whatever their Go type.
+func _enumMatch(tval any, val any) bool {
+	return Typify(tval) == Typify(val) && Stringify(tval) == Stringify(val)
+}
+
 func validation(
 	val any,
 	key any,
@@ -2167,6 +2980,10 @@ func validation(
 	// Current val to verify.
 	cval := GetProp(current, key)
 	if cval == nil {
+		// A missing optional object is not created.
+		if IsMap(GetProp(parent, key)) && _validateOptional(state, _store) {
+			SetProp(parent, key, nil)
+		}
 		return
 	}
 
@@ -2184,7 +3001,7 @@ func validation(
 
 	// Type mismatch.
 	if ptype != ctype && pval != nil {
-		state.Errs.Append(_invalidTypeMsg(state.Path, ptype, ctype, cval))
+		_invalidType(state, _store, state.Path, ptype, ctype, cval)
 		return
 	}
 
@@ -2196,15 +3013,17 @@ func validation(
 			} else {
 				errType = ptype
 			}
-			state.Errs.Append(_invalidTypeMsg(state.Path, errType, ctype, cval))
+			_invalidType(state, _store, state.Path, errType, ctype, cval)
 			return
 		}
 
 		ckeys := KeysOf(cval)
 		pkeys := KeysOf(pval)
 
-		// Empty spec object {} means object can be open (any keys).
-		if len(pkeys) > 0 && GetProp(pval, "`$OPEN`") != true {
+		// Empty spec object {} means object can be open (any keys),
+		// unless validation is closed.
+		closed := true == GetProp(_store, S_DCLOSED)
+		if (len(pkeys) > 0 || closed) && GetProp(pval, "`$OPEN`") != true {
 			badkeys := []string{}
 			for _, ckey := range ckeys {
 				if !HasKey(val, ckey) {
@@ -2213,9 +3032,16 @@ func validation(
 			}
 
 			// Closed object, so reject extra keys not in shape.
-			if len(badkeys) > 0 {
-				state.Errs.Append("Unexpected keys at field " + Pathify(state.Path, 1) +
-					": " + strings.Join(badkeys, ", "))
+			if closed {
+				for _, bkey := range badkeys {
+					bpath := append(append([]string{}, state.Path...), bkey)
+					_invalid(state, _store, bpath, VE_UNKNOWN, pkeys, GetProp(cval, bkey),
+						"Unknown field "+Pathify(bpath, 1)+".")
+				}
+			} else if len(badkeys) > 0 {
+				_invalid(state, _store, state.Path, VE_KEYS, pkeys, badkeys,
+					"Unexpected keys at field "+Pathify(state.Path, 1)+
+						": "+strings.Join(badkeys, ", "))
 			}
 		} else {
 			// Object is open, so merge in extra keys.
@@ -2226,7 +3052,7 @@ func validation(
 		}
 	} else if IsList(cval) {
 		if !IsList(val) {
-			state.Errs.Append(_invalidTypeMsg(state.Path, ptype, ctype, cval))
+			_invalidType(state, _store, state.Path, ptype, ctype, cval)
 		}
 	} else {
 		// Spec value was a default, copy over data
@@ -2256,17 +3082,6 @@ func ValidateCollect(
 		errs = ListRefCreate[any]()
 	}
 
-  
-	// Initialize validate_ONE if not already initialized.
-	// This avoids a circular reference error, validate_ONE calls ValidateCollect.
-	if validate_ONE == nil {
-		init_validate_ONE()
-	}
-
-	// Initialize validate_EXACT if not already initialized.
-	if validate_EXACT == nil {
-		init_validate_EXACT()
-	}
 
 	// Create the store with validation commands
 	store := map[string]any{
@@ -2281,18 +3096,14 @@ func ValidateCollect(
 		"$BT":     nil,
 		"$DS":     nil,
 		"$WHEN":   nil,
+		"$RANDOM": nil,
+		"$UUID":   nil,
+		S_DASSERT: nil,
+	}
 
-		// Add validation commands
-		"$STRING":   validate_STRING,
-		"$NUMBER":   validate_NUMBER,
-		"$BOOLEAN":  validate_BOOLEAN,
-		"$OBJECT":   validate_OBJECT,
-		"$ARRAY":    validate_ARRAY,
-		"$FUNCTION": validate_FUNCTION,
-		"$ANY":      validate_ANY,
-		"$CHILD":    validate_CHILD,
-		"$ONE":      validate_ONE,
-		"$EXACT":    validate_EXACT,
+	// Add validation commands
+	for k, fn := range _validatorStore() {
+		store[k] = fn
 	}
 
 	// Add any extra validation commands
@@ -2399,40 +3210,11 @@ func _getType(v any) string {
 
 
 // StrKey converts different types of keys to string representation.
-// String keys are returned as is.
-// Number keys are converted to strings.
-// Floats are truncated to integers.
-// Booleans, objects, arrays, null, undefined all return empty string.
+// See ToKeyString for the rules.
 
 // TODO: rename to _strKey
 func StrKey(key any) string {
-	if nil == key {
-		return S_MT
-	}
-
-	switch v := key.(type) {
-	case string:
-		return v
-	case *string:
-		if nil != v {
-			return *v
-		}
-		return S_MT
-	case int:
-		return strconv.Itoa(v)
-	case int64:
-		return strconv.FormatInt(v, 10)
-	case int32:
-		return strconv.FormatInt(int64(v), 10)
-	case float64:
-		return strconv.FormatInt(int64(v), 10)
-	case float32:
-		return strconv.FormatInt(int64(v), 10)
-	case bool:
-		return S_MT
-	default:
-		return S_MT
-	}
+	return ToKeyString(key)
 }
 
 
@@ -2451,6 +3233,128 @@ func _resolveStrings(input []any) []string {
 }
 
 
+// Escape a key for use in a dotted path string: dots and backslashes
+// are escaped with a backslash.
+func EscPathKey(key string) string {
+	if !strings.ContainsAny(key, S_BS+S_DT) {
+		return key
+	}
+	key = strings.ReplaceAll(key, S_BS, S_BS+S_BS)
+	return strings.ReplaceAll(key, S_DT, S_BS+S_DT)
+}
+
+// Split a dotted path string into keys. A backslash escapes the
+// following character, so `a\.b` is the single key `a.b`.
+func _splitPath(path string) []string {
+	if !strings.Contains(path, S_BS) {
+		return strings.Split(path, S_DT)
+	}
+
+	parts := []string{}
+	var part strings.Builder
+	escaped := false
+	for _, c := range path {
+		if escaped {
+			part.WriteRune(c)
+			escaped = false
+		} else if '\\' == c {
+			escaped = true
+		} else if '.' == c {
+			parts = append(parts, part.String())
+			part.Reset()
+		} else {
+			part.WriteRune(c)
+		}
+	}
+
+	// A trailing backslash is literal.
+	if escaped {
+		part.WriteString(S_BS)
+	}
+
+	return append(parts, part.String())
+}
+
+// The wildcard parts of a path: the parts of a path string (or a
+// compiled path) that are an unescaped `*`. The parts of a list of
+// keys are literal keys, so a list path has no wildcards. Returns nil
+// if there are no wildcards.
+func _pathWildcards(path any) []bool {
+	switch pp := path.(type) {
+	case string:
+		return _stringWildcards(pp)
+	case *Path:
+		if nil != pp {
+			return pp.wild
+		}
+	}
+	return nil
+}
+
+func _stringWildcards(path string) []bool {
+	if !strings.Contains(path, S_ST) {
+		return nil
+	}
+
+	var wild []bool
+	start, pI := 0, 0
+	escaped := false
+	for i := 0; i <= len(path); i++ {
+		if len(path) == i || (!escaped && '.' == path[i]) {
+			if S_ST == path[start:i] {
+				for len(wild) <= pI {
+					wild = append(wild, false)
+				}
+				wild[pI] = true
+			}
+			start, pI = i+1, pI+1
+		} else if escaped {
+			escaped = false
+		} else if '\\' == path[i] {
+			escaped = true
+		}
+	}
+	return wild
+}
+
+func _isWild(wild []bool, pI int) bool {
+	return pI < len(wild) && wild[pI]
+}
+
+// Resolve a list key into an index for a list of length size.
+func _listIndex(key any, size int) (int, bool) {
+	return ToIndex(key, size)
+}
+
+// Resolve a path (dotted string, string array, or list of keys) into parts.
+func _pathParts(path any) ([]string, bool) {
+	switch pp := path.(type) {
+	case []string:
+		return pp, true
+
+	case string:
+		if pp == "" {
+			return []string{S_MT}, true
+		}
+		return _splitPath(pp), true
+
+	case *Path:
+		// Compiled paths are not copied, so the parts must not be modified.
+		if nil == pp {
+			return nil, false
+		}
+		return pp.parts, true
+
+	default:
+		if IsList(path) {
+			return _resolveStrings(_listify(path)), true
+		}
+	}
+
+	return nil, false
+}
+
+
 func _listify(src any) []any {
 	if list, ok := src.([]any); ok {
 		return list
@@ -2604,95 +3508,3 @@ func _updateAncestors(whence string, state *Injection, target any, tkey any, tva
 	}
 
 }
-
-
-// DEBUG
-
-func fdt(data any) string {
-	return fdti(data, "")
-}
-
-func fdti(data any, indent string) string {
-	result := ""
-
-	if data == nil {
-		return indent + "nil\n"
-	}
-
-	// Get a pointer for memory address
-	memoryAddr := "0x???"
-	val := reflect.ValueOf(data)
-
-	// For non-pointer types that are addressable, get their pointer
-	if val.Kind() != reflect.Ptr && val.CanAddr() {
-		ptr := val.Addr()
-		memoryAddr = fmt.Sprintf("0x%x", ptr.Pointer())
-	} else if val.Kind() == reflect.Ptr {
-		// For pointer types, use the pointer value directly
-		memoryAddr = fmt.Sprintf("0x%x", val.Pointer())
-	} else if val.Kind() == reflect.Map || val.Kind() == reflect.Slice {
-		// For maps and slices, use the pointer to internal data
-		memoryAddr = fmt.Sprintf("0x%x", val.Pointer())
-	}
-
-	switch v := data.(type) {
-	case map[string]any:
-		result += indent + fmt.Sprintf("{ @%s\n", memoryAddr)
-		for key, value := range v {
-			result += fmt.Sprintf("%s  \"%s\": %s", indent, key, fdti(value, indent+"  "))
-		}
-		result += indent + "}\n"
-
-	case []any:
-		result += indent + fmt.Sprintf("[ @%s\n", memoryAddr)
-		for _, value := range v {
-			result += fmt.Sprintf("%s  - %s", indent, fdti(value, indent+"  "))
-		}
-		result += indent + "]\n"
-
-	default:
-		// Check if it's a struct using reflection
-		typ := val.Type()
-
-		// Handle pointers by dereferencing
-		isPtr := false
-		if val.Kind() == reflect.Ptr {
-			isPtr = true
-			if val.IsNil() {
-				return indent + "nil\n"
-			}
-			val = val.Elem()
-			typ = val.Type()
-		}
-
-		if val.Kind() == reflect.Struct {
-			structName := typ.Name()
-			if isPtr {
-				structName = "*" + structName
-			}
-			result += indent + fmt.Sprintf("struct %s @%s {\n", structName, memoryAddr)
-
-			// Iterate over all fields of the struct
-			for i := 0; i < val.NumField(); i++ {
-				field := val.Field(i)
-				fieldType := typ.Field(i)
-
-				// Skip unexported fields (lowercase field names)
-				if !fieldType.IsExported() {
-					continue
-				}
-
-				fieldName := fieldType.Name
-				fieldValue := field.Interface()
-
-				result += fmt.Sprintf("%s  %s: %s", indent, fieldName, fdti(fieldValue, indent+"  "))
-			}
-			result += indent + "}\n"
-		} else {
-			// For non-struct types, just format value with its type
-			result += fmt.Sprintf("%v (%s) @%s\n", v, reflect.TypeOf(v), memoryAddr)
-		}
-	}
-
-	return result
-}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/voxgig/struct"
 	"github.com/voxgig/struct/testutil"
@@ -212,6 +214,40 @@ func TestStruct(t *testing.T) {
 		)
 	})
 
+
+	t.Run("minor-pathify-escape", func(t *testing.T) {
+		escape := map[string]bool{"escape": true}
+
+		result0 := voxgigstruct.PathifyFlags([]any{"a.b", `c\d`, 1}, escape)
+		expected0 := `a\.b.c\\d.1`
+		if expected0 != result0 {
+			t.Errorf("Expected: %v, Got: %v", expected0, result0)
+		}
+
+		store := map[string]any{
+			"a.b": map[string]any{`c\d`: []any{0, "x"}},
+			"a":   map[string]any{"b": "y"},
+		}
+
+		path := []string{"a.b", `c\d`, "1"}
+		pathstr := voxgigstruct.PathifyFlags(path, escape)
+		if "x" != voxgigstruct.GetPath(pathstr, store) {
+			t.Errorf("Expected escaped path %v to resolve", pathstr)
+		}
+
+		if "y" != voxgigstruct.GetPath("a.b", store) {
+			t.Errorf("Expected unescaped path to resolve")
+		}
+
+		if "ab" != voxgigstruct.Pathify([]any{"a.b"}) {
+			t.Errorf("Expected dots to be removed by default")
+		}
+
+		if `a\.b` != voxgigstruct.EscPathKey("a.b") {
+			t.Errorf("Expected escaped key")
+		}
+	})
+
   
 	t.Run("minor-items", func(t *testing.T) {
 		runset(t, minorSpec["items"], voxgigstruct.Items)
@@ -265,6 +301,51 @@ func TestStruct(t *testing.T) {
 		}
 	})
 
+
+	t.Run("minor-edge-getprop-negative", func(t *testing.T) {
+		list := []any{"a", "b", "c"}
+		checks := []struct {
+			key      any
+			expected any
+		}{
+			{-1, "c"},
+			{-3, "a"},
+			{-4, nil},
+			{"-2", "b"},
+			{-1.0, "c"},
+			{"x", nil},
+		}
+
+		for _, check := range checks {
+			result := voxgigstruct.GetProp(list, check.key)
+			if !reflect.DeepEqual(check.expected, result) {
+				t.Errorf("Key: %v, Expected: %v, Got: %v", check.key, check.expected, result)
+			}
+		}
+
+		if "e" != voxgigstruct.GetProp([]string{"d", "e"}, -1) {
+			t.Errorf("Expected negative index on typed list")
+		}
+
+		store := map[string]any{"a": []any{map[string]any{"b": 1}, map[string]any{"b": 2}}}
+		if 2 != voxgigstruct.GetPath("a.-1.b", store) {
+			t.Errorf("Expected negative index in path")
+		}
+
+		path := []string{"a", "-1", "b"}
+		expected0 := map[string]any{"a": []any{map[string]any{"b": 1}, map[string]any{}}}
+		result0 := voxgigstruct.DelPath(path, store)
+		if !reflect.DeepEqual(expected0, result0) {
+			t.Errorf("Expected: %v, Got: %v", expected0, result0)
+		}
+
+		expected1 := map[string]any{"a": []any{map[string]any{"b": 1}}}
+		result1 := voxgigstruct.DelPath("a.-1", store)
+		if !reflect.DeepEqual(expected1, result1) {
+			t.Errorf("Expected: %v, Got: %v", expected1, result1)
+		}
+	})
+
   
 	t.Run("minor-setprop", func(t *testing.T) {
 		runsetFlags(
@@ -324,11 +405,87 @@ func TestStruct(t *testing.T) {
 		})
 	})
 
+
+	t.Run("minor-edge-haskey", func(t *testing.T) {
+		if !voxgigstruct.HasKey(map[string]any{"a": nil}, "a") {
+			t.Errorf("Expected key with nil value to be defined")
+		}
+
+		if !voxgigstruct.HasKey([]any{nil}, 0) {
+			t.Errorf("Expected list element with nil value to be defined")
+		}
+
+		if voxgigstruct.HasKey(map[string]any{"a": nil}, "b") {
+			t.Errorf("Expected missing key to be undefined")
+		}
+	})
+
+
+	t.Run("minor-haspath", func(t *testing.T) {
+		store := map[string]any{"a": map[string]any{"b": nil, "c": []any{1, nil}}}
+
+		checks := map[string]bool{
+			"":      true,
+			"a":     true,
+			"a.b":   true,
+			"a.c.1": true,
+			"a.c.2": false,
+			"a.x":   false,
+			"a.b.x": false,
+			"x":     false,
+		}
+
+		for path, expected := range checks {
+			if expected != voxgigstruct.HasPath(path, store) {
+				t.Errorf("Expected HasPath(%q) to be %v", path, expected)
+			}
+		}
+
+		if voxgigstruct.HasPath("a", nil) {
+			t.Errorf("Expected nil store to have no paths")
+		}
+	})
+
   
 	t.Run("minor-keysof", func(t *testing.T) {
 		runset(t, minorSpec["keysof"], voxgigstruct.KeysOf)
 	})
 
+
+	t.Run("minor-keys", func(t *testing.T) {
+		keys := voxgigstruct.Keys([]any{"a", "b"})
+		if !reflect.DeepEqual([]voxgigstruct.PropKey{0, 1}, keys) {
+			t.Errorf("Unexpected: %v", keys)
+		}
+		if voxgigstruct.GetProp([]any{"a", "b"}, keys[1]) != "b" {
+			t.Errorf("Expected typed key to work with GetProp")
+		}
+
+		keys = voxgigstruct.Keys(map[string]any{"b": 1, "a": 2})
+		if !reflect.DeepEqual([]voxgigstruct.PropKey{"a", "b"}, keys) {
+			t.Errorf("Unexpected: %v", keys)
+		}
+
+		if 0 != len(voxgigstruct.Keys(1)) {
+			t.Errorf("Expected no keys for scalar")
+		}
+
+		for _, check := range []struct {
+			val      any
+			expected int
+		}{
+			{map[string]any{"a": 1}, 1},
+			{[]any{1, 2, 3}, 3},
+			{[]string{"a", "b"}, 2},
+			{"abc", 0},
+			{nil, 0},
+		} {
+			if n := voxgigstruct.NumKeys(check.val); check.expected != n {
+				t.Errorf("NumKeys(%v): Expected: %v, Got: %v", check.val, check.expected, n)
+			}
+		}
+	})
+
   
 	t.Run("minor-joinurl", func(t *testing.T) {
 		runsetFlags(t, minorSpec["joinurl"], map[string]bool{"null": false}, voxgigstruct.JoinUrl)
@@ -431,6 +588,16 @@ func TestStruct(t *testing.T) {
 		runset(t, mergeSpec["integrity"], voxgigstruct.Merge)
 	})
 
+
+	t.Run("merge-with-parity", func(t *testing.T) {
+		mergeWith := func(val any) any {
+			return voxgigstruct.MergeWith(val, voxgigstruct.MergeOptions{})
+		}
+		runset(t, mergeSpec["cases"], mergeWith)
+		runset(t, mergeSpec["array"], mergeWith)
+		runset(t, mergeSpec["integrity"], mergeWith)
+	})
+
   
 	t.Run("merge-special", func(t *testing.T) {
 		f0 := func() int { return 11 }
@@ -540,6 +707,223 @@ func TestStruct(t *testing.T) {
 		})
 	})
 
+
+	t.Run("getpath-wildcard", func(t *testing.T) {
+		store := map[string]any{
+			"users": []any{
+				map[string]any{"name": "a", "email": "a@x"},
+				map[string]any{"name": "b"},
+				map[string]any{"name": "c", "email": "c@x"},
+			},
+			"groups": map[string]any{
+				"g1": map[string]any{"tags": []any{"t0", "t1"}},
+				"g0": map[string]any{"tags": []any{"t2"}},
+			},
+		}
+
+		checks := []struct {
+			path     any
+			expected any
+		}{
+			{"users.*.email", []any{"a@x", "c@x"}},
+			{"users.*.x", []any{}},
+			{"groups.*.tags.*", []any{"t2", "t0", "t1"}},
+			{"groups.*.tags.0", []any{"t2", "t0"}},
+			{"users.*.name", []any{"a", "b", "c"}},
+			{"x.*", nil},
+		}
+
+		for _, check := range checks {
+			result := voxgigstruct.GetPath(check.path, store)
+			if !reflect.DeepEqual(check.expected, result) {
+				t.Errorf("Path: %v, Expected: %v, Got: %v", check.path, check.expected, result)
+			}
+		}
+
+		result0 := voxgigstruct.GetPath("*", []any{1, nil, 2})
+		expected0 := []any{1, 2}
+		if !reflect.DeepEqual(expected0, result0) {
+			t.Errorf("Expected: %v, Got: %v", expected0, result0)
+		}
+
+		result1 := voxgigstruct.Transform(store, map[string]any{"e": "`users.*.email`"})
+		expected1 := map[string]any{"e": []any{"a@x", "c@x"}}
+		if !reflect.DeepEqual(expected1, result1) {
+			t.Errorf("Expected: %v, Got: %v", expected1, result1)
+		}
+
+		cpath, _ := voxgigstruct.CompilePath("users.*.name")
+		result2 := voxgigstruct.GetPath(cpath, store)
+		expected2 := []any{"a", "b", "c"}
+		if !reflect.DeepEqual(expected2, result2) {
+			t.Errorf("Expected: %v, Got: %v", expected2, result2)
+		}
+	})
+
+	t.Run("getpath-literal-star", func(t *testing.T) {
+		store := map[string]any{
+			"cors": map[string]any{
+				"*":   map[string]any{"x": 1},
+				"foo": map[string]any{"x": 2},
+			},
+		}
+
+		// A `*` in a list of keys, or escaped in a path string, is a key.
+		checks := []struct {
+			path     any
+			expected any
+		}{
+			{[]string{"cors", "*"}, map[string]any{"x": 1}},
+			{[]any{"cors", "*", "x"}, 1},
+			{`cors.\*.x`, 1},
+			{"cors.*.x", []any{1, 2}},
+		}
+
+		for _, check := range checks {
+			result := voxgigstruct.GetPath(check.path, store)
+			if !reflect.DeepEqual(check.expected, result) {
+				t.Errorf("Path: %v, Expected: %v, Got: %v", check.path, check.expected, result)
+			}
+		}
+
+		merged := voxgigstruct.Merge([]any{
+			map[string]any{"cors": map[string]any{"*": map[string]any{"x": 1}}},
+			map[string]any{"cors": map[string]any{"*": map[string]any{"y": 2}}},
+		})
+		expected := map[string]any{"cors": map[string]any{"*": map[string]any{"x": 1, "y": 2}}}
+		if !reflect.DeepEqual(expected, merged) {
+			t.Errorf("Expected: %v, Got: %v", expected, merged)
+		}
+
+		set := voxgigstruct.SetPath([]string{"cors", "*", "y"}, store, 3)
+		if 3 != voxgigstruct.GetPath([]string{"cors", "*", "y"}, set) {
+			t.Errorf("Unexpected set: %v", set)
+		}
+
+		paths := []string{}
+		voxgigstruct.Walk(store, func(key *string, val any, parent any, path []string) any {
+			if 1 == val {
+				paths = append(paths, voxgigstruct.Pathify(path))
+			}
+			return val
+		})
+		if !reflect.DeepEqual([]string{"cors.*.x"}, paths) {
+			t.Errorf("Unexpected walk: %v", paths)
+		}
+	})
+
+
+	t.Run("getpath-setpath", func(t *testing.T) {
+		result := voxgigstruct.SetPath("a.b.0", nil, 1)
+		expected := map[string]any{"a": map[string]any{"b": map[string]any{"0": 1}}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		store := map[string]any{"a": []any{map[string]any{"b": 1}}}
+		result = voxgigstruct.SetPath("a.-1.c", store, 2)
+		expected = map[string]any{"a": []any{map[string]any{"b": 1, "c": 2}}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.SetPath("a.0", store, nil)
+		expected = map[string]any{"a": []any{}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+	})
+
+
+	t.Run("getpath-compiled", func(t *testing.T) {
+		path, err := voxgigstruct.CompilePath("a.b\\.c.0")
+		if nil != err || "a.b\\.c.0" != path.String() {
+			t.Fatalf("Unexpected: %v %v", path, err)
+		}
+
+		store := map[string]any{"a": map[string]any{"b.c": []any{1}}}
+		if result := voxgigstruct.GetPath(path, store); 1 != result {
+			t.Errorf("Expected: 1, Got: %v", result)
+		}
+		if !voxgigstruct.HasPath(path, store) {
+			t.Errorf("Expected path to exist")
+		}
+
+		result := voxgigstruct.SetPath(path, store, 2)
+		expected := map[string]any{"a": map[string]any{"b.c": []any{2}}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.DelPath(path, result)
+		expected = map[string]any{"a": map[string]any{"b.c": []any{}}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		// Compiled parts are not affected by callers.
+		parts := path.Parts()
+		parts[0] = "x"
+		if "a" != path.Parts()[0] {
+			t.Errorf("Expected compiled path to be immutable")
+		}
+
+		for _, bad := range []string{"a..b", "a."} {
+			if _, err := voxgigstruct.CompilePath(bad); nil == err {
+				t.Errorf("Expected error for: %v", bad)
+			}
+		}
+
+		if rel, err := voxgigstruct.CompilePath(".a"); nil != err ||
+			2 != voxgigstruct.GetPathState(rel, map[string]any{}, map[string]any{"a": 2}, nil) {
+			t.Errorf("Expected relative path to resolve: %v", err)
+		}
+	})
+
+
+	t.Run("getpath-delpath", func(t *testing.T) {
+		store0 := map[string]any{"a": map[string]any{"b": 1, "c": 2}}
+		expected0 := map[string]any{"a": map[string]any{"c": 2}}
+		result0 := voxgigstruct.DelPath("a.b", store0)
+		if !reflect.DeepEqual(expected0, result0) {
+			t.Errorf("Expected: %v, Got: %v", expected0, result0)
+		}
+
+		store1 := map[string]any{"a": []any{1, 2, 3}}
+		expected1 := map[string]any{"a": []any{1, 3}}
+		result1 := voxgigstruct.DelPath([]any{"a", 1}, store1)
+		if !reflect.DeepEqual(expected1, result1) {
+			t.Errorf("Expected: %v, Got: %v", expected1, result1)
+		}
+
+		store2 := []any{map[string]any{"x": []any{0}}, 1}
+		expected2 := []any{map[string]any{"x": []any{}}, 1}
+		result2 := voxgigstruct.DelPath("0.x.0", store2)
+		if !reflect.DeepEqual(expected2, result2) {
+			t.Errorf("Expected: %v, Got: %v", expected2, result2)
+		}
+
+		store3 := map[string]any{"a": map[string]any{"b": map[string]any{"c": 1}}, "d": 2}
+		expected3 := map[string]any{"d": 2}
+		result3 := voxgigstruct.DelPathFlags("a.b.c", store3, map[string]bool{"prune": true})
+		if !reflect.DeepEqual(expected3, result3) {
+			t.Errorf("Expected: %v, Got: %v", expected3, result3)
+		}
+
+		store4 := map[string]any{"a": map[string]any{}}
+		expected4 := map[string]any{"a": map[string]any{}}
+		result4 := voxgigstruct.DelPathFlags("a.x", store4, map[string]bool{"prune": true})
+		if !reflect.DeepEqual(expected4, result4) {
+			t.Errorf("Expected: %v, Got: %v", expected4, result4)
+		}
+
+		store5 := map[string]any{"a": 1}
+		result5 := voxgigstruct.DelPath("", store5)
+		if !reflect.DeepEqual(store5, result5) {
+			t.Errorf("Expected: %v, Got: %v", store5, result5)
+		}
+	})
+
   
 	// inject tests
 	// ============
@@ -639,6 +1023,17 @@ func TestStruct(t *testing.T) {
 		})
 	})
 
+
+	t.Run("transform-each-list", func(t *testing.T) {
+		data := map[string]any{"x": []any{map[string]any{"y": 1}, map[string]any{"y": 2}}}
+		spec := map[string]any{"z": []any{"`$EACH`", "x", map[string]any{"y": "`$COPY`"}}}
+		expected := map[string]any{"z": []any{map[string]any{"y": 1}, map[string]any{"y": 2}}}
+		result := voxgigstruct.Transform(data, spec)
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+	})
+
   
 	t.Run("transform-pack", func(t *testing.T) {
 		runset(t, transformSpec["pack"], func(v any) any {
@@ -755,6 +1150,142 @@ func TestStruct(t *testing.T) {
     }
 	})
 
+
+	t.Run("transform-nobase", func(t *testing.T) {
+		data := map[string]any{"a": 1, "b": map[string]any{"c": 2}}
+		spec := map[string]any{"x": "`a`", "y": "`$TOP.b.c`", "z": "`b.c`"}
+
+		result := voxgigstruct.TransformWith(data, spec, nil)
+		expected := map[string]any{"x": 1, "y": 2, "z": 2}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		result = voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{NoBase: true})
+		expected = map[string]any{"y": 2}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		store := map[string]any{"$TOP": map[string]any{"a": 1}}
+		state := &voxgigstruct.Injection{Base: "$TOP"}
+		if 1 != voxgigstruct.GetPathState("a", store, nil, state) {
+			t.Errorf("Expected base fallback")
+		}
+		state.NoBase = true
+		if nil != voxgigstruct.GetPathState("a", store, nil, state) {
+			t.Errorf("Expected no base fallback")
+		}
+	})
+
+
+	t.Run("transform-raw", func(t *testing.T) {
+		var num voxgigstruct.Injector = func(
+			state *voxgigstruct.Injection, val any, current any, ref *string, store any,
+		) any {
+			return voxgigstruct.RawValue{V: 42}
+		}
+		var flag voxgigstruct.Injector = func(
+			state *voxgigstruct.Injection, val any, current any, ref *string, store any,
+		) any {
+			return voxgigstruct.RawValue{V: true}
+		}
+
+		result := voxgigstruct.TransformWith(nil, map[string]any{
+			"a": "`$NUM`",
+			"b": "n=`$NUM`",
+			"c": "`$FLAG` `$NUM`",
+			"d": "x`$BT`",
+		}, &voxgigstruct.TransformOptions{
+			Extra: map[string]any{"$NUM": num, "$FLAG": flag},
+		})
+
+		expected := map[string]any{"a": 42, "b": 42, "c": true, "d": "x`"}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+	})
+
+
+	t.Run("transform-assert", func(t *testing.T) {
+		data := map[string]any{"a": 1, "b": map[string]any{"c": "x"}}
+		spec := map[string]any{
+			"x": "`a`",
+			"y": map[string]any{
+				"`$ASSERT`": map[string]any{"b.c": "x", "a": 2, "d": "`a`"},
+			},
+		}
+
+		errs := voxgigstruct.ListRefCreate[any]()
+		result := voxgigstruct.TransformWith(data, spec, &voxgigstruct.TransformOptions{
+			Extra: map[string]any{"$ERRS": errs},
+		})
+
+		expected := map[string]any{"x": 1, "y": map[string]any{}}
+		if !reflect.DeepEqual(expected, result) {
+			t.Errorf("Expected: %v, Got: %v", expected, result)
+		}
+
+		expectedErrs := []any{
+			"Assertion failed at a: expected 2, but found 1.",
+			"Assertion failed at d: expected `a`, but found .",
+		}
+		if !reflect.DeepEqual(expectedErrs, errs.List) {
+			t.Errorf("Expected: %v, Got: %v", expectedErrs, errs.List)
+		}
+	})
+
+
+	t.Run("transform-env", func(t *testing.T) {
+		spec := map[string]any{
+			"when": "`$WHEN`",
+			"rand": "`$RANDOM`",
+			"id":   "`$UUID`",
+		}
+
+		now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		env := &voxgigstruct.Env{
+			Clock: func() time.Time { return now },
+			Rand:  func() float64 { return 0.5 },
+			IDGen: func() string { return "id0" },
+		}
+
+		result0 := voxgigstruct.TransformWith(nil, spec, &voxgigstruct.TransformOptions{Env: env})
+		expected0 := map[string]any{"when": "2025-01-02T03:04:05Z", "rand": 0.5, "id": "id0"}
+		if !reflect.DeepEqual(expected0, result0) {
+			t.Errorf("Expected: %v, Got: %v", expected0, result0)
+		}
+
+		opts := &voxgigstruct.TransformOptions{Env: voxgigstruct.SeededEnv(1, now)}
+		result1 := voxgigstruct.TransformWith(nil, spec, opts)
+		opts.Env = voxgigstruct.SeededEnv(1, now)
+		result2 := voxgigstruct.TransformWith(nil, spec, opts)
+		if !reflect.DeepEqual(result1, result2) {
+			t.Errorf("Expected same output: %v, Got: %v", result1, result2)
+		}
+
+		id := result1.(map[string]any)["id"].(string)
+		if 36 != len(id) || '4' != id[14] {
+			t.Errorf("Expected v4 UUID, Got: %v", id)
+		}
+
+		// A seeded environment can be shared by concurrent transforms.
+		tr := voxgigstruct.NewTransformer(spec, &voxgigstruct.TransformOptions{
+			Env: voxgigstruct.SeededEnv(1, now),
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					tr.Transform(nil)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
   
 	// validate tests
 	// ===============
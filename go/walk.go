@@ -0,0 +1,259 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"context"
+)
+
+// Control signal returned by a WalkCtlApply function.
+type WalkControl int
+
+const (
+	WalkContinue WalkControl = iota // Walk the children of the value.
+	WalkSkip                        // Do not walk the children of the value.
+	WalkStop                        // Stop walking.
+)
+
+// Function applied to each node and leaf by WalkCtl. As for
+// WalkApply, but nodes are applied *before* their children, and a
+// control signal is returned with the (possibly new) value.
+type WalkCtlApply func(
+	key *string,
+	val any,
+	parent any,
+	path []string,
+) (any, WalkControl)
+
+// Walk a data structure depth first, applying a function to each
+// value before its children, so that subtrees can be skipped, and the
+// walk can be stopped early. Returns the (possibly new) root value.
+// As for WalkWith, depth is limited by memory, not the goroutine
+// stack.
+func WalkCtl(
+	val any,
+	apply WalkCtlApply,
+) any {
+	val, ctl := apply(nil, val, nil, nil)
+	if WalkStop == ctl {
+		return val
+	}
+
+	root := &walkFrame{val: val}
+	if WalkSkip != ctl && IsNode(val) {
+		root.items = Items(val)
+	}
+	stack := []*walkFrame{root}
+
+	for {
+		top := stack[len(stack)-1]
+
+		if top.next < len(top.items) {
+			kv := top.items[top.next]
+			ckey := StrKey(kv[0])
+			cpath := append(top.path, ckey)
+
+			child, ctl := apply(&ckey, kv[1], top.val, cpath)
+
+			if WalkStop == ctl {
+				top.val = SetProp(top.val, kv[0], child)
+				return _walkCtlUnwind(stack)
+			}
+
+			if WalkSkip != ctl && IsNode(child) {
+				stack = append(stack, &walkFrame{
+					val: child, key: &ckey, parent: top.val, path: cpath, items: Items(child),
+				})
+				continue
+			}
+
+			top.val = SetProp(top.val, kv[0], child)
+			top.next++
+			continue
+		}
+
+		stack = stack[:len(stack)-1]
+		if 0 == len(stack) {
+			return top.val
+		}
+
+		// Update the parent, as list references are not stable.
+		pframe := stack[len(stack)-1]
+		pframe.val = SetProp(pframe.val, pframe.items[pframe.next][0], top.val)
+		pframe.next++
+	}
+}
+
+// Set each node of a stopped walk in its parent, returning the root.
+func _walkCtlUnwind(stack []*walkFrame) any {
+	for fI := len(stack) - 1; 0 < fI; fI-- {
+		pframe := stack[fI-1]
+		pframe.val = SetProp(pframe.val, pframe.items[pframe.next][0], stack[fI].val)
+	}
+	return stack[0].val
+}
+
+// Options for WalkWith.
+type WalkOptions struct {
+	MaxDepth int       // Maximum depth of the walk (the root is 0; zero means no limit).
+	Cycles   CycleMode // Handling of nodes that contain themselves (default: not checked).
+}
+
+// Walk a data structure depth first, as for Walk, with options. If
+// the maximum depth is exceeded, the walk stops with an ErrLimit
+// error (values already applied are not restored).
+func WalkWith(
+	val any,
+	apply WalkApply,
+	opts *WalkOptions,
+) (any, error) {
+	wopts := WalkOptions{}
+	if nil != opts {
+		wopts = *opts
+	}
+	return _walk(val, _noErr(apply), nil, nil, nil, wopts, nil)
+}
+
+// A node being walked, and the index of the next child to walk.
+type walkFrame struct {
+	val    any
+	key    *string
+	parent any
+	path   []string
+	items  [][2]any
+	next   int
+	id     uintptr // Node identity, if checking cycles.
+}
+
+func _walkFrame(val any, key *string, parent any, path []string) *walkFrame {
+	frame := &walkFrame{val: val, key: key, parent: parent, path: path}
+	if IsNode(val) {
+		frame.items = Items(val)
+	}
+	return frame
+}
+
+// Function applied to each node and leaf by WalkErr. As for
+// WalkApply, but an error stops the walk.
+type WalkErrApply func(
+	key *string,
+	val any,
+	parent any,
+	path []string,
+) (any, error)
+
+// Walk a data structure depth first, as for Walk, stopping at the
+// first error returned by the apply function. The error is returned
+// with the path of the value (values already applied are not
+// restored).
+func WalkErr(
+	val any,
+	apply WalkErrApply,
+) (any, error) {
+	return _walk(val, apply, nil, nil, nil, WalkOptions{}, nil)
+}
+
+func _noErr(apply WalkApply) WalkErrApply {
+	return func(key *string, val any, parent any, path []string) (any, error) {
+		return apply(key, val, parent, path), nil
+	}
+}
+
+// Walk with an explicit stack, so that depth is limited by memory,
+// not the goroutine stack. Nodes are applied after their children.
+// The walk stops if the context (if any) is done.
+func _walk(
+	val any,
+	apply WalkErrApply,
+	key *string,
+	parent any,
+	path []string,
+	opts WalkOptions,
+	ctx context.Context,
+) (any, error) {
+	maxdepth := opts.MaxDepth
+	stack := []*walkFrame{_walkFrame(val, key, parent, path)}
+
+	// Nodes on the current path, by identity.
+	var ancestors map[uintptr]int
+	if CycleIgnore != opts.Cycles {
+		ancestors = map[uintptr]int{}
+		stack[0].id = _nodeID(val)
+		ancestors[stack[0].id]++
+	}
+
+	for {
+		if nil != ctx {
+			if err := ctx.Err(); nil != err {
+				return val, err
+			}
+		}
+
+		top := stack[len(stack)-1]
+
+		if top.next < len(top.items) {
+			kv := top.items[top.next]
+			ckey := StrKey(kv[0])
+			cpath := append(top.path, ckey)
+
+			if 0 < maxdepth && maxdepth < len(stack) {
+				return val, NewPathError(ErrLimit, append([]string{}, cpath...), nil,
+					"Maximum walk depth of %d exceeded", maxdepth)
+			}
+
+			child := kv[1]
+			var id uintptr
+			cycle := false
+			if nil != ancestors {
+				id = _nodeID(child)
+				cycle = 0 != id && 0 < ancestors[id]
+			}
+
+			if cycle {
+				switch opts.Cycles {
+				case CycleError:
+					return val, NewPathError(ErrCycle, append([]string{}, cpath...), nil,
+						"Node contains itself")
+				case CycleMarker:
+					child = S_DCYCLE
+				}
+				// The children of the repeated node are not walked.
+				frame := _walkFrame(child, &ckey, top.val, cpath)
+				frame.items = nil
+				stack = append(stack, frame)
+				continue
+			}
+
+			frame := _walkFrame(child, &ckey, top.val, cpath)
+			if nil != ancestors {
+				frame.id = id
+				ancestors[id]++
+			}
+			stack = append(stack, frame)
+			continue
+		}
+
+		if IsNode(top.val) && nil != top.parent && nil != top.key {
+			SetProp(top.parent, *top.key, top.val)
+		}
+
+		out, err := apply(top.key, top.val, top.parent, top.path)
+		if nil != err {
+			return val, NewPathError(nil, append([]string{}, top.path...), err, "Walk failed")
+		}
+
+		if nil != ancestors {
+			ancestors[top.id]--
+		}
+
+		stack = stack[:len(stack)-1]
+		if 0 == len(stack) {
+			return out, nil
+		}
+
+		// Update the parent, as list references are not stable.
+		pframe := stack[len(stack)-1]
+		pframe.val = SetProp(pframe.val, pframe.items[pframe.next][0], out)
+		pframe.next++
+	}
+}
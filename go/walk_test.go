@@ -0,0 +1,151 @@
+package voxgigstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestWalk(t *testing.T) {
+
+	t.Run("walk-ctl", func(t *testing.T) {
+		doc := map[string]any{
+			"a": map[string]any{"b": 1, "c": 2},
+			"d": []any{3, map[string]any{"e": 4}},
+			"f": 5,
+		}
+
+		visit := func(skip string, stop string) []string {
+			visited := []string{}
+			voxgigstruct.WalkCtl(doc, func(key *string, val any, parent any, path []string) (any, voxgigstruct.WalkControl) {
+				p := strings.Join(path, ".")
+				visited = append(visited, p)
+				if skip == p {
+					return val, voxgigstruct.WalkSkip
+				}
+				if stop == p {
+					return val, voxgigstruct.WalkStop
+				}
+				return val, voxgigstruct.WalkContinue
+			})
+			return visited
+		}
+
+		all := []string{"", "a", "a.b", "a.c", "d", "d.0", "d.1", "d.1.e", "f"}
+		if v := visit("", "-"); !reflect.DeepEqual([]string{""}, v) {
+			t.Errorf("Unexpected: %v", v)
+		}
+		if v := visit("-", "-"); !reflect.DeepEqual(all, v) {
+			t.Errorf("Unexpected: %v", v)
+		}
+		if v := visit("a", "d.1"); !reflect.DeepEqual([]string{"", "a", "d", "d.0", "d.1"}, v) {
+			t.Errorf("Unexpected: %v", v)
+		}
+	})
+
+	t.Run("walk-ctl-update", func(t *testing.T) {
+		out := voxgigstruct.WalkCtl(
+			map[string]any{"a": []any{1, 2}, "b": map[string]any{"c": 3}},
+			func(key *string, val any, parent any, path []string) (any, voxgigstruct.WalkControl) {
+				if n, ok := val.(int); ok {
+					return n * 10, voxgigstruct.WalkContinue
+				}
+				if "b" == strings.Join(path, ".") {
+					return "B", voxgigstruct.WalkSkip
+				}
+				return val, voxgigstruct.WalkContinue
+			})
+		expected := map[string]any{"a": []any{10, 20}, "b": "B"}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("walk-deep", func(t *testing.T) {
+		depth := 100000
+		var doc any = map[string]any{"leaf": 1}
+		for i := 0; i < depth; i++ {
+			doc = map[string]any{"a": doc}
+		}
+
+		count := 0
+		voxgigstruct.Walk(doc, func(key *string, val any, parent any, path []string) any {
+			count++
+			return val
+		})
+		if depth+2 != count {
+			t.Errorf("Unexpected count: %d", count)
+		}
+
+		count = 0
+		out := voxgigstruct.WalkCtl(doc, func(key *string, val any, parent any, path []string) (any, voxgigstruct.WalkControl) {
+			count++
+			if nil != key && "leaf" == *key {
+				return 2, voxgigstruct.WalkStop
+			}
+			return val, voxgigstruct.WalkContinue
+		})
+		if depth+2 != count {
+			t.Errorf("Unexpected count: %d", count)
+		}
+		leaf := out
+		for i := 0; i < depth; i++ {
+			leaf = leaf.(map[string]any)["a"]
+		}
+		if !reflect.DeepEqual(map[string]any{"leaf": 2}, leaf) {
+			t.Errorf("Unexpected: %v", leaf)
+		}
+
+		_, err := voxgigstruct.WalkWith(doc, func(key *string, val any, parent any, path []string) any {
+			return val
+		}, &voxgigstruct.WalkOptions{MaxDepth: 3})
+		if !errors.Is(err, voxgigstruct.ErrLimit) ||
+			"Maximum walk depth of 3 exceeded (at a.a.a.a)" != err.Error() {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		out, err = voxgigstruct.WalkWith(map[string]any{"a": []any{1}},
+			func(key *string, val any, parent any, path []string) any {
+				if n, ok := val.(int); ok {
+					return n + 1
+				}
+				return val
+			}, &voxgigstruct.WalkOptions{MaxDepth: 2})
+		if nil != err || !reflect.DeepEqual(map[string]any{"a": []any{2}}, out) {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+	})
+
+	t.Run("walk-err", func(t *testing.T) {
+		doc := map[string]any{"a": map[string]any{"b": 1, "c": "x"}, "d": 2}
+
+		visited := []string{}
+		_, err := voxgigstruct.WalkErr(doc, func(key *string, val any, parent any, path []string) (any, error) {
+			visited = append(visited, strings.Join(path, "."))
+			if _, ok := val.(string); ok {
+				return nil, voxgigstruct.ErrType
+			}
+			return val, nil
+		})
+		if !errors.Is(err, voxgigstruct.ErrType) || "Walk failed (at a.c): invalid type" != err.Error() {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual([]string{"a.b", "a.c"}, visited) {
+			t.Errorf("Unexpected: %v", visited)
+		}
+
+		out, err := voxgigstruct.WalkErr(doc, func(key *string, val any, parent any, path []string) (any, error) {
+			if n, ok := val.(int); ok {
+				return n * 2, nil
+			}
+			return val, nil
+		})
+		expected := map[string]any{"a": map[string]any{"b": 2, "c": "x"}, "d": 4}
+		if nil != err || !reflect.DeepEqual(expected, out) {
+			t.Errorf("Unexpected: %v %v", out, err)
+		}
+	})
+}
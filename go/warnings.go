@@ -0,0 +1,90 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+// A non-fatal condition found by a transform, such as a coercion, a
+// deprecated spec construct, or a truncated value. Warnings are
+// collected separately from errors, so that they can be reported
+// without failing the transform.
+type Warning struct {
+	Code string   // Short identifier of the condition, for example `each-source`.
+	Path []string // Path in the output.
+	Msg  string
+}
+
+func (w Warning) String() string {
+	if 0 == len(w.Path) {
+		return w.Msg
+	}
+	return w.Msg + " (at " + Pathify(w.Path) + ")"
+}
+
+// Add a warning at the current path, for use by transforms. Warnings
+// are appended to the `$WARNS` collector of the store, and logged.
+func (state *Injection) Warn(code string, msg string) {
+	var path []string
+	if 0 < len(state.Path) {
+		path = append([]string{}, state.Path[1:]...)
+	}
+
+	_logWarn(state.Log, msg, "code", code, "path", Pathify(path))
+
+	if nil != state.Warns {
+		state.Warns.Append(Warning{Code: code, Path: path, Msg: msg})
+	}
+}
+
+// Output of TransformCollect.
+type TransformResult struct {
+	Out      any       // Transform output.
+	Errs     []any     // Errors collected by transforms.
+	Warnings []Warning // Warnings collected by transforms.
+	Manifest *Manifest // Manifest of the transform, if given or recorded.
+}
+
+// Transform data using a spec, as for TransformWith, collecting the
+// errors and warnings. The `$ERRS` and `$WARNS` extra entries (if any)
+// are replaced.
+func TransformCollect(data any, spec any, opts *TransformOptions) *TransformResult {
+	copts := TransformOptions{}
+	if nil != opts {
+		copts = *opts
+	}
+
+	errs := ListRefCreate[any]()
+	warns := ListRefCreate[any]()
+	copts.Extra = _extraWith(copts.Extra, map[string]any{S_DERRS: errs, S_DWARNS: warns})
+
+	if copts.Record && nil == copts.Manifest {
+		copts.Manifest = NewManifest(data, spec)
+	}
+
+	result := &TransformResult{
+		Out:      TransformWith(data, spec, &copts),
+		Errs:     errs.List,
+		Warnings: []Warning{},
+		Manifest: copts.Manifest,
+	}
+
+	for _, w := range warns.List {
+		if warning, ok := w.(Warning); ok {
+			result.Warnings = append(result.Warnings, warning)
+		} else {
+			result.Warnings = append(result.Warnings, Warning{Msg: Stringify(w)})
+		}
+	}
+
+	return result
+}
+
+// A copy of the extra store, with entries added.
+func _extraWith(extra any, add map[string]any) map[string]any {
+	out := map[string]any{}
+	for _, kv := range Items(extra) {
+		out[StrKey(kv[0])] = kv[1]
+	}
+	for k, v := range add {
+		out[k] = v
+	}
+	return out
+}
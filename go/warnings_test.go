@@ -0,0 +1,80 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestWarnings(t *testing.T) {
+
+	// A custom transform that coerces a source string to a number.
+	var toNum voxgigstruct.Injector = func(
+		state *voxgigstruct.Injection,
+		val any,
+		current any,
+		ref *string,
+		store any,
+	) any {
+		src := voxgigstruct.GetProp(current, state.Key)
+		if s, ok := src.(string); ok {
+			n, err := strconv.Atoi(s)
+			if nil != err {
+				state.Errs.Append("Not a number: " + s)
+				return nil
+			}
+			state.Warn("coerced", "Coerced string to number")
+			voxgigstruct.SetProp(state.Parent, state.Key, n)
+			return n
+		}
+		voxgigstruct.SetProp(state.Parent, state.Key, src)
+		return src
+	}
+
+	t.Run("warnings-collect", func(t *testing.T) {
+		log := &testLogger{}
+		result := voxgigstruct.TransformCollect(
+			map[string]any{"a": "1", "b": 2, "c": "x", "d": "e"},
+			map[string]any{
+				"a": "`$NUM`",
+				"b": "`$NUM`",
+				"c": "`$NUM`",
+				"l": []any{"`$EACH`", "d", map[string]any{}},
+			},
+			&voxgigstruct.TransformOptions{
+				Extra:  map[string]any{"$NUM": toNum},
+				Logger: log,
+			},
+		)
+
+		if !reflect.DeepEqual(map[string]any{"a": 1, "b": 2, "l": []any{}}, result.Out) {
+			t.Errorf("Unexpected: %v", result.Out)
+		}
+		if !reflect.DeepEqual([]any{"Not a number: x"}, result.Errs) {
+			t.Errorf("Unexpected errors: %v", result.Errs)
+		}
+
+		expected := []voxgigstruct.Warning{
+			{Code: "coerced", Path: []string{"a"}, Msg: "Coerced string to number"},
+			{Code: "each-source", Path: []string{"l", "0"}, Msg: "Source for $EACH is not a list or map: string"},
+		}
+		if !reflect.DeepEqual(expected, result.Warnings) {
+			t.Errorf("Expected: %v, Got: %v", expected, result.Warnings)
+		}
+		if "Coerced string to number (at a)" != result.Warnings[0].String() {
+			t.Errorf("Unexpected: %v", result.Warnings[0])
+		}
+
+		warns := 0
+		for _, entry := range log.entries {
+			if "WARN" == entry[:4] {
+				warns++
+			}
+		}
+		if 2 != warns {
+			t.Errorf("Unexpected log: %q", log.entries)
+		}
+	})
+}
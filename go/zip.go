@@ -0,0 +1,98 @@
+/* Copyright (c) 2025 Voxgig Ltd. MIT LICENSE. */
+
+package voxgigstruct
+
+import (
+	"sort"
+)
+
+// Combine source lists positionally, applying a child template to
+// each set of elements. Format: ['`$ZIP`', sources, child-template].
+// The sources are a list of source paths (as for `$EACH`), or a map
+// of names to source paths. The child template is injected with a
+// map of the elements as the current value, keyed by name, or by the
+// last part of each path, so that `.ages` is the element of the
+// `people.ages` list. The output has the length of the shortest list.
+var Transform_ZIP Injector = func(
+	state *Injection,
+	val any,
+	current any,
+	ref *string,
+	store any,
+) any {
+	args, ok := _listTransformArgs(state, "$ZIP", 2)
+	if !ok {
+		return nil
+	}
+
+	sources := map[string]string{}
+	if IsMap(args[0]) {
+		for _, name := range KeysOf(args[0]) {
+			if path, ok := GetProp(args[0], name).(string); ok {
+				sources[name] = path
+			}
+		}
+	} else {
+		for _, path := range _listify(args[0]) {
+			if spath, ok := path.(string); ok {
+				parts, _ := _pathParts(spath)
+				sources[parts[len(parts)-1]] = spath
+			}
+		}
+	}
+
+	if 0 == len(sources) {
+		state.Warn("zip-source", "No source paths for $ZIP: "+Stringify(args[0]))
+		return _replaceTransform(state, []any{})
+	}
+
+	srcstore := GetProp(store, state.Base, store)
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	count := -1
+	lists := map[string][]any{}
+	for _, name := range names {
+		path := sources[name]
+		src := GetPathState(path, srcstore, current, nil)
+		if parts, ok := _pathParts(path); ok {
+			_markUsed(store, parts)
+		}
+
+		list := []any{}
+		if IsList(src) {
+			list = _listify(src)
+		} else if nil != src {
+			state.Warn("zip-source", "Source for $ZIP is not a list: "+path+": "+Typify(src))
+		}
+		lists[name] = list
+
+		if -1 == count || len(list) < count {
+			count = len(list)
+		}
+	}
+
+	child := args[1]
+	_reserveOutput(store, count, child, state.Path)
+
+	// Parallel data structures: elements :: child templates.
+	tcur := make([]any, count)
+	tval := make([]any, count)
+	for i := 0; i < count; i++ {
+		elems := map[string]any{}
+		for name, list := range lists {
+			elems[name] = list[i]
+		}
+		tcur[i] = elems
+		tval[i] = Clone(child)
+	}
+
+	out := InjectDescend(tval, store, state.Modify, map[string]any{S_DTOP: tcur},
+		_nestedState(tval, store, state))
+
+	return _replaceTransform(state, out)
+}
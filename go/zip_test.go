@@ -0,0 +1,63 @@
+package voxgigstruct_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxgig/struct"
+)
+
+func TestZip(t *testing.T) {
+
+	data := map[string]any{
+		"names": []any{"ann", "bob", "cy"},
+		"ages":  []any{30, 40},
+		"legacy": map[string]any{
+			"ids": []any{"a1", "b2", "c3"},
+		},
+	}
+
+	t.Run("zip-list", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, map[string]any{
+			"people": []any{"`$ZIP`", []any{"names", "ages"}, map[string]any{
+				"name": "`.names`",
+				"age":  "`.ages`",
+			}},
+		})
+		expected := map[string]any{"people": []any{
+			map[string]any{"name": "ann", "age": 30},
+			map[string]any{"name": "bob", "age": 40},
+		}}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("zip-named", func(t *testing.T) {
+		out := voxgigstruct.Transform(data, []any{"`$ZIP`",
+			map[string]any{"id": "legacy.ids", "name": "names"},
+			map[string]any{"label": "`.id`:`.name`"},
+		})
+		expected := []any{
+			map[string]any{"label": "a1:ann"},
+			map[string]any{"label": "b2:bob"},
+			map[string]any{"label": "c3:cy"},
+		}
+		if !reflect.DeepEqual(expected, out) {
+			t.Errorf("Expected: %v, Got: %v", expected, out)
+		}
+	})
+
+	t.Run("zip-source", func(t *testing.T) {
+		warns := voxgigstruct.ListRefCreate[any]()
+		out := voxgigstruct.TransformWith(data, map[string]any{
+			"a": []any{"`$ZIP`", []any{"names", "legacy"}, "`.names`"},
+			"b": []any{"`$ZIP`", []any{"names", "missing"}, "`.names`"},
+		}, &voxgigstruct.TransformOptions{Extra: map[string]any{"$WARNS": warns}})
+
+		expected := map[string]any{"a": []any{}, "b": []any{}}
+		if !reflect.DeepEqual(expected, out) || 1 != len(warns.List) {
+			t.Errorf("Expected: %v, Got: %v %v", expected, out, warns.List)
+		}
+	})
+}